@@ -5,10 +5,14 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/Bughay/Trainer-GO/db"
 	"github.com/Bughay/Trainer-GO/internal/auth"
 	"github.com/Bughay/Trainer-GO/internal/food"
+	"github.com/Bughay/Trainer-GO/internal/mail"
+	"github.com/Bughay/Trainer-GO/internal/profile"
+	"github.com/Bughay/Trainer-GO/internal/trainer"
 	"github.com/Bughay/Trainer-GO/internal/training"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -44,9 +48,55 @@ func main() {
 
 	queries := db.New(dbPool)
 
-	authHandler, err := auth.NewAuthHandler(queries, jwtSecret)
-	foodHandler := food.NewFoodHandler(queries)
-	trainingHandler := training.NewTrainingHandler(queries)
+	// MAIL_DIR writes outgoing mail to files; otherwise it is only logged
+	var mailer mail.Sender = mail.LogSender{}
+	if mailDir := os.Getenv("MAIL_DIR"); mailDir != "" {
+		fileSender, err := mail.NewFileSender(mailDir)
+		if err != nil {
+			log.Fatalf("Failed to create mail sender: %v", err)
+		}
+		mailer = fileSender
+	}
+
+	// BREACHED_PASSWORDS_FILE extends the built-in list of rejected passwords
+	policy := auth.DefaultPolicy()
+	if breachedPath := os.Getenv("BREACHED_PASSWORDS_FILE"); breachedPath != "" {
+		if err := policy.LoadBreachedPasswords(breachedPath); err != nil {
+			log.Fatalf("Failed to load breached passwords: %v", err)
+		}
+	}
+
+	// Failed logins are counted in memory unless LOGIN_THROTTLE_STORE=postgres,
+	// which replicas behind a load balancer need to share counters
+	var attemptStore auth.AttemptStore = auth.NewMemoryAttemptStore()
+	if os.Getenv("LOGIN_THROTTLE_STORE") == "postgres" {
+		attemptStore = auth.NewPostgresAttemptStore(queries)
+	}
+	throttle := auth.NewThrottle(attemptStore)
+
+	// TRUSTED_PROXIES lists the load balancer CIDRs whose X-Forwarded-For is
+	// used as the client IP; without it the connection address is used
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		throttle.TrustedProxies, err = auth.ParseTrustedProxies(proxies)
+		if err != nil {
+			log.Fatalf("Failed to parse TRUSTED_PROXIES: %v", err)
+		}
+	}
+	go func() {
+		ticker := time.NewTicker(10 * time.Minute)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			if err := throttle.Prune(ctx, now); err != nil {
+				log.Printf("prune login throttle: %v", err)
+			}
+		}
+	}()
+
+	authHandler, err := auth.NewAuthHandler(queries, dbPool, jwtSecret, mailer, policy, throttle)
+	foodHandler := food.NewFoodHandler(queries, dbPool)
+	trainingHandler := training.NewTrainingHandler(queries, dbPool)
+	profileHandler := profile.NewProfileHandler(queries, dbPool)
+	trainerHandler := trainer.NewTrainerHandler(queries, dbPool)
 	if err != nil {
 		log.Fatalf("Failed to create auth handler: %v", err)
 	}
@@ -54,13 +104,91 @@ func main() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("POST /auth/register", authHandler.UserRegistrationHandler)
 	mux.HandleFunc("POST /auth/login", authHandler.UserLoginHandler)
+	mux.HandleFunc("POST /auth/refresh", authHandler.RefreshHandler)
+	mux.HandleFunc("POST /auth/logout", authHandler.AuthMiddleware(authHandler.LogoutHandler))
+	mux.HandleFunc("POST /auth/password", authHandler.AuthMiddleware(authHandler.ChangePasswordHandler))
+	mux.HandleFunc("POST /auth/password/forgot", authHandler.RequestPasswordResetHandler)
+	mux.HandleFunc("POST /auth/password/reset", authHandler.ResetPasswordHandler)
+	mux.HandleFunc("DELETE /me", authHandler.AuthMiddleware(authHandler.DeleteAccountHandler))
+	mux.HandleFunc("PUT /admin/users/{id}/roles", authHandler.AuthMiddleware(auth.RequireRole(auth.RoleAdmin, authHandler.SetRolesHandler)))
+
+	mux.HandleFunc("GET /me/profile", authHandler.AuthMiddleware(profileHandler.GetProfileHandler))
+	mux.HandleFunc("PUT /me/profile", authHandler.AuthMiddleware(profileHandler.UpdateProfileHandler))
+	mux.HandleFunc("GET /me/measurements", authHandler.AuthMiddleware(profileHandler.ListMeasurementsHandler))
+	mux.HandleFunc("POST /me/measurements", authHandler.AuthMiddleware(profileHandler.CreateMeasurementHandler))
+	mux.HandleFunc("PUT /me/measurements/{id}", authHandler.AuthMiddleware(profileHandler.UpdateMeasurementHandler))
+	mux.HandleFunc("DELETE /me/measurements/{id}", authHandler.AuthMiddleware(profileHandler.DeleteMeasurementHandler))
 
 	mux.HandleFunc("POST /food/create", authHandler.AuthMiddleware(foodHandler.CreateFoodItemHandler))
+	mux.HandleFunc("GET /food/items", authHandler.AuthMiddleware(foodHandler.ListFoodItemsHandler))
+	mux.HandleFunc("GET /food/items/{id}", authHandler.AuthMiddleware(foodHandler.GetFoodItemHandler))
+	mux.HandleFunc("PUT /food/items/{id}", authHandler.AuthMiddleware(foodHandler.UpdateFoodItemHandler))
+	mux.HandleFunc("DELETE /food/items/{id}", authHandler.AuthMiddleware(foodHandler.DeleteFoodItemHandler))
 	mux.HandleFunc("POST /food/log", authHandler.AuthMiddleware(foodHandler.LogFoodHandler))
 	mux.HandleFunc("GET /food/view", authHandler.AuthMiddleware(foodHandler.ViewFoodHandler))
 	mux.HandleFunc("GET /food/viewtotal", authHandler.AuthMiddleware(foodHandler.ViewFoodTotalHandler))
+	mux.HandleFunc("GET /food/recent", authHandler.AuthMiddleware(foodHandler.RecentFoodsHandler))
+	mux.HandleFunc("PUT /food/entries/{id}", authHandler.AuthMiddleware(foodHandler.UpdateFoodEntryHandler))
+	mux.HandleFunc("DELETE /food/entries/{id}", authHandler.AuthMiddleware(foodHandler.DeleteFoodEntryHandler))
+	mux.HandleFunc("GET /food/targets", authHandler.AuthMiddleware(foodHandler.ListNutritionTargetsHandler))
+	mux.HandleFunc("PUT /food/targets", authHandler.AuthMiddleware(foodHandler.SetNutritionTargetsHandler))
+	mux.HandleFunc("GET /food/adherence", authHandler.AuthMiddleware(foodHandler.AdherenceHandler))
+	mux.HandleFunc("GET /food/recommendation", authHandler.AuthMiddleware(foodHandler.RecommendationHandler))
+	mux.HandleFunc("GET /food/plan", authHandler.AuthMiddleware(foodHandler.PlanDayHandler))
+	mux.HandleFunc("POST /food/plan/items/{id}/eat", authHandler.AuthMiddleware(foodHandler.EatPlanItemHandler))
+	mux.HandleFunc("GET /food/plan/compare", authHandler.AuthMiddleware(foodHandler.PlanComparisonHandler))
+
+	mux.HandleFunc("POST /recipes", authHandler.AuthMiddleware(foodHandler.CreateRecipeHandler))
+	mux.HandleFunc("GET /recipes", authHandler.AuthMiddleware(foodHandler.ListRecipesHandler))
+	mux.HandleFunc("GET /recipes/{id}", authHandler.AuthMiddleware(foodHandler.GetRecipeHandler))
+	mux.HandleFunc("PUT /recipes/{id}", authHandler.AuthMiddleware(foodHandler.UpdateRecipeHandler))
+	mux.HandleFunc("DELETE /recipes/{id}", authHandler.AuthMiddleware(foodHandler.DeleteRecipeHandler))
 
 	mux.HandleFunc("POST /training/log", authHandler.AuthMiddleware(trainingHandler.LogTrainingHandler))
+	mux.HandleFunc("GET /training/entries", authHandler.AuthMiddleware(trainingHandler.ListExerciseEntriesHandler))
+	mux.HandleFunc("GET /training/entries/{id}", authHandler.AuthMiddleware(trainingHandler.GetExerciseEntryHandler))
+	mux.HandleFunc("PUT /training/entries/{id}", authHandler.AuthMiddleware(trainingHandler.UpdateExerciseEntryHandler))
+	mux.HandleFunc("DELETE /training/entries/{id}", authHandler.AuthMiddleware(trainingHandler.DeleteExerciseEntryHandler))
+	mux.HandleFunc("GET /training/exercises", authHandler.AuthMiddleware(trainingHandler.ListExercisesHandler))
+	mux.HandleFunc("POST /training/exercises", authHandler.AuthMiddleware(trainingHandler.CreateExerciseHandler))
+	mux.HandleFunc("GET /training/exercises/{id}", authHandler.AuthMiddleware(trainingHandler.GetExerciseHandler))
+	mux.HandleFunc("GET /training/exercises/{name}/history", authHandler.AuthMiddleware(trainingHandler.ExerciseHistoryHandler))
+	mux.HandleFunc("GET /training/prs", authHandler.AuthMiddleware(trainingHandler.ListPersonalRecordsHandler))
+	mux.HandleFunc("GET /training/stats", authHandler.AuthMiddleware(trainingHandler.TrainingStatsHandler))
+	mux.HandleFunc("GET /training/today", authHandler.AuthMiddleware(trainingHandler.TodayHandler))
+
+	mux.HandleFunc("POST /training/sessions", authHandler.AuthMiddleware(trainingHandler.StartSessionHandler))
+	mux.HandleFunc("GET /training/sessions", authHandler.AuthMiddleware(trainingHandler.ListSessionsHandler))
+	mux.HandleFunc("GET /training/sessions/{id}", authHandler.AuthMiddleware(trainingHandler.GetSessionHandler))
+	mux.HandleFunc("POST /training/sessions/{id}/entries", authHandler.AuthMiddleware(trainingHandler.AddSessionEntryHandler))
+	mux.HandleFunc("POST /training/sessions/{id}/finish", authHandler.AuthMiddleware(trainingHandler.FinishSessionHandler))
+
+	mux.HandleFunc("POST /trainer/invites", authHandler.AuthMiddleware(auth.RequireRole(auth.RoleTrainer, trainerHandler.InviteClientHandler)))
+	mux.HandleFunc("POST /trainer/invites/{id}/accept", authHandler.AuthMiddleware(trainerHandler.AcceptInviteHandler))
+	mux.HandleFunc("POST /trainer/invites/{id}/decline", authHandler.AuthMiddleware(trainerHandler.DeclineInviteHandler))
+	mux.HandleFunc("DELETE /trainer/relationships/{id}", authHandler.AuthMiddleware(trainerHandler.EndRelationshipHandler))
+	mux.HandleFunc("GET /trainer/clients", authHandler.AuthMiddleware(auth.RequireRole(auth.RoleTrainer, trainerHandler.ListClientsHandler)))
+	mux.HandleFunc("GET /me/trainers", authHandler.AuthMiddleware(trainerHandler.ListTrainersHandler))
+	mux.HandleFunc("POST /trainer/programs", authHandler.AuthMiddleware(auth.RequireRole(auth.RoleTrainer, trainerHandler.CreateProgramHandler)))
+	mux.HandleFunc("GET /trainer/programs", authHandler.AuthMiddleware(auth.RequireRole(auth.RoleTrainer, trainerHandler.ListProgramsHandler)))
+	mux.HandleFunc("GET /trainer/programs/{id}", authHandler.AuthMiddleware(auth.RequireRole(auth.RoleTrainer, trainerHandler.GetProgramHandler)))
+	mux.HandleFunc("POST /trainer/programs/{id}/assign", authHandler.AuthMiddleware(auth.RequireRole(auth.RoleTrainer, trainerHandler.AssignProgramHandler)))
+	mux.HandleFunc("GET /me/program", authHandler.AuthMiddleware(trainerHandler.ProgramProgressHandler))
+	mux.HandleFunc("POST /trainer/meal-plans", authHandler.AuthMiddleware(auth.RequireRole(auth.RoleTrainer, foodHandler.CreateMealPlanHandler)))
+	mux.HandleFunc("GET /trainer/meal-plans", authHandler.AuthMiddleware(auth.RequireRole(auth.RoleTrainer, foodHandler.ListMealPlansHandler)))
+	mux.HandleFunc("GET /trainer/meal-plans/{id}", authHandler.AuthMiddleware(auth.RequireRole(auth.RoleTrainer, foodHandler.GetMealPlanHandler)))
+	mux.HandleFunc("POST /trainer/meal-plans/{id}/assign", authHandler.AuthMiddleware(auth.RequireRole(auth.RoleTrainer, foodHandler.AssignMealPlanHandler)))
+
+	// Read-only views of a client's logs. RequireOwnerOrTrainerOf checks the
+	// {client_id} link and runs the handler as that client.
+	mux.HandleFunc("GET /trainer/clients/{client_id}/food/view", authHandler.AuthMiddleware(authHandler.RequireOwnerOrTrainerOf("client_id", foodHandler.ViewFoodHandler)))
+	mux.HandleFunc("GET /trainer/clients/{client_id}/food/viewtotal", authHandler.AuthMiddleware(authHandler.RequireOwnerOrTrainerOf("client_id", foodHandler.ViewFoodTotalHandler)))
+	mux.HandleFunc("GET /trainer/clients/{client_id}/training/entries", authHandler.AuthMiddleware(authHandler.RequireOwnerOrTrainerOf("client_id", trainingHandler.ListExerciseEntriesHandler)))
+	mux.HandleFunc("GET /trainer/clients/{client_id}/training/entries/{id}", authHandler.AuthMiddleware(authHandler.RequireOwnerOrTrainerOf("client_id", trainingHandler.GetExerciseEntryHandler)))
+	mux.HandleFunc("GET /trainer/clients/{client_id}/training/stats", authHandler.AuthMiddleware(authHandler.RequireOwnerOrTrainerOf("client_id", trainingHandler.TrainingStatsHandler)))
+	mux.HandleFunc("GET /trainer/clients/{client_id}/program", authHandler.AuthMiddleware(authHandler.RequireOwnerOrTrainerOf("client_id", trainerHandler.ProgramProgressHandler)))
+	mux.HandleFunc("GET /trainer/clients/{client_id}/food/plan", authHandler.AuthMiddleware(authHandler.RequireOwnerOrTrainerOf("client_id", foodHandler.PlanDayHandler)))
+	mux.HandleFunc("GET /trainer/clients/{client_id}/food/plan/compare", authHandler.AuthMiddleware(authHandler.RequireOwnerOrTrainerOf("client_id", foodHandler.PlanComparisonHandler)))
 
 	server := &http.Server{
 		Addr:    ":8080",
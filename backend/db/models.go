@@ -8,32 +8,65 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type BodyMeasurement struct {
+	MeasurementID int64            `json:"measurement_id"`
+	UserID        int64            `json:"user_id"`
+	MeasuredAt    pgtype.Timestamp `json:"measured_at"`
+	Bodyweight    pgtype.Numeric   `json:"bodyweight"`
+	BodyFat       pgtype.Numeric   `json:"body_fat"`
+	Waist         pgtype.Numeric   `json:"waist"`
+	Chest         pgtype.Numeric   `json:"chest"`
+	Arms          pgtype.Numeric   `json:"arms"`
+	Thighs        pgtype.Numeric   `json:"thighs"`
+	Notes         pgtype.Text      `json:"notes"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	LastUpdated   pgtype.Timestamp `json:"last_updated"`
+}
+
+type Exercise struct {
+	ExerciseID       int64            `json:"exercise_id"`
+	UserID           pgtype.Int8      `json:"user_id"`
+	Name             string           `json:"name"`
+	Aliases          []string         `json:"aliases"`
+	PrimaryMuscles   []string         `json:"primary_muscles"`
+	SecondaryMuscles []string         `json:"secondary_muscles"`
+	Equipment        pgtype.Text      `json:"equipment"`
+	MovementPattern  pgtype.Text      `json:"movement_pattern"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	LastUpdated      pgtype.Timestamp `json:"last_updated"`
+}
+
 type ExerciseEntry struct {
-	EntryID      int64            `json:"entry_id"`
-	UserID       int64            `json:"user_id"`
-	CreatedAt    pgtype.Timestamp `json:"created_at"`
-	LastUpdated  pgtype.Timestamp `json:"last_updated"`
-	ExerciseName string           `json:"exercise_name"`
-	Weight       pgtype.Numeric   `json:"weight"`
-	Sets         int32            `json:"sets"`
-	Reps         int32            `json:"reps"`
-	Rpe          int32            `json:"rpe"`
-	Notes        pgtype.Text      `json:"notes"`
+	EntryID        int64            `json:"entry_id"`
+	UserID         int64            `json:"user_id"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	LastUpdated    pgtype.Timestamp `json:"last_updated"`
+	ExerciseName   string           `json:"exercise_name"`
+	Weight         pgtype.Numeric   `json:"weight"`
+	Sets           int32            `json:"sets"`
+	Reps           int32            `json:"reps"`
+	Rpe            int32            `json:"rpe"`
+	Notes          pgtype.Text      `json:"notes"`
+	SessionID      pgtype.Int8      `json:"session_id"`
+	ExerciseID     pgtype.Int8      `json:"exercise_id"`
+	Estimated1rm   pgtype.Numeric   `json:"estimated_1rm"`
+	PrescriptionID pgtype.Int8      `json:"prescription_id"`
 }
 
-type Food struct {
-	FoodID      int64            `json:"food_id"`
-	UserID      int64            `json:"user_id"`
-	FoodName    string           `json:"food_name"`
-	Calories100 float64          `json:"calories_100"`
-	Protein100  float64          `json:"protein_100"`
-	Carbs100    float64          `json:"carbs_100"`
-	Fats100     float64          `json:"fats_100"`
+type ExerciseSet struct {
+	SetID       int64            `json:"set_id"`
+	EntryID     int64            `json:"entry_id"`
+	SetOrder    int32            `json:"set_order"`
+	SetType     string           `json:"set_type"`
+	Weight      pgtype.Numeric   `json:"weight"`
+	Reps        int32            `json:"reps"`
+	Rpe         pgtype.Numeric   `json:"rpe"`
+	Completed   bool             `json:"completed"`
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 	LastUpdated pgtype.Timestamp `json:"last_updated"`
 }
 
-type FoodCache struct {
+type Food struct {
 	FoodID      int64            `json:"food_id"`
 	UserID      int64            `json:"user_id"`
 	FoodName    string           `json:"food_name"`
@@ -45,11 +78,27 @@ type FoodCache struct {
 	LastUpdated pgtype.Timestamp `json:"last_updated"`
 }
 
+type FoodCache struct {
+	FoodID         int64            `json:"food_id"`
+	UserID         int64            `json:"user_id"`
+	FoodName       string           `json:"food_name"`
+	NormalizedName string           `json:"normalized_name"`
+	Calories100    float64          `json:"calories_100"`
+	Protein100     float64          `json:"protein_100"`
+	Carbs100       float64          `json:"carbs_100"`
+	Fats100        float64          `json:"fats_100"`
+	UseCount       int32            `json:"use_count"`
+	LastUsed       pgtype.Timestamp `json:"last_used"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	LastUpdated    pgtype.Timestamp `json:"last_updated"`
+}
+
 type FoodEntry struct {
 	NutritionID int64            `json:"nutrition_id"`
 	UserID      int64            `json:"user_id"`
 	FoodID      pgtype.Int8      `json:"food_id"`
 	RecipeID    pgtype.Int8      `json:"recipe_id"`
+	CacheID     pgtype.Int8      `json:"cache_id"`
 	Calories    float64          `json:"calories"`
 	TotalGrams  float64          `json:"total_grams"`
 	Protein     float64          `json:"protein"`
@@ -57,6 +106,148 @@ type FoodEntry struct {
 	Fats        float64          `json:"fats"`
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 	LastUpdated pgtype.Timestamp `json:"last_updated"`
+	PlanItemID  pgtype.Int8      `json:"plan_item_id"`
+}
+
+type LoginAttempt struct {
+	AttemptID   int64            `json:"attempt_id"`
+	Username    string           `json:"username"`
+	UserID      pgtype.Int8      `json:"user_id"`
+	IpAddress   string           `json:"ip_address"`
+	Outcome     string           `json:"outcome"`
+	AttemptedAt pgtype.Timestamp `json:"attempted_at"`
+}
+
+type LoginThrottle struct {
+	ThrottleKey string           `json:"throttle_key"`
+	Failures    int32            `json:"failures"`
+	LastFailure pgtype.Timestamp `json:"last_failure"`
+}
+
+type MealPlan struct {
+	PlanID      int64            `json:"plan_id"`
+	TrainerID   int64            `json:"trainer_id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	CycleDays   int32            `json:"cycle_days"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	LastUpdated pgtype.Timestamp `json:"last_updated"`
+}
+
+type MealPlanAssignment struct {
+	AssignmentID int64            `json:"assignment_id"`
+	PlanID       int64            `json:"plan_id"`
+	ClientID     int64            `json:"client_id"`
+	StartDate    pgtype.Date      `json:"start_date"`
+	Status       string           `json:"status"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	LastUpdated  pgtype.Timestamp `json:"last_updated"`
+}
+
+type MealPlanDay struct {
+	DayID     int64       `json:"day_id"`
+	PlanID    int64       `json:"plan_id"`
+	DayNumber int32       `json:"day_number"`
+	Name      pgtype.Text `json:"name"`
+}
+
+type MealPlanItem struct {
+	ItemID     int64       `json:"item_id"`
+	MealID     int64       `json:"meal_id"`
+	ItemOrder  int32       `json:"item_order"`
+	FoodID     pgtype.Int8 `json:"food_id"`
+	RecipeID   pgtype.Int8 `json:"recipe_id"`
+	ItemName   string      `json:"item_name"`
+	TotalGrams float64     `json:"total_grams"`
+	Calories   float64     `json:"calories"`
+	Protein    float64     `json:"protein"`
+	Carbs      float64     `json:"carbs"`
+	Fats       float64     `json:"fats"`
+}
+
+type MealPlanMeal struct {
+	MealID    int64  `json:"meal_id"`
+	DayID     int64  `json:"day_id"`
+	MealOrder int32  `json:"meal_order"`
+	Name      string `json:"name"`
+}
+
+type NutritionTarget struct {
+	TargetID      int64            `json:"target_id"`
+	UserID        int64            `json:"user_id"`
+	DayType       string           `json:"day_type"`
+	EffectiveFrom pgtype.Date      `json:"effective_from"`
+	Calories      float64          `json:"calories"`
+	Protein       float64          `json:"protein"`
+	Carbs         float64          `json:"carbs"`
+	Fats          float64          `json:"fats"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	LastUpdated   pgtype.Timestamp `json:"last_updated"`
+}
+
+type PasswordResetToken struct {
+	TokenID   int64            `json:"token_id"`
+	UserID    int64            `json:"user_id"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	UsedAt    pgtype.Timestamp `json:"used_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type PersonalRecord struct {
+	RecordID     int64            `json:"record_id"`
+	UserID       int64            `json:"user_id"`
+	ExerciseName string           `json:"exercise_name"`
+	RecordType   string           `json:"record_type"`
+	Value        pgtype.Numeric   `json:"value"`
+	Weight       pgtype.Numeric   `json:"weight"`
+	Reps         pgtype.Int4      `json:"reps"`
+	EntryID      pgtype.Int8      `json:"entry_id"`
+	AchievedAt   pgtype.Timestamp `json:"achieved_at"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	LastUpdated  pgtype.Timestamp `json:"last_updated"`
+}
+
+type Program struct {
+	ProgramID   int64            `json:"program_id"`
+	TrainerID   int64            `json:"trainer_id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	Weeks       int32            `json:"weeks"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	LastUpdated pgtype.Timestamp `json:"last_updated"`
+}
+
+type ProgramAssignment struct {
+	AssignmentID int64            `json:"assignment_id"`
+	ProgramID    int64            `json:"program_id"`
+	ClientID     int64            `json:"client_id"`
+	StartDate    pgtype.Date      `json:"start_date"`
+	Status       string           `json:"status"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	LastUpdated  pgtype.Timestamp `json:"last_updated"`
+}
+
+type ProgramDay struct {
+	DayID      int64       `json:"day_id"`
+	ProgramID  int64       `json:"program_id"`
+	WeekNumber int32       `json:"week_number"`
+	DayNumber  int32       `json:"day_number"`
+	Name       pgtype.Text `json:"name"`
+}
+
+type ProgramExercise struct {
+	PrescriptionID int64          `json:"prescription_id"`
+	DayID          int64          `json:"day_id"`
+	ExerciseOrder  int32          `json:"exercise_order"`
+	ExerciseName   string         `json:"exercise_name"`
+	ExerciseID     pgtype.Int8    `json:"exercise_id"`
+	TargetSets     int32          `json:"target_sets"`
+	TargetReps     int32          `json:"target_reps"`
+	LoadPercent    pgtype.Numeric `json:"load_percent"`
+	TargetRpe      pgtype.Numeric `json:"target_rpe"`
+	RestSeconds    pgtype.Int4    `json:"rest_seconds"`
+	Notes          pgtype.Text    `json:"notes"`
 }
 
 type Recipe struct {
@@ -64,6 +255,7 @@ type Recipe struct {
 	UserID       int64            `json:"user_id"`
 	RecipeName   string           `json:"recipe_name"`
 	Instructions pgtype.Text      `json:"instructions"`
+	Servings     int32            `json:"servings"`
 	CreatedAt    pgtype.Timestamp `json:"created_at"`
 	LastUpdated  pgtype.Timestamp `json:"last_updated"`
 }
@@ -77,6 +269,35 @@ type RecipeIngredient struct {
 	LastUpdated  pgtype.Timestamp `json:"last_updated"`
 }
 
+type RefreshToken struct {
+	TokenID   int64            `json:"token_id"`
+	UserID    int64            `json:"user_id"`
+	FamilyID  string           `json:"family_id"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	UsedAt    pgtype.Timestamp `json:"used_at"`
+	RevokedAt pgtype.Timestamp `json:"revoked_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type RevokedToken struct {
+	Jti       string           `json:"jti"`
+	UserID    pgtype.Int8      `json:"user_id"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	RevokedAt pgtype.Timestamp `json:"revoked_at"`
+}
+
+type TrainerClient struct {
+	RelationshipID int64            `json:"relationship_id"`
+	TrainerID      int64            `json:"trainer_id"`
+	ClientID       int64            `json:"client_id"`
+	Status         string           `json:"status"`
+	InvitedAt      pgtype.Timestamp `json:"invited_at"`
+	RespondedAt    pgtype.Timestamp `json:"responded_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	LastUpdated    pgtype.Timestamp `json:"last_updated"`
+}
+
 type User struct {
 	UserID         int64            `json:"user_id"`
 	Username       string           `json:"username"`
@@ -85,12 +306,29 @@ type User struct {
 }
 
 type UsersProfile struct {
+	UserID         int64            `json:"user_id"`
+	DateOfBirth    pgtype.Date      `json:"date_of_birth"`
+	Email          pgtype.Text      `json:"email"`
+	Height         pgtype.Numeric   `json:"height"`
+	Weight         pgtype.Numeric   `json:"weight"`
+	Sex            pgtype.Text      `json:"sex"`
+	BodyFat        pgtype.Numeric   `json:"body_fat"`
+	PreferredUnits string           `json:"preferred_units"`
+	IsTrainer      bool             `json:"is_trainer"`
+	IsVip          bool             `json:"is_vip"`
+	IsAdmin        bool             `json:"is_admin"`
+	LastUpdated    pgtype.Timestamp `json:"last_updated"`
+}
+
+type WorkoutSession struct {
+	SessionID   int64            `json:"session_id"`
 	UserID      int64            `json:"user_id"`
-	DateOfBirth pgtype.Date      `json:"date_of_birth"`
-	Email       pgtype.Text      `json:"email"`
-	Height      pgtype.Numeric   `json:"height"`
-	Weight      pgtype.Numeric   `json:"weight"`
-	IsTrainer   bool             `json:"is_trainer"`
-	IsVip       bool             `json:"is_vip"`
+	Title       string           `json:"title"`
+	Notes       pgtype.Text      `json:"notes"`
+	StartedAt   pgtype.Timestamp `json:"started_at"`
+	EndedAt     pgtype.Timestamp `json:"ended_at"`
+	Bodyweight  pgtype.Numeric   `json:"bodyweight"`
+	SessionRpe  pgtype.Int4      `json:"session_rpe"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
 	LastUpdated pgtype.Timestamp `json:"last_updated"`
 }
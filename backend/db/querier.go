@@ -6,16 +6,161 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
-	CreateFoodCacheItem(ctx context.Context, arg CreateFoodCacheItemParams) (FoodCache, error)
-	CreateFoodItem(ctx context.Context, arg CreateFoodItemParams) (CreateFoodItemRow, error)
+	AddRecipeIngredient(ctx context.Context, arg AddRecipeIngredientParams) (RecipeIngredient, error)
+	// Marks the token used and returns its owner, or no rows when the token is
+	// unknown, spent or expired
+	ConsumePasswordResetToken(ctx context.Context, tokenHash string) (int64, error)
+	CountFoodItems(ctx context.Context, arg CountFoodItemsParams) (int64, error)
+	CountUserFoods(ctx context.Context, arg CountUserFoodsParams) (int64, error)
+	CreateBodyMeasurement(ctx context.Context, arg CreateBodyMeasurementParams) (BodyMeasurement, error)
+	CreateExercise(ctx context.Context, arg CreateExerciseParams) (Exercise, error)
+	CreateExerciseSet(ctx context.Context, arg CreateExerciseSetParams) (ExerciseSet, error)
+	CreateFoodItem(ctx context.Context, arg CreateFoodItemParams) (Food, error)
+	CreateLoginAttempt(ctx context.Context, arg CreateLoginAttemptParams) error
+	CreateMealPlan(ctx context.Context, arg CreateMealPlanParams) (MealPlan, error)
+	CreateMealPlanAssignment(ctx context.Context, arg CreateMealPlanAssignmentParams) (MealPlanAssignment, error)
+	CreateMealPlanDay(ctx context.Context, arg CreateMealPlanDayParams) (MealPlanDay, error)
+	CreateMealPlanItem(ctx context.Context, arg CreateMealPlanItemParams) (MealPlanItem, error)
+	CreateMealPlanMeal(ctx context.Context, arg CreateMealPlanMealParams) (MealPlanMeal, error)
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) error
+	CreateProgram(ctx context.Context, arg CreateProgramParams) (Program, error)
+	CreateProgramAssignment(ctx context.Context, arg CreateProgramAssignmentParams) (ProgramAssignment, error)
+	CreateProgramDay(ctx context.Context, arg CreateProgramDayParams) (ProgramDay, error)
+	CreateProgramExercise(ctx context.Context, arg CreateProgramExerciseParams) (ProgramExercise, error)
+	CreateRecipe(ctx context.Context, arg CreateRecipeParams) (Recipe, error)
+	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error)
+	CreateUserProfile(ctx context.Context, userID int64) error
+	CreateWorkoutSession(ctx context.Context, arg CreateWorkoutSessionParams) (WorkoutSession, error)
+	DailyFoodTotals(ctx context.Context, arg DailyFoodTotalsParams) ([]DailyFoodTotalsRow, error)
+	DeleteBodyMeasurement(ctx context.Context, arg DeleteBodyMeasurementParams) (int64, error)
+	DeleteEntrySets(ctx context.Context, entryID int64) error
+	DeleteExerciseEntry(ctx context.Context, arg DeleteExerciseEntryParams) (int64, error)
+	DeleteExercisePersonalRecords(ctx context.Context, arg DeleteExercisePersonalRecordsParams) error
+	DeleteFoodEntry(ctx context.Context, arg DeleteFoodEntryParams) (int64, error)
+	DeleteFoodItem(ctx context.Context, arg DeleteFoodItemParams) (int64, error)
+	DeleteRecipe(ctx context.Context, arg DeleteRecipeParams) (int64, error)
+	DeleteRecipeIngredients(ctx context.Context, recipeID int64) error
+	DeleteStaleLoginThrottles(ctx context.Context, lastFailure pgtype.Timestamp) (int64, error)
+	// Tables without ON DELETE CASCADE to users must be cleared first with the
+	// DeleteUser* queries
+	DeleteUser(ctx context.Context, userID int64) error
+	DeleteUserExerciseEntries(ctx context.Context, userID int64) error
+	DeleteUserExercises(ctx context.Context, userID int64) error
+	DeleteUserFoodCache(ctx context.Context, userID int64) error
+	DeleteUserFoodEntries(ctx context.Context, userID int64) error
+	DeleteUserFoods(ctx context.Context, userID int64) error
+	DeleteUserMeasurements(ctx context.Context, userID int64) error
+	DeleteUserNutritionTargets(ctx context.Context, userID int64) error
+	DeleteUserPersonalRecords(ctx context.Context, userID int64) error
+	DeleteUserRecipeIngredients(ctx context.Context, userID int64) error
+	DeleteUserRecipes(ctx context.Context, userID int64) error
+	DeleteUserWorkoutSessions(ctx context.Context, userID int64) error
+	EndActiveAssignments(ctx context.Context, clientID int64) error
+	EndActiveMealPlanAssignments(ctx context.Context, clientID int64) error
+	EndTrainerRelationship(ctx context.Context, arg EndTrainerRelationshipParams) (int64, error)
+	FinishWorkoutSession(ctx context.Context, arg FinishWorkoutSessionParams) (WorkoutSession, error)
+	GetActiveAssignment(ctx context.Context, clientID int64) (GetActiveAssignmentRow, error)
+	GetActiveMealPlanAssignment(ctx context.Context, clientID int64) (GetActiveMealPlanAssignmentRow, error)
+	// Only items from the client's active meal plan can be eaten
+	GetAssignedPlanItem(ctx context.Context, arg GetAssignedPlanItemParams) (MealPlanItem, error)
+	// Only prescriptions from the client's active program can be logged against
+	GetAssignedPrescription(ctx context.Context, arg GetAssignedPrescriptionParams) (ProgramExercise, error)
+	GetExercise(ctx context.Context, arg GetExerciseParams) (Exercise, error)
+	GetExerciseEntry(ctx context.Context, arg GetExerciseEntryParams) (ExerciseEntry, error)
+	GetFoodCacheItem(ctx context.Context, arg GetFoodCacheItemParams) (FoodCache, error)
+	GetFoodEntry(ctx context.Context, arg GetFoodEntryParams) (FoodEntry, error)
+	GetFoodItem(ctx context.Context, arg GetFoodItemParams) (Food, error)
+	GetLoginThrottle(ctx context.Context, throttleKey string) (LoginThrottle, error)
+	GetMealPlan(ctx context.Context, arg GetMealPlanParams) (MealPlan, error)
+	GetProgram(ctx context.Context, arg GetProgramParams) (Program, error)
+	GetProgramDay(ctx context.Context, arg GetProgramDayParams) (ProgramDay, error)
+	GetRecipe(ctx context.Context, arg GetRecipeParams) (Recipe, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error)
+	GetUserByEmail(ctx context.Context, lower string) (GetUserByEmailRow, error)
 	GetUserByID(ctx context.Context, userID int64) (GetUserByIDRow, error)
-	GetUserByUsername(ctx context.Context, username string) (GetUserByUsernameRow, error)
+	GetUserByUsername(ctx context.Context, lower string) (GetUserByUsernameRow, error)
+	GetUserProfile(ctx context.Context, userID int64) (UsersProfile, error)
+	GetWorkoutSession(ctx context.Context, arg GetWorkoutSessionParams) (WorkoutSession, error)
+	// Spends any outstanding tokens so only the most recent email works
+	InvalidatePasswordResetTokens(ctx context.Context, userID int64) error
+	// Re-inviting is allowed once an earlier invite was declined or the
+	// relationship ended; a pending or active link returns no rows
+	InviteClient(ctx context.Context, arg InviteClientParams) (TrainerClient, error)
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	// The trainer must still be flagged as one for the link to count
+	IsTrainerOfClient(ctx context.Context, arg IsTrainerOfClientParams) (bool, error)
+	ListBodyMeasurements(ctx context.Context, arg ListBodyMeasurementsParams) ([]BodyMeasurement, error)
+	// Bodyweight from the measurement log plus any weigh-ins recorded on
+	// workout sessions
+	ListBodyweightReadings(ctx context.Context, arg ListBodyweightReadingsParams) ([]ListBodyweightReadingsRow, error)
+	ListClientTrainers(ctx context.Context, clientID int64) ([]ListClientTrainersRow, error)
+	ListDayExercises(ctx context.Context, dayID int64) ([]ProgramExercise, error)
+	ListEntrySets(ctx context.Context, entryID int64) ([]ExerciseSet, error)
+	ListExerciseEntries(ctx context.Context, arg ListExerciseEntriesParams) ([]ExerciseEntry, error)
+	ListExerciseHistory(ctx context.Context, arg ListExerciseHistoryParams) ([]ExerciseEntry, error)
+	ListExercises(ctx context.Context, arg ListExercisesParams) ([]Exercise, error)
+	ListFoodItems(ctx context.Context, arg ListFoodItemsParams) ([]Food, error)
+	// Flattened plan contents, optionally for a single day
+	ListMealPlanItems(ctx context.Context, arg ListMealPlanItemsParams) ([]ListMealPlanItemsRow, error)
+	ListMealPlans(ctx context.Context, trainerID int64) ([]MealPlan, error)
+	ListNutritionTargets(ctx context.Context, userID int64) ([]NutritionTarget, error)
+	ListPersonalRecords(ctx context.Context, arg ListPersonalRecordsParams) ([]PersonalRecord, error)
+	ListPlanItemEntries(ctx context.Context, arg ListPlanItemEntriesParams) ([]FoodEntry, error)
+	ListPrescriptionEntries(ctx context.Context, arg ListPrescriptionEntriesParams) ([]ExerciseEntry, error)
+	ListProgramDays(ctx context.Context, programID int64) ([]ProgramDay, error)
+	ListProgramExercises(ctx context.Context, programID int64) ([]ProgramExercise, error)
+	ListPrograms(ctx context.Context, trainerID int64) ([]Program, error)
+	ListRecentFoods(ctx context.Context, arg ListRecentFoodsParams) ([]FoodCache, error)
+	ListRecipeIngredients(ctx context.Context, recipeID int64) ([]ListRecipeIngredientsRow, error)
+	ListRecipes(ctx context.Context, userID int64) ([]Recipe, error)
+	ListSessionEntries(ctx context.Context, sessionID pgtype.Int8) ([]ExerciseEntry, error)
+	ListSessionSets(ctx context.Context, sessionID pgtype.Int8) ([]ExerciseSet, error)
+	ListSetsForEntries(ctx context.Context, entryIds []int64) ([]ExerciseSet, error)
+	ListTrainerClients(ctx context.Context, trainerID int64) ([]ListTrainerClientsRow, error)
+	ListTrainingDays(ctx context.Context, arg ListTrainingDaysParams) ([]pgtype.Date, error)
+	ListUserRecipeIngredients(ctx context.Context, userID int64) ([]ListUserRecipeIngredientsRow, error)
+	ListWorkoutSessions(ctx context.Context, arg ListWorkoutSessionsParams) ([]WorkoutSession, error)
 	LogExercise(ctx context.Context, arg LogExerciseParams) (ExerciseEntry, error)
 	LogFoodItem(ctx context.Context, arg LogFoodItemParams) (FoodEntry, error)
+	// Affects no rows when the token was already used or revoked, which is how
+	// a concurrent replay is detected
+	MarkRefreshTokenUsed(ctx context.Context, tokenID int64) (int64, error)
+	// Secondary muscles count as half a set. Entries that are not linked to the
+	// exercise catalog have no muscle groups and are left out.
+	MuscleSetStats(ctx context.Context, arg MuscleSetStatsParams) ([]MuscleSetStatsRow, error)
+	// Counts a failure, starting over when the previous one is older than
+	// window_start
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginThrottle, error)
+	// Takes back a failure reserved for a login that succeeded or never ran
+	ReleaseLoginFailure(ctx context.Context, throttleKey string) error
+	ResetLoginThrottle(ctx context.Context, throttleKey string) error
+	// Matches a canonical name or alias case-insensitively, preferring the
+	// user's own custom exercise over the built-in one
+	ResolveExercise(ctx context.Context, arg ResolveExerciseParams) (Exercise, error)
+	RespondToInvite(ctx context.Context, arg RespondToInviteParams) (TrainerClient, error)
+	RevokeAccessToken(ctx context.Context, arg RevokeAccessTokenParams) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int64) error
+	SetUserRoles(ctx context.Context, arg SetUserRolesParams) (UsersProfile, error)
+	TouchFoodCacheItem(ctx context.Context, foodID int64) error
+	TrainingVolumeStats(ctx context.Context, arg TrainingVolumeStatsParams) ([]TrainingVolumeStatsRow, error)
+	UpdateBodyMeasurement(ctx context.Context, arg UpdateBodyMeasurementParams) (BodyMeasurement, error)
+	UpdateExerciseEntry(ctx context.Context, arg UpdateExerciseEntryParams) (ExerciseEntry, error)
+	UpdateFoodEntry(ctx context.Context, arg UpdateFoodEntryParams) (FoodEntry, error)
+	UpdateFoodItem(ctx context.Context, arg UpdateFoodItemParams) (Food, error)
+	UpdateRecipe(ctx context.Context, arg UpdateRecipeParams) (Recipe, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	UpsertFoodCacheItem(ctx context.Context, arg UpsertFoodCacheItemParams) (FoodCache, error)
+	UpsertNutritionTarget(ctx context.Context, arg UpsertNutritionTargetParams) (NutritionTarget, error)
+	// Returns no row when the existing record is at least as good
+	UpsertPersonalRecord(ctx context.Context, arg UpsertPersonalRecordParams) (PersonalRecord, error)
+	UpsertUserProfile(ctx context.Context, arg UpsertUserProfileParams) (UsersProfile, error)
 	ViewFood(ctx context.Context, arg ViewFoodParams) ([]ViewFoodRow, error)
 	ViewFoodTotal(ctx context.Context, arg ViewFoodTotalParams) (ViewFoodTotalRow, error)
 }
@@ -11,61 +11,3719 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createFoodCacheItem = `-- name: CreateFoodCacheItem :one
-INSERT INTO food_Cache(user_id,food_name,calories_100,protein_100,carbs_100,fats_100)
+const addRecipeIngredient = `-- name: AddRecipeIngredient :one
+INSERT INTO recipe_ingredients(recipe_id,food_id,total_grams)
+VALUES($1,$2,$3)
+RETURNING ingredient_id, recipe_id, food_id, total_grams, created_at, last_updated
+`
+
+type AddRecipeIngredientParams struct {
+	RecipeID   int64   `json:"recipe_id"`
+	FoodID     int64   `json:"food_id"`
+	TotalGrams float64 `json:"total_grams"`
+}
+
+func (q *Queries) AddRecipeIngredient(ctx context.Context, arg AddRecipeIngredientParams) (RecipeIngredient, error) {
+	row := q.db.QueryRow(ctx, addRecipeIngredient, arg.RecipeID, arg.FoodID, arg.TotalGrams)
+	var i RecipeIngredient
+	err := row.Scan(
+		&i.IngredientID,
+		&i.RecipeID,
+		&i.FoodID,
+		&i.TotalGrams,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const consumePasswordResetToken = `-- name: ConsumePasswordResetToken :one
+UPDATE password_reset_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+RETURNING user_id
+`
+
+// Marks the token used and returns its owner, or no rows when the token is
+// unknown, spent or expired
+func (q *Queries) ConsumePasswordResetToken(ctx context.Context, tokenHash string) (int64, error) {
+	row := q.db.QueryRow(ctx, consumePasswordResetToken, tokenHash)
+	var user_id int64
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const countFoodItems = `-- name: CountFoodItems :one
+SELECT COUNT(*)
+FROM food
+WHERE user_id = $1
+  AND food_name ILIKE '%' || replace(replace(replace($2::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+`
+
+type CountFoodItemsParams struct {
+	UserID int64  `json:"user_id"`
+	Search string `json:"search"`
+}
+
+func (q *Queries) CountFoodItems(ctx context.Context, arg CountFoodItemsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countFoodItems, arg.UserID, arg.Search)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserFoods = `-- name: CountUserFoods :one
+SELECT COUNT(DISTINCT food_id)
+FROM food
+WHERE user_id = $1 AND food_id = ANY($2::bigint[])
+`
+
+type CountUserFoodsParams struct {
+	UserID  int64   `json:"user_id"`
+	FoodIds []int64 `json:"food_ids"`
+}
+
+func (q *Queries) CountUserFoods(ctx context.Context, arg CountUserFoodsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUserFoods, arg.UserID, arg.FoodIds)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createBodyMeasurement = `-- name: CreateBodyMeasurement :one
+INSERT INTO body_measurements(user_id,measured_at,bodyweight,body_fat,waist,chest,arms,thighs,notes)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9)
+RETURNING measurement_id, user_id, measured_at, bodyweight, body_fat, waist, chest, arms, thighs, notes, created_at, last_updated
+`
+
+type CreateBodyMeasurementParams struct {
+	UserID     int64            `json:"user_id"`
+	MeasuredAt pgtype.Timestamp `json:"measured_at"`
+	Bodyweight pgtype.Numeric   `json:"bodyweight"`
+	BodyFat    pgtype.Numeric   `json:"body_fat"`
+	Waist      pgtype.Numeric   `json:"waist"`
+	Chest      pgtype.Numeric   `json:"chest"`
+	Arms       pgtype.Numeric   `json:"arms"`
+	Thighs     pgtype.Numeric   `json:"thighs"`
+	Notes      pgtype.Text      `json:"notes"`
+}
+
+func (q *Queries) CreateBodyMeasurement(ctx context.Context, arg CreateBodyMeasurementParams) (BodyMeasurement, error) {
+	row := q.db.QueryRow(ctx, createBodyMeasurement,
+		arg.UserID,
+		arg.MeasuredAt,
+		arg.Bodyweight,
+		arg.BodyFat,
+		arg.Waist,
+		arg.Chest,
+		arg.Arms,
+		arg.Thighs,
+		arg.Notes,
+	)
+	var i BodyMeasurement
+	err := row.Scan(
+		&i.MeasurementID,
+		&i.UserID,
+		&i.MeasuredAt,
+		&i.Bodyweight,
+		&i.BodyFat,
+		&i.Waist,
+		&i.Chest,
+		&i.Arms,
+		&i.Thighs,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const createExercise = `-- name: CreateExercise :one
+INSERT INTO exercises(user_id,name,aliases,primary_muscles,secondary_muscles,equipment,movement_pattern)
+VALUES($1,$2,$3,$4,$5,$6,$7)
+RETURNING exercise_id, user_id, name, aliases, primary_muscles, secondary_muscles, equipment, movement_pattern, created_at, last_updated
+`
+
+type CreateExerciseParams struct {
+	UserID           pgtype.Int8 `json:"user_id"`
+	Name             string      `json:"name"`
+	Aliases          []string    `json:"aliases"`
+	PrimaryMuscles   []string    `json:"primary_muscles"`
+	SecondaryMuscles []string    `json:"secondary_muscles"`
+	Equipment        pgtype.Text `json:"equipment"`
+	MovementPattern  pgtype.Text `json:"movement_pattern"`
+}
+
+func (q *Queries) CreateExercise(ctx context.Context, arg CreateExerciseParams) (Exercise, error) {
+	row := q.db.QueryRow(ctx, createExercise,
+		arg.UserID,
+		arg.Name,
+		arg.Aliases,
+		arg.PrimaryMuscles,
+		arg.SecondaryMuscles,
+		arg.Equipment,
+		arg.MovementPattern,
+	)
+	var i Exercise
+	err := row.Scan(
+		&i.ExerciseID,
+		&i.UserID,
+		&i.Name,
+		&i.Aliases,
+		&i.PrimaryMuscles,
+		&i.SecondaryMuscles,
+		&i.Equipment,
+		&i.MovementPattern,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const createExerciseSet = `-- name: CreateExerciseSet :one
+INSERT INTO exercise_sets(entry_id,set_order,set_type,weight,reps,rpe,completed)
+VALUES($1,$2,$3,$4,$5,$6,$7)
+RETURNING set_id, entry_id, set_order, set_type, weight, reps, rpe, completed, created_at, last_updated
+`
+
+type CreateExerciseSetParams struct {
+	EntryID   int64          `json:"entry_id"`
+	SetOrder  int32          `json:"set_order"`
+	SetType   string         `json:"set_type"`
+	Weight    pgtype.Numeric `json:"weight"`
+	Reps      int32          `json:"reps"`
+	Rpe       pgtype.Numeric `json:"rpe"`
+	Completed bool           `json:"completed"`
+}
+
+func (q *Queries) CreateExerciseSet(ctx context.Context, arg CreateExerciseSetParams) (ExerciseSet, error) {
+	row := q.db.QueryRow(ctx, createExerciseSet,
+		arg.EntryID,
+		arg.SetOrder,
+		arg.SetType,
+		arg.Weight,
+		arg.Reps,
+		arg.Rpe,
+		arg.Completed,
+	)
+	var i ExerciseSet
+	err := row.Scan(
+		&i.SetID,
+		&i.EntryID,
+		&i.SetOrder,
+		&i.SetType,
+		&i.Weight,
+		&i.Reps,
+		&i.Rpe,
+		&i.Completed,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const createFoodItem = `-- name: CreateFoodItem :one
+INSERT INTO food(user_id,food_name,calories_100,protein_100,carbs_100,fats_100)
 VALUES($1,$2,$3,$4,$5,$6)
 RETURNING food_id, user_id, food_name, calories_100, protein_100, carbs_100, fats_100, created_at, last_updated
 `
 
-type CreateFoodCacheItemParams struct {
-	UserID      int64   `json:"user_id"`
-	FoodName    string  `json:"food_name"`
-	Calories100 float64 `json:"calories_100"`
-	Protein100  float64 `json:"protein_100"`
-	Carbs100    float64 `json:"carbs_100"`
-	Fats100     float64 `json:"fats_100"`
+type CreateFoodItemParams struct {
+	UserID      int64   `json:"user_id"`
+	FoodName    string  `json:"food_name"`
+	Calories100 float64 `json:"calories_100"`
+	Protein100  float64 `json:"protein_100"`
+	Carbs100    float64 `json:"carbs_100"`
+	Fats100     float64 `json:"fats_100"`
+}
+
+func (q *Queries) CreateFoodItem(ctx context.Context, arg CreateFoodItemParams) (Food, error) {
+	row := q.db.QueryRow(ctx, createFoodItem,
+		arg.UserID,
+		arg.FoodName,
+		arg.Calories100,
+		arg.Protein100,
+		arg.Carbs100,
+		arg.Fats100,
+	)
+	var i Food
+	err := row.Scan(
+		&i.FoodID,
+		&i.UserID,
+		&i.FoodName,
+		&i.Calories100,
+		&i.Protein100,
+		&i.Carbs100,
+		&i.Fats100,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const createLoginAttempt = `-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempts(username,user_id,ip_address,outcome)
+VALUES($1,$2,$3,$4)
+`
+
+type CreateLoginAttemptParams struct {
+	Username  string      `json:"username"`
+	UserID    pgtype.Int8 `json:"user_id"`
+	IpAddress string      `json:"ip_address"`
+	Outcome   string      `json:"outcome"`
+}
+
+func (q *Queries) CreateLoginAttempt(ctx context.Context, arg CreateLoginAttemptParams) error {
+	_, err := q.db.Exec(ctx, createLoginAttempt,
+		arg.Username,
+		arg.UserID,
+		arg.IpAddress,
+		arg.Outcome,
+	)
+	return err
+}
+
+const createMealPlan = `-- name: CreateMealPlan :one
+INSERT INTO meal_plans(trainer_id,name,description,cycle_days)
+VALUES($1,$2,$3,$4)
+RETURNING plan_id, trainer_id, name, description, cycle_days, created_at, last_updated
+`
+
+type CreateMealPlanParams struct {
+	TrainerID   int64       `json:"trainer_id"`
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+	CycleDays   int32       `json:"cycle_days"`
+}
+
+func (q *Queries) CreateMealPlan(ctx context.Context, arg CreateMealPlanParams) (MealPlan, error) {
+	row := q.db.QueryRow(ctx, createMealPlan,
+		arg.TrainerID,
+		arg.Name,
+		arg.Description,
+		arg.CycleDays,
+	)
+	var i MealPlan
+	err := row.Scan(
+		&i.PlanID,
+		&i.TrainerID,
+		&i.Name,
+		&i.Description,
+		&i.CycleDays,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const createMealPlanAssignment = `-- name: CreateMealPlanAssignment :one
+INSERT INTO meal_plan_assignments(plan_id,client_id,start_date)
+VALUES($1,$2,$3)
+RETURNING assignment_id, plan_id, client_id, start_date, status, created_at, last_updated
+`
+
+type CreateMealPlanAssignmentParams struct {
+	PlanID    int64       `json:"plan_id"`
+	ClientID  int64       `json:"client_id"`
+	StartDate pgtype.Date `json:"start_date"`
+}
+
+func (q *Queries) CreateMealPlanAssignment(ctx context.Context, arg CreateMealPlanAssignmentParams) (MealPlanAssignment, error) {
+	row := q.db.QueryRow(ctx, createMealPlanAssignment, arg.PlanID, arg.ClientID, arg.StartDate)
+	var i MealPlanAssignment
+	err := row.Scan(
+		&i.AssignmentID,
+		&i.PlanID,
+		&i.ClientID,
+		&i.StartDate,
+		&i.Status,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const createMealPlanDay = `-- name: CreateMealPlanDay :one
+INSERT INTO meal_plan_days(plan_id,day_number,name)
+VALUES($1,$2,$3)
+RETURNING day_id, plan_id, day_number, name
+`
+
+type CreateMealPlanDayParams struct {
+	PlanID    int64       `json:"plan_id"`
+	DayNumber int32       `json:"day_number"`
+	Name      pgtype.Text `json:"name"`
+}
+
+func (q *Queries) CreateMealPlanDay(ctx context.Context, arg CreateMealPlanDayParams) (MealPlanDay, error) {
+	row := q.db.QueryRow(ctx, createMealPlanDay, arg.PlanID, arg.DayNumber, arg.Name)
+	var i MealPlanDay
+	err := row.Scan(
+		&i.DayID,
+		&i.PlanID,
+		&i.DayNumber,
+		&i.Name,
+	)
+	return i, err
+}
+
+const createMealPlanItem = `-- name: CreateMealPlanItem :one
+INSERT INTO meal_plan_items(meal_id,item_order,food_id,recipe_id,item_name,total_grams,calories,protein,carbs,fats)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+RETURNING item_id, meal_id, item_order, food_id, recipe_id, item_name, total_grams, calories, protein, carbs, fats
+`
+
+type CreateMealPlanItemParams struct {
+	MealID     int64       `json:"meal_id"`
+	ItemOrder  int32       `json:"item_order"`
+	FoodID     pgtype.Int8 `json:"food_id"`
+	RecipeID   pgtype.Int8 `json:"recipe_id"`
+	ItemName   string      `json:"item_name"`
+	TotalGrams float64     `json:"total_grams"`
+	Calories   float64     `json:"calories"`
+	Protein    float64     `json:"protein"`
+	Carbs      float64     `json:"carbs"`
+	Fats       float64     `json:"fats"`
+}
+
+func (q *Queries) CreateMealPlanItem(ctx context.Context, arg CreateMealPlanItemParams) (MealPlanItem, error) {
+	row := q.db.QueryRow(ctx, createMealPlanItem,
+		arg.MealID,
+		arg.ItemOrder,
+		arg.FoodID,
+		arg.RecipeID,
+		arg.ItemName,
+		arg.TotalGrams,
+		arg.Calories,
+		arg.Protein,
+		arg.Carbs,
+		arg.Fats,
+	)
+	var i MealPlanItem
+	err := row.Scan(
+		&i.ItemID,
+		&i.MealID,
+		&i.ItemOrder,
+		&i.FoodID,
+		&i.RecipeID,
+		&i.ItemName,
+		&i.TotalGrams,
+		&i.Calories,
+		&i.Protein,
+		&i.Carbs,
+		&i.Fats,
+	)
+	return i, err
+}
+
+const createMealPlanMeal = `-- name: CreateMealPlanMeal :one
+INSERT INTO meal_plan_meals(day_id,meal_order,name)
+VALUES($1,$2,$3)
+RETURNING meal_id, day_id, meal_order, name
+`
+
+type CreateMealPlanMealParams struct {
+	DayID     int64  `json:"day_id"`
+	MealOrder int32  `json:"meal_order"`
+	Name      string `json:"name"`
+}
+
+func (q *Queries) CreateMealPlanMeal(ctx context.Context, arg CreateMealPlanMealParams) (MealPlanMeal, error) {
+	row := q.db.QueryRow(ctx, createMealPlanMeal, arg.DayID, arg.MealOrder, arg.Name)
+	var i MealPlanMeal
+	err := row.Scan(
+		&i.MealID,
+		&i.DayID,
+		&i.MealOrder,
+		&i.Name,
+	)
+	return i, err
+}
+
+const createPasswordResetToken = `-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens(user_id,token_hash,expires_at)
+VALUES($1,$2,$3)
+`
+
+type CreatePasswordResetTokenParams struct {
+	UserID    int64            `json:"user_id"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+}
+
+func (q *Queries) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) error {
+	_, err := q.db.Exec(ctx, createPasswordResetToken, arg.UserID, arg.TokenHash, arg.ExpiresAt)
+	return err
+}
+
+const createProgram = `-- name: CreateProgram :one
+INSERT INTO programs(trainer_id,name,description,weeks)
+VALUES($1,$2,$3,$4)
+RETURNING program_id, trainer_id, name, description, weeks, created_at, last_updated
+`
+
+type CreateProgramParams struct {
+	TrainerID   int64       `json:"trainer_id"`
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+	Weeks       int32       `json:"weeks"`
+}
+
+func (q *Queries) CreateProgram(ctx context.Context, arg CreateProgramParams) (Program, error) {
+	row := q.db.QueryRow(ctx, createProgram,
+		arg.TrainerID,
+		arg.Name,
+		arg.Description,
+		arg.Weeks,
+	)
+	var i Program
+	err := row.Scan(
+		&i.ProgramID,
+		&i.TrainerID,
+		&i.Name,
+		&i.Description,
+		&i.Weeks,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const createProgramAssignment = `-- name: CreateProgramAssignment :one
+INSERT INTO program_assignments(program_id,client_id,start_date)
+VALUES($1,$2,$3)
+RETURNING assignment_id, program_id, client_id, start_date, status, created_at, last_updated
+`
+
+type CreateProgramAssignmentParams struct {
+	ProgramID int64       `json:"program_id"`
+	ClientID  int64       `json:"client_id"`
+	StartDate pgtype.Date `json:"start_date"`
+}
+
+func (q *Queries) CreateProgramAssignment(ctx context.Context, arg CreateProgramAssignmentParams) (ProgramAssignment, error) {
+	row := q.db.QueryRow(ctx, createProgramAssignment, arg.ProgramID, arg.ClientID, arg.StartDate)
+	var i ProgramAssignment
+	err := row.Scan(
+		&i.AssignmentID,
+		&i.ProgramID,
+		&i.ClientID,
+		&i.StartDate,
+		&i.Status,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const createProgramDay = `-- name: CreateProgramDay :one
+INSERT INTO program_days(program_id,week_number,day_number,name)
+VALUES($1,$2,$3,$4)
+RETURNING day_id, program_id, week_number, day_number, name
+`
+
+type CreateProgramDayParams struct {
+	ProgramID  int64       `json:"program_id"`
+	WeekNumber int32       `json:"week_number"`
+	DayNumber  int32       `json:"day_number"`
+	Name       pgtype.Text `json:"name"`
+}
+
+func (q *Queries) CreateProgramDay(ctx context.Context, arg CreateProgramDayParams) (ProgramDay, error) {
+	row := q.db.QueryRow(ctx, createProgramDay,
+		arg.ProgramID,
+		arg.WeekNumber,
+		arg.DayNumber,
+		arg.Name,
+	)
+	var i ProgramDay
+	err := row.Scan(
+		&i.DayID,
+		&i.ProgramID,
+		&i.WeekNumber,
+		&i.DayNumber,
+		&i.Name,
+	)
+	return i, err
+}
+
+const createProgramExercise = `-- name: CreateProgramExercise :one
+INSERT INTO program_exercises(day_id,exercise_order,exercise_name,exercise_id,target_sets,target_reps,load_percent,target_rpe,rest_seconds,notes)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+RETURNING prescription_id, day_id, exercise_order, exercise_name, exercise_id, target_sets, target_reps, load_percent, target_rpe, rest_seconds, notes
+`
+
+type CreateProgramExerciseParams struct {
+	DayID         int64          `json:"day_id"`
+	ExerciseOrder int32          `json:"exercise_order"`
+	ExerciseName  string         `json:"exercise_name"`
+	ExerciseID    pgtype.Int8    `json:"exercise_id"`
+	TargetSets    int32          `json:"target_sets"`
+	TargetReps    int32          `json:"target_reps"`
+	LoadPercent   pgtype.Numeric `json:"load_percent"`
+	TargetRpe     pgtype.Numeric `json:"target_rpe"`
+	RestSeconds   pgtype.Int4    `json:"rest_seconds"`
+	Notes         pgtype.Text    `json:"notes"`
+}
+
+func (q *Queries) CreateProgramExercise(ctx context.Context, arg CreateProgramExerciseParams) (ProgramExercise, error) {
+	row := q.db.QueryRow(ctx, createProgramExercise,
+		arg.DayID,
+		arg.ExerciseOrder,
+		arg.ExerciseName,
+		arg.ExerciseID,
+		arg.TargetSets,
+		arg.TargetReps,
+		arg.LoadPercent,
+		arg.TargetRpe,
+		arg.RestSeconds,
+		arg.Notes,
+	)
+	var i ProgramExercise
+	err := row.Scan(
+		&i.PrescriptionID,
+		&i.DayID,
+		&i.ExerciseOrder,
+		&i.ExerciseName,
+		&i.ExerciseID,
+		&i.TargetSets,
+		&i.TargetReps,
+		&i.LoadPercent,
+		&i.TargetRpe,
+		&i.RestSeconds,
+		&i.Notes,
+	)
+	return i, err
+}
+
+const createRecipe = `-- name: CreateRecipe :one
+INSERT INTO recipes(user_id,recipe_name,instructions,servings)
+VALUES($1,$2,$3,$4)
+RETURNING recipe_id, user_id, recipe_name, instructions, servings, created_at, last_updated
+`
+
+type CreateRecipeParams struct {
+	UserID       int64       `json:"user_id"`
+	RecipeName   string      `json:"recipe_name"`
+	Instructions pgtype.Text `json:"instructions"`
+	Servings     int32       `json:"servings"`
+}
+
+func (q *Queries) CreateRecipe(ctx context.Context, arg CreateRecipeParams) (Recipe, error) {
+	row := q.db.QueryRow(ctx, createRecipe,
+		arg.UserID,
+		arg.RecipeName,
+		arg.Instructions,
+		arg.Servings,
+	)
+	var i Recipe
+	err := row.Scan(
+		&i.RecipeID,
+		&i.UserID,
+		&i.RecipeName,
+		&i.Instructions,
+		&i.Servings,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const createRefreshToken = `-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens(user_id,family_id,token_hash,expires_at)
+VALUES($1,$2,$3,$4)
+RETURNING token_id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+`
+
+type CreateRefreshTokenParams struct {
+	UserID    int64            `json:"user_id"`
+	FamilyID  string           `json:"family_id"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+}
+
+func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error) {
+	row := q.db.QueryRow(ctx, createRefreshToken,
+		arg.UserID,
+		arg.FamilyID,
+		arg.TokenHash,
+		arg.ExpiresAt,
+	)
+	var i RefreshToken
+	err := row.Scan(
+		&i.TokenID,
+		&i.UserID,
+		&i.FamilyID,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (username, hashed_password)
+VALUES ($1, $2)
+RETURNING user_id, username
+`
+
+type CreateUserParams struct {
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+type CreateUserRow struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
+	row := q.db.QueryRow(ctx, createUser, arg.Username, arg.HashedPassword)
+	var i CreateUserRow
+	err := row.Scan(&i.UserID, &i.Username)
+	return i, err
+}
+
+const createUserProfile = `-- name: CreateUserProfile :exec
+INSERT INTO users_profile(user_id)
+VALUES($1)
+`
+
+func (q *Queries) CreateUserProfile(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, createUserProfile, userID)
+	return err
+}
+
+const createWorkoutSession = `-- name: CreateWorkoutSession :one
+INSERT INTO workout_sessions(user_id,title,notes,started_at,bodyweight)
+VALUES($1,$2,$3,$4,$5)
+RETURNING session_id, user_id, title, notes, started_at, ended_at, bodyweight, session_rpe, created_at, last_updated
+`
+
+type CreateWorkoutSessionParams struct {
+	UserID     int64            `json:"user_id"`
+	Title      string           `json:"title"`
+	Notes      pgtype.Text      `json:"notes"`
+	StartedAt  pgtype.Timestamp `json:"started_at"`
+	Bodyweight pgtype.Numeric   `json:"bodyweight"`
+}
+
+func (q *Queries) CreateWorkoutSession(ctx context.Context, arg CreateWorkoutSessionParams) (WorkoutSession, error) {
+	row := q.db.QueryRow(ctx, createWorkoutSession,
+		arg.UserID,
+		arg.Title,
+		arg.Notes,
+		arg.StartedAt,
+		arg.Bodyweight,
+	)
+	var i WorkoutSession
+	err := row.Scan(
+		&i.SessionID,
+		&i.UserID,
+		&i.Title,
+		&i.Notes,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.Bodyweight,
+		&i.SessionRpe,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const dailyFoodTotals = `-- name: DailyFoodTotals :many
+SELECT created_at::date AS day,
+       SUM(calories)::float AS calories,
+       SUM(protein)::float AS protein,
+       SUM(carbs)::float AS carbs,
+       SUM(fats)::float AS fats
+FROM food_entries
+WHERE user_id = $1
+  AND created_at >= $2
+  AND created_at < $3
+GROUP BY day
+ORDER BY day
+`
+
+type DailyFoodTotalsParams struct {
+	UserID   int64            `json:"user_id"`
+	DateFrom pgtype.Timestamp `json:"date_from"`
+	DateTo   pgtype.Timestamp `json:"date_to"`
+}
+
+type DailyFoodTotalsRow struct {
+	Day      pgtype.Date `json:"day"`
+	Calories float64     `json:"calories"`
+	Protein  float64     `json:"protein"`
+	Carbs    float64     `json:"carbs"`
+	Fats     float64     `json:"fats"`
+}
+
+func (q *Queries) DailyFoodTotals(ctx context.Context, arg DailyFoodTotalsParams) ([]DailyFoodTotalsRow, error) {
+	rows, err := q.db.Query(ctx, dailyFoodTotals, arg.UserID, arg.DateFrom, arg.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DailyFoodTotalsRow
+	for rows.Next() {
+		var i DailyFoodTotalsRow
+		if err := rows.Scan(
+			&i.Day,
+			&i.Calories,
+			&i.Protein,
+			&i.Carbs,
+			&i.Fats,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteBodyMeasurement = `-- name: DeleteBodyMeasurement :execrows
+DELETE FROM body_measurements
+WHERE measurement_id = $1 AND user_id = $2
+`
+
+type DeleteBodyMeasurementParams struct {
+	MeasurementID int64 `json:"measurement_id"`
+	UserID        int64 `json:"user_id"`
+}
+
+func (q *Queries) DeleteBodyMeasurement(ctx context.Context, arg DeleteBodyMeasurementParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteBodyMeasurement, arg.MeasurementID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteEntrySets = `-- name: DeleteEntrySets :exec
+DELETE FROM exercise_sets
+WHERE entry_id = $1
+`
+
+func (q *Queries) DeleteEntrySets(ctx context.Context, entryID int64) error {
+	_, err := q.db.Exec(ctx, deleteEntrySets, entryID)
+	return err
+}
+
+const deleteExerciseEntry = `-- name: DeleteExerciseEntry :execrows
+DELETE FROM exercise_entries
+WHERE entry_id = $1 AND user_id = $2
+`
+
+type DeleteExerciseEntryParams struct {
+	EntryID int64 `json:"entry_id"`
+	UserID  int64 `json:"user_id"`
+}
+
+func (q *Queries) DeleteExerciseEntry(ctx context.Context, arg DeleteExerciseEntryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExerciseEntry, arg.EntryID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExercisePersonalRecords = `-- name: DeleteExercisePersonalRecords :exec
+DELETE FROM personal_records
+WHERE user_id = $1 AND exercise_name = $2
+`
+
+type DeleteExercisePersonalRecordsParams struct {
+	UserID       int64  `json:"user_id"`
+	ExerciseName string `json:"exercise_name"`
+}
+
+func (q *Queries) DeleteExercisePersonalRecords(ctx context.Context, arg DeleteExercisePersonalRecordsParams) error {
+	_, err := q.db.Exec(ctx, deleteExercisePersonalRecords, arg.UserID, arg.ExerciseName)
+	return err
+}
+
+const deleteFoodEntry = `-- name: DeleteFoodEntry :execrows
+DELETE FROM food_entries
+WHERE nutrition_id = $1 AND user_id = $2
+`
+
+type DeleteFoodEntryParams struct {
+	NutritionID int64 `json:"nutrition_id"`
+	UserID      int64 `json:"user_id"`
+}
+
+func (q *Queries) DeleteFoodEntry(ctx context.Context, arg DeleteFoodEntryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteFoodEntry, arg.NutritionID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteFoodItem = `-- name: DeleteFoodItem :execrows
+DELETE FROM food
+WHERE food_id = $1 AND user_id = $2
+`
+
+type DeleteFoodItemParams struct {
+	FoodID int64 `json:"food_id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) DeleteFoodItem(ctx context.Context, arg DeleteFoodItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteFoodItem, arg.FoodID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRecipe = `-- name: DeleteRecipe :execrows
+DELETE FROM recipes
+WHERE recipe_id = $1 AND user_id = $2
+`
+
+type DeleteRecipeParams struct {
+	RecipeID int64 `json:"recipe_id"`
+	UserID   int64 `json:"user_id"`
+}
+
+func (q *Queries) DeleteRecipe(ctx context.Context, arg DeleteRecipeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRecipe, arg.RecipeID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRecipeIngredients = `-- name: DeleteRecipeIngredients :exec
+DELETE FROM recipe_ingredients
+WHERE recipe_id = $1
+`
+
+func (q *Queries) DeleteRecipeIngredients(ctx context.Context, recipeID int64) error {
+	_, err := q.db.Exec(ctx, deleteRecipeIngredients, recipeID)
+	return err
+}
+
+const deleteStaleLoginThrottles = `-- name: DeleteStaleLoginThrottles :execrows
+DELETE FROM login_throttle
+WHERE last_failure < $1
+`
+
+func (q *Queries) DeleteStaleLoginThrottles(ctx context.Context, lastFailure pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteStaleLoginThrottles, lastFailure)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users
+WHERE user_id = $1
+`
+
+// Tables without ON DELETE CASCADE to users must be cleared first with the
+// DeleteUser* queries
+func (q *Queries) DeleteUser(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUser, userID)
+	return err
+}
+
+const deleteUserExerciseEntries = `-- name: DeleteUserExerciseEntries :exec
+DELETE FROM exercise_entries
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserExerciseEntries(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserExerciseEntries, userID)
+	return err
+}
+
+const deleteUserExercises = `-- name: DeleteUserExercises :exec
+DELETE FROM exercises
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserExercises(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserExercises, userID)
+	return err
+}
+
+const deleteUserFoodCache = `-- name: DeleteUserFoodCache :exec
+DELETE FROM food_Cache
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserFoodCache(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserFoodCache, userID)
+	return err
+}
+
+const deleteUserFoodEntries = `-- name: DeleteUserFoodEntries :exec
+DELETE FROM food_entries
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserFoodEntries(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserFoodEntries, userID)
+	return err
+}
+
+const deleteUserFoods = `-- name: DeleteUserFoods :exec
+DELETE FROM food
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserFoods(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserFoods, userID)
+	return err
+}
+
+const deleteUserMeasurements = `-- name: DeleteUserMeasurements :exec
+DELETE FROM body_measurements
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserMeasurements(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserMeasurements, userID)
+	return err
+}
+
+const deleteUserNutritionTargets = `-- name: DeleteUserNutritionTargets :exec
+DELETE FROM nutrition_targets
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserNutritionTargets(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserNutritionTargets, userID)
+	return err
+}
+
+const deleteUserPersonalRecords = `-- name: DeleteUserPersonalRecords :exec
+DELETE FROM personal_records
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserPersonalRecords(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserPersonalRecords, userID)
+	return err
+}
+
+const deleteUserRecipeIngredients = `-- name: DeleteUserRecipeIngredients :exec
+DELETE FROM recipe_ingredients
+WHERE recipe_id IN (SELECT recipe_id FROM recipes WHERE user_id = $1)
+`
+
+func (q *Queries) DeleteUserRecipeIngredients(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserRecipeIngredients, userID)
+	return err
+}
+
+const deleteUserRecipes = `-- name: DeleteUserRecipes :exec
+DELETE FROM recipes
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserRecipes(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserRecipes, userID)
+	return err
+}
+
+const deleteUserWorkoutSessions = `-- name: DeleteUserWorkoutSessions :exec
+DELETE FROM workout_sessions
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserWorkoutSessions(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserWorkoutSessions, userID)
+	return err
+}
+
+const endActiveAssignments = `-- name: EndActiveAssignments :exec
+UPDATE program_assignments
+SET status = 'ended',
+    last_updated = CURRENT_TIMESTAMP
+WHERE client_id = $1 AND status = 'active'
+`
+
+func (q *Queries) EndActiveAssignments(ctx context.Context, clientID int64) error {
+	_, err := q.db.Exec(ctx, endActiveAssignments, clientID)
+	return err
+}
+
+const endActiveMealPlanAssignments = `-- name: EndActiveMealPlanAssignments :exec
+UPDATE meal_plan_assignments
+SET status = 'ended',
+    last_updated = CURRENT_TIMESTAMP
+WHERE client_id = $1 AND status = 'active'
+`
+
+func (q *Queries) EndActiveMealPlanAssignments(ctx context.Context, clientID int64) error {
+	_, err := q.db.Exec(ctx, endActiveMealPlanAssignments, clientID)
+	return err
+}
+
+const endTrainerRelationship = `-- name: EndTrainerRelationship :execrows
+UPDATE trainer_clients
+SET status = 'ended',
+    last_updated = CURRENT_TIMESTAMP
+WHERE relationship_id = $1
+  AND (trainer_id = $2 OR client_id = $2)
+  AND status IN ('pending', 'active')
+`
+
+type EndTrainerRelationshipParams struct {
+	RelationshipID int64 `json:"relationship_id"`
+	UserID         int64 `json:"user_id"`
+}
+
+func (q *Queries) EndTrainerRelationship(ctx context.Context, arg EndTrainerRelationshipParams) (int64, error) {
+	result, err := q.db.Exec(ctx, endTrainerRelationship, arg.RelationshipID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const finishWorkoutSession = `-- name: FinishWorkoutSession :one
+UPDATE workout_sessions
+SET ended_at = $3,
+    notes = $4,
+    bodyweight = $5,
+    session_rpe = $6,
+    last_updated = CURRENT_TIMESTAMP
+WHERE session_id = $1 AND user_id = $2 AND ended_at IS NULL
+RETURNING session_id, user_id, title, notes, started_at, ended_at, bodyweight, session_rpe, created_at, last_updated
+`
+
+type FinishWorkoutSessionParams struct {
+	SessionID  int64            `json:"session_id"`
+	UserID     int64            `json:"user_id"`
+	EndedAt    pgtype.Timestamp `json:"ended_at"`
+	Notes      pgtype.Text      `json:"notes"`
+	Bodyweight pgtype.Numeric   `json:"bodyweight"`
+	SessionRpe pgtype.Int4      `json:"session_rpe"`
+}
+
+func (q *Queries) FinishWorkoutSession(ctx context.Context, arg FinishWorkoutSessionParams) (WorkoutSession, error) {
+	row := q.db.QueryRow(ctx, finishWorkoutSession,
+		arg.SessionID,
+		arg.UserID,
+		arg.EndedAt,
+		arg.Notes,
+		arg.Bodyweight,
+		arg.SessionRpe,
+	)
+	var i WorkoutSession
+	err := row.Scan(
+		&i.SessionID,
+		&i.UserID,
+		&i.Title,
+		&i.Notes,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.Bodyweight,
+		&i.SessionRpe,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const getActiveAssignment = `-- name: GetActiveAssignment :one
+SELECT a.assignment_id, a.program_id, a.start_date, p.trainer_id, p.name, p.weeks
+FROM program_assignments a
+JOIN programs p ON p.program_id = a.program_id
+WHERE a.client_id = $1 AND a.status = 'active'
+`
+
+type GetActiveAssignmentRow struct {
+	AssignmentID int64       `json:"assignment_id"`
+	ProgramID    int64       `json:"program_id"`
+	StartDate    pgtype.Date `json:"start_date"`
+	TrainerID    int64       `json:"trainer_id"`
+	Name         string      `json:"name"`
+	Weeks        int32       `json:"weeks"`
+}
+
+func (q *Queries) GetActiveAssignment(ctx context.Context, clientID int64) (GetActiveAssignmentRow, error) {
+	row := q.db.QueryRow(ctx, getActiveAssignment, clientID)
+	var i GetActiveAssignmentRow
+	err := row.Scan(
+		&i.AssignmentID,
+		&i.ProgramID,
+		&i.StartDate,
+		&i.TrainerID,
+		&i.Name,
+		&i.Weeks,
+	)
+	return i, err
+}
+
+const getActiveMealPlanAssignment = `-- name: GetActiveMealPlanAssignment :one
+SELECT a.assignment_id, a.plan_id, a.start_date, p.trainer_id, p.name, p.cycle_days
+FROM meal_plan_assignments a
+JOIN meal_plans p ON p.plan_id = a.plan_id
+WHERE a.client_id = $1 AND a.status = 'active'
+`
+
+type GetActiveMealPlanAssignmentRow struct {
+	AssignmentID int64       `json:"assignment_id"`
+	PlanID       int64       `json:"plan_id"`
+	StartDate    pgtype.Date `json:"start_date"`
+	TrainerID    int64       `json:"trainer_id"`
+	Name         string      `json:"name"`
+	CycleDays    int32       `json:"cycle_days"`
+}
+
+func (q *Queries) GetActiveMealPlanAssignment(ctx context.Context, clientID int64) (GetActiveMealPlanAssignmentRow, error) {
+	row := q.db.QueryRow(ctx, getActiveMealPlanAssignment, clientID)
+	var i GetActiveMealPlanAssignmentRow
+	err := row.Scan(
+		&i.AssignmentID,
+		&i.PlanID,
+		&i.StartDate,
+		&i.TrainerID,
+		&i.Name,
+		&i.CycleDays,
+	)
+	return i, err
+}
+
+const getAssignedPlanItem = `-- name: GetAssignedPlanItem :one
+SELECT i.item_id, i.meal_id, i.item_order, i.food_id, i.recipe_id, i.item_name, i.total_grams, i.calories, i.protein, i.carbs, i.fats
+FROM meal_plan_items i
+JOIN meal_plan_meals m ON m.meal_id = i.meal_id
+JOIN meal_plan_days d ON d.day_id = m.day_id
+JOIN meal_plan_assignments a ON a.plan_id = d.plan_id
+WHERE i.item_id = $1
+  AND a.client_id = $2
+  AND a.status = 'active'
+`
+
+type GetAssignedPlanItemParams struct {
+	ItemID   int64 `json:"item_id"`
+	ClientID int64 `json:"client_id"`
+}
+
+// Only items from the client's active meal plan can be eaten
+func (q *Queries) GetAssignedPlanItem(ctx context.Context, arg GetAssignedPlanItemParams) (MealPlanItem, error) {
+	row := q.db.QueryRow(ctx, getAssignedPlanItem, arg.ItemID, arg.ClientID)
+	var i MealPlanItem
+	err := row.Scan(
+		&i.ItemID,
+		&i.MealID,
+		&i.ItemOrder,
+		&i.FoodID,
+		&i.RecipeID,
+		&i.ItemName,
+		&i.TotalGrams,
+		&i.Calories,
+		&i.Protein,
+		&i.Carbs,
+		&i.Fats,
+	)
+	return i, err
+}
+
+const getAssignedPrescription = `-- name: GetAssignedPrescription :one
+SELECT pe.prescription_id, pe.day_id, pe.exercise_order, pe.exercise_name, pe.exercise_id, pe.target_sets, pe.target_reps, pe.load_percent, pe.target_rpe, pe.rest_seconds, pe.notes
+FROM program_exercises pe
+JOIN program_days d ON d.day_id = pe.day_id
+JOIN program_assignments a ON a.program_id = d.program_id
+WHERE pe.prescription_id = $1
+  AND a.client_id = $2
+  AND a.status = 'active'
+`
+
+type GetAssignedPrescriptionParams struct {
+	PrescriptionID int64 `json:"prescription_id"`
+	ClientID       int64 `json:"client_id"`
+}
+
+// Only prescriptions from the client's active program can be logged against
+func (q *Queries) GetAssignedPrescription(ctx context.Context, arg GetAssignedPrescriptionParams) (ProgramExercise, error) {
+	row := q.db.QueryRow(ctx, getAssignedPrescription, arg.PrescriptionID, arg.ClientID)
+	var i ProgramExercise
+	err := row.Scan(
+		&i.PrescriptionID,
+		&i.DayID,
+		&i.ExerciseOrder,
+		&i.ExerciseName,
+		&i.ExerciseID,
+		&i.TargetSets,
+		&i.TargetReps,
+		&i.LoadPercent,
+		&i.TargetRpe,
+		&i.RestSeconds,
+		&i.Notes,
+	)
+	return i, err
+}
+
+const getExercise = `-- name: GetExercise :one
+SELECT exercise_id, user_id, name, aliases, primary_muscles, secondary_muscles, equipment, movement_pattern, created_at, last_updated
+FROM exercises
+WHERE exercise_id = $1 AND (user_id IS NULL OR user_id = $2)
+`
+
+type GetExerciseParams struct {
+	ExerciseID int64       `json:"exercise_id"`
+	UserID     pgtype.Int8 `json:"user_id"`
+}
+
+func (q *Queries) GetExercise(ctx context.Context, arg GetExerciseParams) (Exercise, error) {
+	row := q.db.QueryRow(ctx, getExercise, arg.ExerciseID, arg.UserID)
+	var i Exercise
+	err := row.Scan(
+		&i.ExerciseID,
+		&i.UserID,
+		&i.Name,
+		&i.Aliases,
+		&i.PrimaryMuscles,
+		&i.SecondaryMuscles,
+		&i.Equipment,
+		&i.MovementPattern,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const getExerciseEntry = `-- name: GetExerciseEntry :one
+SELECT entry_id, user_id, created_at, last_updated, exercise_name, weight, sets, reps, rpe, notes, session_id, exercise_id, estimated_1rm, prescription_id
+FROM exercise_entries
+WHERE entry_id = $1 AND user_id = $2
+`
+
+type GetExerciseEntryParams struct {
+	EntryID int64 `json:"entry_id"`
+	UserID  int64 `json:"user_id"`
+}
+
+func (q *Queries) GetExerciseEntry(ctx context.Context, arg GetExerciseEntryParams) (ExerciseEntry, error) {
+	row := q.db.QueryRow(ctx, getExerciseEntry, arg.EntryID, arg.UserID)
+	var i ExerciseEntry
+	err := row.Scan(
+		&i.EntryID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.LastUpdated,
+		&i.ExerciseName,
+		&i.Weight,
+		&i.Sets,
+		&i.Reps,
+		&i.Rpe,
+		&i.Notes,
+		&i.SessionID,
+		&i.ExerciseID,
+		&i.Estimated1rm,
+		&i.PrescriptionID,
+	)
+	return i, err
+}
+
+const getFoodCacheItem = `-- name: GetFoodCacheItem :one
+SELECT food_id, user_id, food_name, normalized_name, calories_100, protein_100, carbs_100, fats_100, use_count, last_used, created_at, last_updated
+FROM food_Cache
+WHERE food_id = $1 AND user_id = $2
+`
+
+type GetFoodCacheItemParams struct {
+	FoodID int64 `json:"food_id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) GetFoodCacheItem(ctx context.Context, arg GetFoodCacheItemParams) (FoodCache, error) {
+	row := q.db.QueryRow(ctx, getFoodCacheItem, arg.FoodID, arg.UserID)
+	var i FoodCache
+	err := row.Scan(
+		&i.FoodID,
+		&i.UserID,
+		&i.FoodName,
+		&i.NormalizedName,
+		&i.Calories100,
+		&i.Protein100,
+		&i.Carbs100,
+		&i.Fats100,
+		&i.UseCount,
+		&i.LastUsed,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const getFoodEntry = `-- name: GetFoodEntry :one
+SELECT nutrition_id, user_id, food_id, recipe_id, cache_id, calories, total_grams, protein, carbs, fats, created_at, last_updated, plan_item_id
+FROM food_entries
+WHERE nutrition_id = $1 AND user_id = $2
+`
+
+type GetFoodEntryParams struct {
+	NutritionID int64 `json:"nutrition_id"`
+	UserID      int64 `json:"user_id"`
+}
+
+func (q *Queries) GetFoodEntry(ctx context.Context, arg GetFoodEntryParams) (FoodEntry, error) {
+	row := q.db.QueryRow(ctx, getFoodEntry, arg.NutritionID, arg.UserID)
+	var i FoodEntry
+	err := row.Scan(
+		&i.NutritionID,
+		&i.UserID,
+		&i.FoodID,
+		&i.RecipeID,
+		&i.CacheID,
+		&i.Calories,
+		&i.TotalGrams,
+		&i.Protein,
+		&i.Carbs,
+		&i.Fats,
+		&i.CreatedAt,
+		&i.LastUpdated,
+		&i.PlanItemID,
+	)
+	return i, err
+}
+
+const getFoodItem = `-- name: GetFoodItem :one
+SELECT food_id, user_id, food_name, calories_100, protein_100, carbs_100, fats_100, created_at, last_updated
+FROM food
+WHERE food_id = $1 AND user_id = $2
+`
+
+type GetFoodItemParams struct {
+	FoodID int64 `json:"food_id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) GetFoodItem(ctx context.Context, arg GetFoodItemParams) (Food, error) {
+	row := q.db.QueryRow(ctx, getFoodItem, arg.FoodID, arg.UserID)
+	var i Food
+	err := row.Scan(
+		&i.FoodID,
+		&i.UserID,
+		&i.FoodName,
+		&i.Calories100,
+		&i.Protein100,
+		&i.Carbs100,
+		&i.Fats100,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const getLoginThrottle = `-- name: GetLoginThrottle :one
+SELECT throttle_key, failures, last_failure
+FROM login_throttle
+WHERE throttle_key = $1
+`
+
+func (q *Queries) GetLoginThrottle(ctx context.Context, throttleKey string) (LoginThrottle, error) {
+	row := q.db.QueryRow(ctx, getLoginThrottle, throttleKey)
+	var i LoginThrottle
+	err := row.Scan(&i.ThrottleKey, &i.Failures, &i.LastFailure)
+	return i, err
+}
+
+const getMealPlan = `-- name: GetMealPlan :one
+SELECT plan_id, trainer_id, name, description, cycle_days, created_at, last_updated
+FROM meal_plans
+WHERE plan_id = $1 AND trainer_id = $2
+`
+
+type GetMealPlanParams struct {
+	PlanID    int64 `json:"plan_id"`
+	TrainerID int64 `json:"trainer_id"`
+}
+
+func (q *Queries) GetMealPlan(ctx context.Context, arg GetMealPlanParams) (MealPlan, error) {
+	row := q.db.QueryRow(ctx, getMealPlan, arg.PlanID, arg.TrainerID)
+	var i MealPlan
+	err := row.Scan(
+		&i.PlanID,
+		&i.TrainerID,
+		&i.Name,
+		&i.Description,
+		&i.CycleDays,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const getProgram = `-- name: GetProgram :one
+SELECT program_id, trainer_id, name, description, weeks, created_at, last_updated
+FROM programs
+WHERE program_id = $1 AND trainer_id = $2
+`
+
+type GetProgramParams struct {
+	ProgramID int64 `json:"program_id"`
+	TrainerID int64 `json:"trainer_id"`
+}
+
+func (q *Queries) GetProgram(ctx context.Context, arg GetProgramParams) (Program, error) {
+	row := q.db.QueryRow(ctx, getProgram, arg.ProgramID, arg.TrainerID)
+	var i Program
+	err := row.Scan(
+		&i.ProgramID,
+		&i.TrainerID,
+		&i.Name,
+		&i.Description,
+		&i.Weeks,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const getProgramDay = `-- name: GetProgramDay :one
+SELECT day_id, program_id, week_number, day_number, name
+FROM program_days
+WHERE program_id = $1 AND week_number = $2 AND day_number = $3
+`
+
+type GetProgramDayParams struct {
+	ProgramID  int64 `json:"program_id"`
+	WeekNumber int32 `json:"week_number"`
+	DayNumber  int32 `json:"day_number"`
+}
+
+func (q *Queries) GetProgramDay(ctx context.Context, arg GetProgramDayParams) (ProgramDay, error) {
+	row := q.db.QueryRow(ctx, getProgramDay, arg.ProgramID, arg.WeekNumber, arg.DayNumber)
+	var i ProgramDay
+	err := row.Scan(
+		&i.DayID,
+		&i.ProgramID,
+		&i.WeekNumber,
+		&i.DayNumber,
+		&i.Name,
+	)
+	return i, err
+}
+
+const getRecipe = `-- name: GetRecipe :one
+SELECT recipe_id, user_id, recipe_name, instructions, servings, created_at, last_updated
+FROM recipes
+WHERE recipe_id = $1 AND user_id = $2
+`
+
+type GetRecipeParams struct {
+	RecipeID int64 `json:"recipe_id"`
+	UserID   int64 `json:"user_id"`
+}
+
+func (q *Queries) GetRecipe(ctx context.Context, arg GetRecipeParams) (Recipe, error) {
+	row := q.db.QueryRow(ctx, getRecipe, arg.RecipeID, arg.UserID)
+	var i Recipe
+	err := row.Scan(
+		&i.RecipeID,
+		&i.UserID,
+		&i.RecipeName,
+		&i.Instructions,
+		&i.Servings,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const getRefreshTokenByHash = `-- name: GetRefreshTokenByHash :one
+SELECT token_id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+FROM refresh_tokens
+WHERE token_hash = $1
+`
+
+func (q *Queries) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	row := q.db.QueryRow(ctx, getRefreshTokenByHash, tokenHash)
+	var i RefreshToken
+	err := row.Scan(
+		&i.TokenID,
+		&i.UserID,
+		&i.FamilyID,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT u.user_id, u.username
+FROM users u
+JOIN users_profile p ON p.user_id = u.user_id
+WHERE lower(p.email) = lower($1)
+`
+
+type GetUserByEmailRow struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) GetUserByEmail(ctx context.Context, lower string) (GetUserByEmailRow, error) {
+	row := q.db.QueryRow(ctx, getUserByEmail, lower)
+	var i GetUserByEmailRow
+	err := row.Scan(&i.UserID, &i.Username)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT user_id,username,hashed_password
+FROM users
+WHERE user_id = $1
+`
+
+type GetUserByIDRow struct {
+	UserID         int64  `json:"user_id"`
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+func (q *Queries) GetUserByID(ctx context.Context, userID int64) (GetUserByIDRow, error) {
+	row := q.db.QueryRow(ctx, getUserByID, userID)
+	var i GetUserByIDRow
+	err := row.Scan(&i.UserID, &i.Username, &i.HashedPassword)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT user_id,username, hashed_password
+FROM users
+WHERE lower(username) = lower($1)
+`
+
+type GetUserByUsernameRow struct {
+	UserID         int64  `json:"user_id"`
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+func (q *Queries) GetUserByUsername(ctx context.Context, lower string) (GetUserByUsernameRow, error) {
+	row := q.db.QueryRow(ctx, getUserByUsername, lower)
+	var i GetUserByUsernameRow
+	err := row.Scan(&i.UserID, &i.Username, &i.HashedPassword)
+	return i, err
+}
+
+const getUserProfile = `-- name: GetUserProfile :one
+SELECT user_id, date_of_birth, email, height, weight, sex, body_fat, preferred_units, is_trainer, is_vip, is_admin, last_updated
+FROM users_profile
+WHERE user_id = $1
+`
+
+func (q *Queries) GetUserProfile(ctx context.Context, userID int64) (UsersProfile, error) {
+	row := q.db.QueryRow(ctx, getUserProfile, userID)
+	var i UsersProfile
+	err := row.Scan(
+		&i.UserID,
+		&i.DateOfBirth,
+		&i.Email,
+		&i.Height,
+		&i.Weight,
+		&i.Sex,
+		&i.BodyFat,
+		&i.PreferredUnits,
+		&i.IsTrainer,
+		&i.IsVip,
+		&i.IsAdmin,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const getWorkoutSession = `-- name: GetWorkoutSession :one
+SELECT session_id, user_id, title, notes, started_at, ended_at, bodyweight, session_rpe, created_at, last_updated
+FROM workout_sessions
+WHERE session_id = $1 AND user_id = $2
+`
+
+type GetWorkoutSessionParams struct {
+	SessionID int64 `json:"session_id"`
+	UserID    int64 `json:"user_id"`
+}
+
+func (q *Queries) GetWorkoutSession(ctx context.Context, arg GetWorkoutSessionParams) (WorkoutSession, error) {
+	row := q.db.QueryRow(ctx, getWorkoutSession, arg.SessionID, arg.UserID)
+	var i WorkoutSession
+	err := row.Scan(
+		&i.SessionID,
+		&i.UserID,
+		&i.Title,
+		&i.Notes,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.Bodyweight,
+		&i.SessionRpe,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const invalidatePasswordResetTokens = `-- name: InvalidatePasswordResetTokens :exec
+UPDATE password_reset_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND used_at IS NULL
+`
+
+// Spends any outstanding tokens so only the most recent email works
+func (q *Queries) InvalidatePasswordResetTokens(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, invalidatePasswordResetTokens, userID)
+	return err
+}
+
+const inviteClient = `-- name: InviteClient :one
+INSERT INTO trainer_clients(trainer_id,client_id)
+VALUES($1,$2)
+ON CONFLICT (trainer_id, client_id) DO UPDATE
+SET status = 'pending',
+    invited_at = CURRENT_TIMESTAMP,
+    responded_at = NULL,
+    last_updated = CURRENT_TIMESTAMP
+WHERE trainer_clients.status IN ('declined', 'ended')
+RETURNING relationship_id, trainer_id, client_id, status, invited_at, responded_at, created_at, last_updated
+`
+
+type InviteClientParams struct {
+	TrainerID int64 `json:"trainer_id"`
+	ClientID  int64 `json:"client_id"`
+}
+
+// Re-inviting is allowed once an earlier invite was declined or the
+// relationship ended; a pending or active link returns no rows
+func (q *Queries) InviteClient(ctx context.Context, arg InviteClientParams) (TrainerClient, error) {
+	row := q.db.QueryRow(ctx, inviteClient, arg.TrainerID, arg.ClientID)
+	var i TrainerClient
+	err := row.Scan(
+		&i.RelationshipID,
+		&i.TrainerID,
+		&i.ClientID,
+		&i.Status,
+		&i.InvitedAt,
+		&i.RespondedAt,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const isAccessTokenRevoked = `-- name: IsAccessTokenRevoked :one
+SELECT EXISTS (
+    SELECT 1
+    FROM revoked_tokens
+    WHERE jti = $1
+)
+`
+
+func (q *Queries) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	row := q.db.QueryRow(ctx, isAccessTokenRevoked, jti)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const isTrainerOfClient = `-- name: IsTrainerOfClient :one
+SELECT EXISTS (
+    SELECT 1
+    FROM trainer_clients tc
+    JOIN users_profile p ON p.user_id = tc.trainer_id
+    WHERE tc.trainer_id = $1
+      AND tc.client_id = $2
+      AND tc.status = 'active'
+      AND p.is_trainer
+)
+`
+
+type IsTrainerOfClientParams struct {
+	TrainerID int64 `json:"trainer_id"`
+	ClientID  int64 `json:"client_id"`
+}
+
+// The trainer must still be flagged as one for the link to count
+func (q *Queries) IsTrainerOfClient(ctx context.Context, arg IsTrainerOfClientParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isTrainerOfClient, arg.TrainerID, arg.ClientID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listBodyMeasurements = `-- name: ListBodyMeasurements :many
+SELECT measurement_id, user_id, measured_at, bodyweight, body_fat, waist, chest, arms, thighs, notes, created_at, last_updated
+FROM body_measurements
+WHERE user_id = $1
+  AND ($2::timestamp IS NULL OR measured_at >= $2)
+  AND ($3::timestamp IS NULL OR measured_at < $3)
+ORDER BY measured_at, measurement_id
+`
+
+type ListBodyMeasurementsParams struct {
+	UserID   int64            `json:"user_id"`
+	DateFrom pgtype.Timestamp `json:"date_from"`
+	DateTo   pgtype.Timestamp `json:"date_to"`
+}
+
+func (q *Queries) ListBodyMeasurements(ctx context.Context, arg ListBodyMeasurementsParams) ([]BodyMeasurement, error) {
+	rows, err := q.db.Query(ctx, listBodyMeasurements, arg.UserID, arg.DateFrom, arg.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BodyMeasurement
+	for rows.Next() {
+		var i BodyMeasurement
+		if err := rows.Scan(
+			&i.MeasurementID,
+			&i.UserID,
+			&i.MeasuredAt,
+			&i.Bodyweight,
+			&i.BodyFat,
+			&i.Waist,
+			&i.Chest,
+			&i.Arms,
+			&i.Thighs,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.LastUpdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBodyweightReadings = `-- name: ListBodyweightReadings :many
+SELECT measured_at, bodyweight::float AS weight
+FROM body_measurements
+WHERE user_id = $1
+  AND bodyweight IS NOT NULL
+  AND measured_at >= $2
+UNION ALL
+SELECT started_at, bodyweight::float
+FROM workout_sessions
+WHERE user_id = $1
+  AND bodyweight IS NOT NULL
+  AND started_at >= $2
+ORDER BY measured_at
+`
+
+type ListBodyweightReadingsParams struct {
+	UserID   int64            `json:"user_id"`
+	DateFrom pgtype.Timestamp `json:"date_from"`
+}
+
+type ListBodyweightReadingsRow struct {
+	MeasuredAt pgtype.Timestamp `json:"measured_at"`
+	Weight     float64          `json:"weight"`
+}
+
+// Bodyweight from the measurement log plus any weigh-ins recorded on
+// workout sessions
+func (q *Queries) ListBodyweightReadings(ctx context.Context, arg ListBodyweightReadingsParams) ([]ListBodyweightReadingsRow, error) {
+	rows, err := q.db.Query(ctx, listBodyweightReadings, arg.UserID, arg.DateFrom)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBodyweightReadingsRow
+	for rows.Next() {
+		var i ListBodyweightReadingsRow
+		if err := rows.Scan(&i.MeasuredAt, &i.Weight); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listClientTrainers = `-- name: ListClientTrainers :many
+SELECT tc.relationship_id, tc.trainer_id AS user_id, u.username, tc.status, tc.invited_at, tc.responded_at
+FROM trainer_clients tc
+JOIN users u ON u.user_id = tc.trainer_id
+WHERE tc.client_id = $1
+  AND tc.status IN ('pending', 'active')
+ORDER BY tc.invited_at DESC
+`
+
+type ListClientTrainersRow struct {
+	RelationshipID int64            `json:"relationship_id"`
+	UserID         int64            `json:"user_id"`
+	Username       string           `json:"username"`
+	Status         string           `json:"status"`
+	InvitedAt      pgtype.Timestamp `json:"invited_at"`
+	RespondedAt    pgtype.Timestamp `json:"responded_at"`
+}
+
+func (q *Queries) ListClientTrainers(ctx context.Context, clientID int64) ([]ListClientTrainersRow, error) {
+	rows, err := q.db.Query(ctx, listClientTrainers, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListClientTrainersRow
+	for rows.Next() {
+		var i ListClientTrainersRow
+		if err := rows.Scan(
+			&i.RelationshipID,
+			&i.UserID,
+			&i.Username,
+			&i.Status,
+			&i.InvitedAt,
+			&i.RespondedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDayExercises = `-- name: ListDayExercises :many
+SELECT prescription_id, day_id, exercise_order, exercise_name, exercise_id, target_sets, target_reps, load_percent, target_rpe, rest_seconds, notes
+FROM program_exercises
+WHERE day_id = $1
+ORDER BY exercise_order
+`
+
+func (q *Queries) ListDayExercises(ctx context.Context, dayID int64) ([]ProgramExercise, error) {
+	rows, err := q.db.Query(ctx, listDayExercises, dayID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProgramExercise
+	for rows.Next() {
+		var i ProgramExercise
+		if err := rows.Scan(
+			&i.PrescriptionID,
+			&i.DayID,
+			&i.ExerciseOrder,
+			&i.ExerciseName,
+			&i.ExerciseID,
+			&i.TargetSets,
+			&i.TargetReps,
+			&i.LoadPercent,
+			&i.TargetRpe,
+			&i.RestSeconds,
+			&i.Notes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntrySets = `-- name: ListEntrySets :many
+SELECT set_id, entry_id, set_order, set_type, weight, reps, rpe, completed, created_at, last_updated
+FROM exercise_sets
+WHERE entry_id = $1
+ORDER BY set_order
+`
+
+func (q *Queries) ListEntrySets(ctx context.Context, entryID int64) ([]ExerciseSet, error) {
+	rows, err := q.db.Query(ctx, listEntrySets, entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseSet
+	for rows.Next() {
+		var i ExerciseSet
+		if err := rows.Scan(
+			&i.SetID,
+			&i.EntryID,
+			&i.SetOrder,
+			&i.SetType,
+			&i.Weight,
+			&i.Reps,
+			&i.Rpe,
+			&i.Completed,
+			&i.CreatedAt,
+			&i.LastUpdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExerciseEntries = `-- name: ListExerciseEntries :many
+SELECT entry_id, user_id, created_at, last_updated, exercise_name, weight, sets, reps, rpe, notes, session_id, exercise_id, estimated_1rm, prescription_id
+FROM exercise_entries
+WHERE user_id = $1
+  AND ($2::timestamp IS NULL OR created_at >= $2)
+  AND ($3::timestamp IS NULL OR created_at < $3)
+  AND ($4::text IS NULL
+       OR exercise_name = $4
+       OR exercise_id = $5::bigint)
+  AND ($6::timestamp IS NULL
+       OR (created_at, entry_id) < ($6, $7::bigint))
+ORDER BY created_at DESC, entry_id DESC
+LIMIT $8
+`
+
+type ListExerciseEntriesParams struct {
+	UserID          int64            `json:"user_id"`
+	DateFrom        pgtype.Timestamp `json:"date_from"`
+	DateTo          pgtype.Timestamp `json:"date_to"`
+	ExerciseName    pgtype.Text      `json:"exercise_name"`
+	ExerciseID      pgtype.Int8      `json:"exercise_id"`
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorEntryID   int64            `json:"cursor_entry_id"`
+	PageLimit       int32            `json:"page_limit"`
+}
+
+func (q *Queries) ListExerciseEntries(ctx context.Context, arg ListExerciseEntriesParams) ([]ExerciseEntry, error) {
+	rows, err := q.db.Query(ctx, listExerciseEntries,
+		arg.UserID,
+		arg.DateFrom,
+		arg.DateTo,
+		arg.ExerciseName,
+		arg.ExerciseID,
+		arg.CursorCreatedAt,
+		arg.CursorEntryID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseEntry
+	for rows.Next() {
+		var i ExerciseEntry
+		if err := rows.Scan(
+			&i.EntryID,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.LastUpdated,
+			&i.ExerciseName,
+			&i.Weight,
+			&i.Sets,
+			&i.Reps,
+			&i.Rpe,
+			&i.Notes,
+			&i.SessionID,
+			&i.ExerciseID,
+			&i.Estimated1rm,
+			&i.PrescriptionID,
+			&i.PrescriptionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExerciseHistory = `-- name: ListExerciseHistory :many
+SELECT entry_id, user_id, created_at, last_updated, exercise_name, weight, sets, reps, rpe, notes, session_id, exercise_id, estimated_1rm, prescription_id
+FROM exercise_entries
+WHERE user_id = $1
+  AND (exercise_name = $2 OR exercise_id = $3::bigint)
+ORDER BY created_at, entry_id
+`
+
+type ListExerciseHistoryParams struct {
+	UserID       int64       `json:"user_id"`
+	ExerciseName string      `json:"exercise_name"`
+	ExerciseID   pgtype.Int8 `json:"exercise_id"`
+}
+
+func (q *Queries) ListExerciseHistory(ctx context.Context, arg ListExerciseHistoryParams) ([]ExerciseEntry, error) {
+	rows, err := q.db.Query(ctx, listExerciseHistory, arg.UserID, arg.ExerciseName, arg.ExerciseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseEntry
+	for rows.Next() {
+		var i ExerciseEntry
+		if err := rows.Scan(
+			&i.EntryID,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.LastUpdated,
+			&i.ExerciseName,
+			&i.Weight,
+			&i.Sets,
+			&i.Reps,
+			&i.Rpe,
+			&i.Notes,
+			&i.SessionID,
+			&i.ExerciseID,
+			&i.Estimated1rm,
+			&i.PrescriptionID,
+			&i.PrescriptionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExercises = `-- name: ListExercises :many
+SELECT exercise_id, user_id, name, aliases, primary_muscles, secondary_muscles, equipment, movement_pattern, created_at, last_updated
+FROM exercises
+WHERE (user_id IS NULL OR user_id = $1)
+  AND ($2::text = ''
+       OR name ILIKE '%' || replace(replace(replace($2::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+       OR EXISTS (SELECT 1 FROM unnest(aliases) AS alias WHERE alias ILIKE '%' || replace(replace(replace($2::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'))
+  AND ($3::text = ''
+       OR $3::text = ANY(primary_muscles)
+       OR $3::text = ANY(secondary_muscles))
+ORDER BY name, exercise_id
+`
+
+type ListExercisesParams struct {
+	UserID pgtype.Int8 `json:"user_id"`
+	Search string      `json:"search"`
+	Muscle string      `json:"muscle"`
+}
+
+// The search term is matched literally, with \, % and _ escaped
+func (q *Queries) ListExercises(ctx context.Context, arg ListExercisesParams) ([]Exercise, error) {
+	rows, err := q.db.Query(ctx, listExercises, arg.UserID, arg.Search, arg.Muscle)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Exercise
+	for rows.Next() {
+		var i Exercise
+		if err := rows.Scan(
+			&i.ExerciseID,
+			&i.UserID,
+			&i.Name,
+			&i.Aliases,
+			&i.PrimaryMuscles,
+			&i.SecondaryMuscles,
+			&i.Equipment,
+			&i.MovementPattern,
+			&i.CreatedAt,
+			&i.LastUpdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFoodItems = `-- name: ListFoodItems :many
+SELECT food_id, user_id, food_name, calories_100, protein_100, carbs_100, fats_100, created_at, last_updated
+FROM food
+WHERE user_id = $1
+  AND food_name ILIKE '%' || replace(replace(replace($2::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+ORDER BY food_name, food_id
+LIMIT $3 OFFSET $4
+`
+
+type ListFoodItemsParams struct {
+	UserID     int64  `json:"user_id"`
+	Search     string `json:"search"`
+	PageLimit  int32  `json:"page_limit"`
+	PageOffset int32  `json:"page_offset"`
+}
+
+// The search term is matched literally, with \, % and _ escaped
+func (q *Queries) ListFoodItems(ctx context.Context, arg ListFoodItemsParams) ([]Food, error) {
+	rows, err := q.db.Query(ctx, listFoodItems,
+		arg.UserID,
+		arg.Search,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Food
+	for rows.Next() {
+		var i Food
+		if err := rows.Scan(
+			&i.FoodID,
+			&i.UserID,
+			&i.FoodName,
+			&i.Calories100,
+			&i.Protein100,
+			&i.Carbs100,
+			&i.Fats100,
+			&i.CreatedAt,
+			&i.LastUpdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMealPlanItems = `-- name: ListMealPlanItems :many
+SELECT d.day_id, d.day_number, d.name AS day_name,
+       m.meal_id, m.meal_order, m.name AS meal_name,
+       i.item_id, i.item_order, i.food_id, i.recipe_id, i.item_name,
+       i.total_grams, i.calories, i.protein, i.carbs, i.fats
+FROM meal_plan_days d
+JOIN meal_plan_meals m ON m.day_id = d.day_id
+JOIN meal_plan_items i ON i.meal_id = m.meal_id
+WHERE d.plan_id = $1
+  AND ($2::int IS NULL OR d.day_number = $2)
+ORDER BY d.day_number, m.meal_order, i.item_order
+`
+
+type ListMealPlanItemsParams struct {
+	PlanID    int64       `json:"plan_id"`
+	DayNumber pgtype.Int4 `json:"day_number"`
+}
+
+type ListMealPlanItemsRow struct {
+	DayID      int64       `json:"day_id"`
+	DayNumber  int32       `json:"day_number"`
+	DayName    pgtype.Text `json:"day_name"`
+	MealID     int64       `json:"meal_id"`
+	MealOrder  int32       `json:"meal_order"`
+	MealName   string      `json:"meal_name"`
+	ItemID     int64       `json:"item_id"`
+	ItemOrder  int32       `json:"item_order"`
+	FoodID     pgtype.Int8 `json:"food_id"`
+	RecipeID   pgtype.Int8 `json:"recipe_id"`
+	ItemName   string      `json:"item_name"`
+	TotalGrams float64     `json:"total_grams"`
+	Calories   float64     `json:"calories"`
+	Protein    float64     `json:"protein"`
+	Carbs      float64     `json:"carbs"`
+	Fats       float64     `json:"fats"`
+}
+
+// Flattened plan contents, optionally for a single day
+func (q *Queries) ListMealPlanItems(ctx context.Context, arg ListMealPlanItemsParams) ([]ListMealPlanItemsRow, error) {
+	rows, err := q.db.Query(ctx, listMealPlanItems, arg.PlanID, arg.DayNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMealPlanItemsRow
+	for rows.Next() {
+		var i ListMealPlanItemsRow
+		if err := rows.Scan(
+			&i.DayID,
+			&i.DayNumber,
+			&i.DayName,
+			&i.MealID,
+			&i.MealOrder,
+			&i.MealName,
+			&i.ItemID,
+			&i.ItemOrder,
+			&i.FoodID,
+			&i.RecipeID,
+			&i.ItemName,
+			&i.TotalGrams,
+			&i.Calories,
+			&i.Protein,
+			&i.Carbs,
+			&i.Fats,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMealPlans = `-- name: ListMealPlans :many
+SELECT plan_id, trainer_id, name, description, cycle_days, created_at, last_updated
+FROM meal_plans
+WHERE trainer_id = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListMealPlans(ctx context.Context, trainerID int64) ([]MealPlan, error) {
+	rows, err := q.db.Query(ctx, listMealPlans, trainerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MealPlan
+	for rows.Next() {
+		var i MealPlan
+		if err := rows.Scan(
+			&i.PlanID,
+			&i.TrainerID,
+			&i.Name,
+			&i.Description,
+			&i.CycleDays,
+			&i.CreatedAt,
+			&i.LastUpdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNutritionTargets = `-- name: ListNutritionTargets :many
+SELECT target_id, user_id, day_type, effective_from, calories, protein, carbs, fats, created_at, last_updated, plan_item_id
+FROM nutrition_targets
+WHERE user_id = $1
+ORDER BY effective_from DESC, day_type
+`
+
+func (q *Queries) ListNutritionTargets(ctx context.Context, userID int64) ([]NutritionTarget, error) {
+	rows, err := q.db.Query(ctx, listNutritionTargets, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NutritionTarget
+	for rows.Next() {
+		var i NutritionTarget
+		if err := rows.Scan(
+			&i.TargetID,
+			&i.UserID,
+			&i.DayType,
+			&i.EffectiveFrom,
+			&i.Calories,
+			&i.Protein,
+			&i.Carbs,
+			&i.Fats,
+			&i.CreatedAt,
+			&i.LastUpdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPersonalRecords = `-- name: ListPersonalRecords :many
+SELECT record_id, user_id, exercise_name, record_type, value, weight, reps, entry_id, achieved_at, created_at, last_updated
+FROM personal_records
+WHERE user_id = $1
+  AND ($2::text IS NULL OR exercise_name = $2)
+ORDER BY exercise_name, record_type
+`
+
+type ListPersonalRecordsParams struct {
+	UserID       int64       `json:"user_id"`
+	ExerciseName pgtype.Text `json:"exercise_name"`
+}
+
+func (q *Queries) ListPersonalRecords(ctx context.Context, arg ListPersonalRecordsParams) ([]PersonalRecord, error) {
+	rows, err := q.db.Query(ctx, listPersonalRecords, arg.UserID, arg.ExerciseName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PersonalRecord
+	for rows.Next() {
+		var i PersonalRecord
+		if err := rows.Scan(
+			&i.RecordID,
+			&i.UserID,
+			&i.ExerciseName,
+			&i.RecordType,
+			&i.Value,
+			&i.Weight,
+			&i.Reps,
+			&i.EntryID,
+			&i.AchievedAt,
+			&i.CreatedAt,
+			&i.LastUpdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPlanItemEntries = `-- name: ListPlanItemEntries :many
+SELECT nutrition_id, user_id, food_id, recipe_id, cache_id, calories, total_grams, protein, carbs, fats, created_at, last_updated, plan_item_id
+FROM food_entries
+WHERE user_id = $1
+  AND plan_item_id IS NOT NULL
+  AND created_at >= $2
+  AND created_at < $3
+ORDER BY created_at
+`
+
+type ListPlanItemEntriesParams struct {
+	UserID   int64            `json:"user_id"`
+	DateFrom pgtype.Timestamp `json:"date_from"`
+	DateTo   pgtype.Timestamp `json:"date_to"`
+}
+
+func (q *Queries) ListPlanItemEntries(ctx context.Context, arg ListPlanItemEntriesParams) ([]FoodEntry, error) {
+	rows, err := q.db.Query(ctx, listPlanItemEntries, arg.UserID, arg.DateFrom, arg.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FoodEntry
+	for rows.Next() {
+		var i FoodEntry
+		if err := rows.Scan(
+			&i.NutritionID,
+			&i.UserID,
+			&i.FoodID,
+			&i.RecipeID,
+			&i.CacheID,
+			&i.Calories,
+			&i.TotalGrams,
+			&i.Protein,
+			&i.Carbs,
+			&i.Fats,
+			&i.CreatedAt,
+			&i.LastUpdated,
+			&i.PlanItemID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPrescriptionEntries = `-- name: ListPrescriptionEntries :many
+SELECT e.entry_id, e.user_id, e.created_at, e.last_updated, e.exercise_name, e.weight, e.sets, e.reps, e.rpe, e.notes, e.session_id, e.exercise_id, e.estimated_1rm, e.prescription_id
+FROM exercise_entries e
+JOIN program_exercises pe ON pe.prescription_id = e.prescription_id
+JOIN program_days d ON d.day_id = pe.day_id
+WHERE e.user_id = $1
+  AND d.program_id = $2
+  AND e.created_at >= $3
+ORDER BY e.created_at
+`
+
+type ListPrescriptionEntriesParams struct {
+	UserID    int64            `json:"user_id"`
+	ProgramID int64            `json:"program_id"`
+	Since     pgtype.Timestamp `json:"since"`
+}
+
+func (q *Queries) ListPrescriptionEntries(ctx context.Context, arg ListPrescriptionEntriesParams) ([]ExerciseEntry, error) {
+	rows, err := q.db.Query(ctx, listPrescriptionEntries, arg.UserID, arg.ProgramID, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseEntry
+	for rows.Next() {
+		var i ExerciseEntry
+		if err := rows.Scan(
+			&i.EntryID,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.LastUpdated,
+			&i.ExerciseName,
+			&i.Weight,
+			&i.Sets,
+			&i.Reps,
+			&i.Rpe,
+			&i.Notes,
+			&i.SessionID,
+			&i.ExerciseID,
+			&i.Estimated1rm,
+			&i.PrescriptionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProgramDays = `-- name: ListProgramDays :many
+SELECT day_id, program_id, week_number, day_number, name
+FROM program_days
+WHERE program_id = $1
+ORDER BY week_number, day_number
+`
+
+func (q *Queries) ListProgramDays(ctx context.Context, programID int64) ([]ProgramDay, error) {
+	rows, err := q.db.Query(ctx, listProgramDays, programID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProgramDay
+	for rows.Next() {
+		var i ProgramDay
+		if err := rows.Scan(
+			&i.DayID,
+			&i.ProgramID,
+			&i.WeekNumber,
+			&i.DayNumber,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProgramExercises = `-- name: ListProgramExercises :many
+SELECT pe.prescription_id, pe.day_id, pe.exercise_order, pe.exercise_name, pe.exercise_id, pe.target_sets, pe.target_reps, pe.load_percent, pe.target_rpe, pe.rest_seconds, pe.notes
+FROM program_exercises pe
+JOIN program_days d ON d.day_id = pe.day_id
+WHERE d.program_id = $1
+ORDER BY d.week_number, d.day_number, pe.exercise_order
+`
+
+func (q *Queries) ListProgramExercises(ctx context.Context, programID int64) ([]ProgramExercise, error) {
+	rows, err := q.db.Query(ctx, listProgramExercises, programID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProgramExercise
+	for rows.Next() {
+		var i ProgramExercise
+		if err := rows.Scan(
+			&i.PrescriptionID,
+			&i.DayID,
+			&i.ExerciseOrder,
+			&i.ExerciseName,
+			&i.ExerciseID,
+			&i.TargetSets,
+			&i.TargetReps,
+			&i.LoadPercent,
+			&i.TargetRpe,
+			&i.RestSeconds,
+			&i.Notes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPrograms = `-- name: ListPrograms :many
+SELECT program_id, trainer_id, name, description, weeks, created_at, last_updated
+FROM programs
+WHERE trainer_id = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListPrograms(ctx context.Context, trainerID int64) ([]Program, error) {
+	rows, err := q.db.Query(ctx, listPrograms, trainerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Program
+	for rows.Next() {
+		var i Program
+		if err := rows.Scan(
+			&i.ProgramID,
+			&i.TrainerID,
+			&i.Name,
+			&i.Description,
+			&i.Weeks,
+			&i.CreatedAt,
+			&i.LastUpdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecentFoods = `-- name: ListRecentFoods :many
+SELECT food_id, user_id, food_name, normalized_name, calories_100, protein_100, carbs_100, fats_100, use_count, last_used, created_at, last_updated
+FROM food_Cache
+WHERE user_id = $1
+ORDER BY use_count / (1 + EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - last_used)) / 604800.0) DESC,
+         last_used DESC
+LIMIT $2
+`
+
+type ListRecentFoodsParams struct {
+	UserID int64 `json:"user_id"`
+	Limit  int32 `json:"limit"`
+}
+
+func (q *Queries) ListRecentFoods(ctx context.Context, arg ListRecentFoodsParams) ([]FoodCache, error) {
+	rows, err := q.db.Query(ctx, listRecentFoods, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FoodCache
+	for rows.Next() {
+		var i FoodCache
+		if err := rows.Scan(
+			&i.FoodID,
+			&i.UserID,
+			&i.FoodName,
+			&i.NormalizedName,
+			&i.Calories100,
+			&i.Protein100,
+			&i.Carbs100,
+			&i.Fats100,
+			&i.UseCount,
+			&i.LastUsed,
+			&i.CreatedAt,
+			&i.LastUpdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecipeIngredients = `-- name: ListRecipeIngredients :many
+SELECT ri.ingredient_id, ri.recipe_id, ri.food_id, f.food_name, ri.total_grams,
+       f.calories_100, f.protein_100, f.carbs_100, f.fats_100
+FROM recipe_ingredients ri
+JOIN food f ON f.food_id = ri.food_id
+WHERE ri.recipe_id = $1
+ORDER BY ri.ingredient_id
+`
+
+type ListRecipeIngredientsRow struct {
+	IngredientID int64   `json:"ingredient_id"`
+	RecipeID     int64   `json:"recipe_id"`
+	FoodID       int64   `json:"food_id"`
+	FoodName     string  `json:"food_name"`
+	TotalGrams   float64 `json:"total_grams"`
+	Calories100  float64 `json:"calories_100"`
+	Protein100   float64 `json:"protein_100"`
+	Carbs100     float64 `json:"carbs_100"`
+	Fats100      float64 `json:"fats_100"`
+}
+
+func (q *Queries) ListRecipeIngredients(ctx context.Context, recipeID int64) ([]ListRecipeIngredientsRow, error) {
+	rows, err := q.db.Query(ctx, listRecipeIngredients, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRecipeIngredientsRow
+	for rows.Next() {
+		var i ListRecipeIngredientsRow
+		if err := rows.Scan(
+			&i.IngredientID,
+			&i.RecipeID,
+			&i.FoodID,
+			&i.FoodName,
+			&i.TotalGrams,
+			&i.Calories100,
+			&i.Protein100,
+			&i.Carbs100,
+			&i.Fats100,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecipes = `-- name: ListRecipes :many
+SELECT recipe_id, user_id, recipe_name, instructions, servings, created_at, last_updated
+FROM recipes
+WHERE user_id = $1
+ORDER BY recipe_name
+`
+
+func (q *Queries) ListRecipes(ctx context.Context, userID int64) ([]Recipe, error) {
+	rows, err := q.db.Query(ctx, listRecipes, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Recipe
+	for rows.Next() {
+		var i Recipe
+		if err := rows.Scan(
+			&i.RecipeID,
+			&i.UserID,
+			&i.RecipeName,
+			&i.Instructions,
+			&i.Servings,
+			&i.CreatedAt,
+			&i.LastUpdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionEntries = `-- name: ListSessionEntries :many
+SELECT entry_id, user_id, created_at, last_updated, exercise_name, weight, sets, reps, rpe, notes, session_id, exercise_id, estimated_1rm, prescription_id
+FROM exercise_entries
+WHERE session_id = $1
+ORDER BY created_at, entry_id
+`
+
+func (q *Queries) ListSessionEntries(ctx context.Context, sessionID pgtype.Int8) ([]ExerciseEntry, error) {
+	rows, err := q.db.Query(ctx, listSessionEntries, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseEntry
+	for rows.Next() {
+		var i ExerciseEntry
+		if err := rows.Scan(
+			&i.EntryID,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.LastUpdated,
+			&i.ExerciseName,
+			&i.Weight,
+			&i.Sets,
+			&i.Reps,
+			&i.Rpe,
+			&i.Notes,
+			&i.SessionID,
+			&i.ExerciseID,
+			&i.Estimated1rm,
+			&i.PrescriptionID,
+			&i.PrescriptionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionSets = `-- name: ListSessionSets :many
+SELECT s.set_id, s.entry_id, s.set_order, s.set_type, s.weight, s.reps, s.rpe, s.completed, s.created_at, s.last_updated
+FROM exercise_sets s
+JOIN exercise_entries e ON e.entry_id = s.entry_id
+WHERE e.session_id = $1
+ORDER BY s.entry_id, s.set_order
+`
+
+func (q *Queries) ListSessionSets(ctx context.Context, sessionID pgtype.Int8) ([]ExerciseSet, error) {
+	rows, err := q.db.Query(ctx, listSessionSets, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseSet
+	for rows.Next() {
+		var i ExerciseSet
+		if err := rows.Scan(
+			&i.SetID,
+			&i.EntryID,
+			&i.SetOrder,
+			&i.SetType,
+			&i.Weight,
+			&i.Reps,
+			&i.Rpe,
+			&i.Completed,
+			&i.CreatedAt,
+			&i.LastUpdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSetsForEntries = `-- name: ListSetsForEntries :many
+SELECT set_id, entry_id, set_order, set_type, weight, reps, rpe, completed, created_at, last_updated
+FROM exercise_sets
+WHERE entry_id = ANY($1::bigint[])
+ORDER BY entry_id, set_order
+`
+
+func (q *Queries) ListSetsForEntries(ctx context.Context, entryIds []int64) ([]ExerciseSet, error) {
+	rows, err := q.db.Query(ctx, listSetsForEntries, entryIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseSet
+	for rows.Next() {
+		var i ExerciseSet
+		if err := rows.Scan(
+			&i.SetID,
+			&i.EntryID,
+			&i.SetOrder,
+			&i.SetType,
+			&i.Weight,
+			&i.Reps,
+			&i.Rpe,
+			&i.Completed,
+			&i.CreatedAt,
+			&i.LastUpdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrainerClients = `-- name: ListTrainerClients :many
+SELECT tc.relationship_id, tc.client_id AS user_id, u.username, tc.status, tc.invited_at, tc.responded_at
+FROM trainer_clients tc
+JOIN users u ON u.user_id = tc.client_id
+WHERE tc.trainer_id = $1
+  AND tc.status IN ('pending', 'active')
+ORDER BY u.username
+`
+
+type ListTrainerClientsRow struct {
+	RelationshipID int64            `json:"relationship_id"`
+	UserID         int64            `json:"user_id"`
+	Username       string           `json:"username"`
+	Status         string           `json:"status"`
+	InvitedAt      pgtype.Timestamp `json:"invited_at"`
+	RespondedAt    pgtype.Timestamp `json:"responded_at"`
+}
+
+func (q *Queries) ListTrainerClients(ctx context.Context, trainerID int64) ([]ListTrainerClientsRow, error) {
+	rows, err := q.db.Query(ctx, listTrainerClients, trainerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTrainerClientsRow
+	for rows.Next() {
+		var i ListTrainerClientsRow
+		if err := rows.Scan(
+			&i.RelationshipID,
+			&i.UserID,
+			&i.Username,
+			&i.Status,
+			&i.InvitedAt,
+			&i.RespondedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrainingDays = `-- name: ListTrainingDays :many
+SELECT DISTINCT created_at::date AS day
+FROM exercise_entries
+WHERE user_id = $1
+  AND created_at >= $2
+  AND created_at < $3
+ORDER BY day
+`
+
+type ListTrainingDaysParams struct {
+	UserID   int64            `json:"user_id"`
+	DateFrom pgtype.Timestamp `json:"date_from"`
+	DateTo   pgtype.Timestamp `json:"date_to"`
+}
+
+func (q *Queries) ListTrainingDays(ctx context.Context, arg ListTrainingDaysParams) ([]pgtype.Date, error) {
+	rows, err := q.db.Query(ctx, listTrainingDays, arg.UserID, arg.DateFrom, arg.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.Date
+	for rows.Next() {
+		var day pgtype.Date
+		if err := rows.Scan(&day); err != nil {
+			return nil, err
+		}
+		items = append(items, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserRecipeIngredients = `-- name: ListUserRecipeIngredients :many
+SELECT ri.ingredient_id, ri.recipe_id, ri.food_id, f.food_name, ri.total_grams,
+       f.calories_100, f.protein_100, f.carbs_100, f.fats_100
+FROM recipe_ingredients ri
+JOIN recipes r ON r.recipe_id = ri.recipe_id
+JOIN food f ON f.food_id = ri.food_id
+WHERE r.user_id = $1
+ORDER BY ri.recipe_id, ri.ingredient_id
+`
+
+type ListUserRecipeIngredientsRow struct {
+	IngredientID int64   `json:"ingredient_id"`
+	RecipeID     int64   `json:"recipe_id"`
+	FoodID       int64   `json:"food_id"`
+	FoodName     string  `json:"food_name"`
+	TotalGrams   float64 `json:"total_grams"`
+	Calories100  float64 `json:"calories_100"`
+	Protein100   float64 `json:"protein_100"`
+	Carbs100     float64 `json:"carbs_100"`
+	Fats100      float64 `json:"fats_100"`
+}
+
+func (q *Queries) ListUserRecipeIngredients(ctx context.Context, userID int64) ([]ListUserRecipeIngredientsRow, error) {
+	rows, err := q.db.Query(ctx, listUserRecipeIngredients, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserRecipeIngredientsRow
+	for rows.Next() {
+		var i ListUserRecipeIngredientsRow
+		if err := rows.Scan(
+			&i.IngredientID,
+			&i.RecipeID,
+			&i.FoodID,
+			&i.FoodName,
+			&i.TotalGrams,
+			&i.Calories100,
+			&i.Protein100,
+			&i.Carbs100,
+			&i.Fats100,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkoutSessions = `-- name: ListWorkoutSessions :many
+SELECT session_id, user_id, title, notes, started_at, ended_at, bodyweight, session_rpe, created_at, last_updated
+FROM workout_sessions
+WHERE user_id = $1
+ORDER BY started_at DESC
+LIMIT $2
+`
+
+type ListWorkoutSessionsParams struct {
+	UserID int64 `json:"user_id"`
+	Limit  int32 `json:"limit"`
+}
+
+func (q *Queries) ListWorkoutSessions(ctx context.Context, arg ListWorkoutSessionsParams) ([]WorkoutSession, error) {
+	rows, err := q.db.Query(ctx, listWorkoutSessions, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkoutSession
+	for rows.Next() {
+		var i WorkoutSession
+		if err := rows.Scan(
+			&i.SessionID,
+			&i.UserID,
+			&i.Title,
+			&i.Notes,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.Bodyweight,
+			&i.SessionRpe,
+			&i.CreatedAt,
+			&i.LastUpdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const logExercise = `-- name: LogExercise :one
+INSERT INTO exercise_entries(user_id,exercise_name,weight,sets,reps,rpe,notes,session_id,exercise_id,estimated_1rm,prescription_id)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+RETURNING entry_id, user_id, created_at, last_updated, exercise_name, weight, sets, reps, rpe, notes, session_id, exercise_id, estimated_1rm, prescription_id
+`
+
+type LogExerciseParams struct {
+	UserID         int64          `json:"user_id"`
+	ExerciseName   string         `json:"exercise_name"`
+	Weight         pgtype.Numeric `json:"weight"`
+	Sets           int32          `json:"sets"`
+	Reps           int32          `json:"reps"`
+	Rpe            int32          `json:"rpe"`
+	Notes          pgtype.Text    `json:"notes"`
+	SessionID      pgtype.Int8    `json:"session_id"`
+	ExerciseID     pgtype.Int8    `json:"exercise_id"`
+	Estimated1rm   pgtype.Numeric `json:"estimated_1rm"`
+	PrescriptionID pgtype.Int8    `json:"prescription_id"`
+}
+
+func (q *Queries) LogExercise(ctx context.Context, arg LogExerciseParams) (ExerciseEntry, error) {
+	row := q.db.QueryRow(ctx, logExercise,
+		arg.UserID,
+		arg.ExerciseName,
+		arg.Weight,
+		arg.Sets,
+		arg.Reps,
+		arg.Rpe,
+		arg.Notes,
+		arg.SessionID,
+		arg.ExerciseID,
+		arg.Estimated1rm,
+		arg.PrescriptionID,
+	)
+	var i ExerciseEntry
+	err := row.Scan(
+		&i.EntryID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.LastUpdated,
+		&i.ExerciseName,
+		&i.Weight,
+		&i.Sets,
+		&i.Reps,
+		&i.Rpe,
+		&i.Notes,
+		&i.SessionID,
+		&i.ExerciseID,
+		&i.Estimated1rm,
+		&i.PrescriptionID,
+	)
+	return i, err
+}
+
+const logFoodItem = `-- name: LogFoodItem :one
+INSERT INTO food_entries (
+    user_id,
+    food_id,
+    recipe_id,
+    cache_id,
+    calories,
+    total_grams,
+    protein,
+    carbs,
+    fats,
+    plan_item_id
+) VALUES (
+    $1,  -- user_id (BIGINT, NOT NULL)
+    $2,  -- food_id (BIGINT, can be NULL)
+    $3,  -- recipe_id (BIGINT, can be NULL) 
+    $4,  -- cache_id (BIGINT, can be NULL)
+    $5,  -- calories (DOUBLE PRECISION, NOT NULL)
+    $6,  -- total_grams (DOUBLE PRECISION, NOT NULL)
+    $7,  -- protein (DOUBLE PRECISION, NOT NULL)
+    $8,  -- carbs (DOUBLE PRECISION, NOT NULL)
+    $9,  -- fats (DOUBLE PRECISION, NOT NULL)
+    $10  -- plan_item_id (BIGINT, can be NULL)
+)
+RETURNING nutrition_id, user_id, food_id, recipe_id, cache_id, calories, total_grams, protein, carbs, fats, created_at, last_updated, plan_item_id
+`
+
+type LogFoodItemParams struct {
+	UserID     int64       `json:"user_id"`
+	FoodID     pgtype.Int8 `json:"food_id"`
+	RecipeID   pgtype.Int8 `json:"recipe_id"`
+	CacheID    pgtype.Int8 `json:"cache_id"`
+	Calories   float64     `json:"calories"`
+	TotalGrams float64     `json:"total_grams"`
+	Protein    float64     `json:"protein"`
+	Carbs      float64     `json:"carbs"`
+	Fats       float64     `json:"fats"`
+	PlanItemID pgtype.Int8 `json:"plan_item_id"`
+}
+
+func (q *Queries) LogFoodItem(ctx context.Context, arg LogFoodItemParams) (FoodEntry, error) {
+	row := q.db.QueryRow(ctx, logFoodItem,
+		arg.UserID,
+		arg.FoodID,
+		arg.RecipeID,
+		arg.CacheID,
+		arg.Calories,
+		arg.TotalGrams,
+		arg.Protein,
+		arg.Carbs,
+		arg.Fats,
+		arg.PlanItemID,
+	)
+	var i FoodEntry
+	err := row.Scan(
+		&i.NutritionID,
+		&i.UserID,
+		&i.FoodID,
+		&i.RecipeID,
+		&i.CacheID,
+		&i.Calories,
+		&i.TotalGrams,
+		&i.Protein,
+		&i.Carbs,
+		&i.Fats,
+		&i.CreatedAt,
+		&i.LastUpdated,
+		&i.PlanItemID,
+	)
+	return i, err
+}
+
+const markRefreshTokenUsed = `-- name: MarkRefreshTokenUsed :execrows
+UPDATE refresh_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE token_id = $1 AND used_at IS NULL AND revoked_at IS NULL
+`
+
+// Affects no rows when the token was already used or revoked, which is how
+// a concurrent replay is detected
+func (q *Queries) MarkRefreshTokenUsed(ctx context.Context, tokenID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, markRefreshTokenUsed, tokenID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const muscleSetStats = `-- name: MuscleSetStats :many
+WITH entry_sets AS (
+    SELECT e.created_at,
+           e.exercise_id,
+           COALESCE(s.set_count, e.sets) AS set_count
+    FROM exercise_entries e
+    LEFT JOIN LATERAL (
+        SELECT COUNT(*) AS set_count
+        FROM exercise_sets es
+        WHERE es.entry_id = e.entry_id AND es.completed AND es.set_type <> 'warmup'
+        HAVING COUNT(*) > 0
+    ) s ON TRUE
+    WHERE e.user_id = $1
+      AND e.created_at >= $2
+      AND e.created_at < $3
+)
+SELECT date_trunc($4::text, es.created_at)::timestamp AS bucket_start,
+       m.muscle::text AS muscle,
+       SUM(es.set_count * m.share)::float AS sets
+FROM entry_sets es
+JOIN exercises x ON x.exercise_id = es.exercise_id
+CROSS JOIN LATERAL (
+    SELECT unnest(x.primary_muscles) AS muscle, 1.0 AS share
+    UNION ALL
+    SELECT unnest(x.secondary_muscles), 0.5
+) m
+GROUP BY bucket_start, m.muscle
+ORDER BY bucket_start, sets DESC, muscle
+`
+
+type MuscleSetStatsParams struct {
+	UserID   int64            `json:"user_id"`
+	DateFrom pgtype.Timestamp `json:"date_from"`
+	DateTo   pgtype.Timestamp `json:"date_to"`
+	Bucket   string           `json:"bucket"`
+}
+
+type MuscleSetStatsRow struct {
+	BucketStart pgtype.Timestamp `json:"bucket_start"`
+	Muscle      string           `json:"muscle"`
+	Sets        float64          `json:"sets"`
+}
+
+// Secondary muscles count as half a set. Entries that are not linked to the
+// exercise catalog have no muscle groups and are left out.
+func (q *Queries) MuscleSetStats(ctx context.Context, arg MuscleSetStatsParams) ([]MuscleSetStatsRow, error) {
+	rows, err := q.db.Query(ctx, muscleSetStats,
+		arg.UserID,
+		arg.DateFrom,
+		arg.DateTo,
+		arg.Bucket,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MuscleSetStatsRow
+	for rows.Next() {
+		var i MuscleSetStatsRow
+		if err := rows.Scan(&i.BucketStart, &i.Muscle, &i.Sets); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordLoginFailure = `-- name: RecordLoginFailure :one
+INSERT INTO login_throttle(throttle_key,failures,last_failure)
+VALUES($1,1,$2)
+ON CONFLICT (throttle_key) DO UPDATE
+SET failures = CASE WHEN login_throttle.last_failure < $3 THEN 1
+                    ELSE login_throttle.failures + 1 END,
+    last_failure = EXCLUDED.last_failure
+RETURNING throttle_key, failures, last_failure
+`
+
+type RecordLoginFailureParams struct {
+	ThrottleKey string           `json:"throttle_key"`
+	FailedAt    pgtype.Timestamp `json:"failed_at"`
+	WindowStart pgtype.Timestamp `json:"window_start"`
+}
+
+// Counts a failure, starting over when the previous one is older than
+// window_start
+func (q *Queries) RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginThrottle, error) {
+	row := q.db.QueryRow(ctx, recordLoginFailure, arg.ThrottleKey, arg.FailedAt, arg.WindowStart)
+	var i LoginThrottle
+	err := row.Scan(&i.ThrottleKey, &i.Failures, &i.LastFailure)
+	return i, err
+}
+
+const releaseLoginFailure = `-- name: ReleaseLoginFailure :exec
+UPDATE login_throttle
+SET failures = failures - 1
+WHERE throttle_key = $1 AND failures > 0
+`
+
+// Takes back a failure reserved for a login that succeeded or never ran
+func (q *Queries) ReleaseLoginFailure(ctx context.Context, throttleKey string) error {
+	_, err := q.db.Exec(ctx, releaseLoginFailure, throttleKey)
+	return err
+}
+
+const resetLoginThrottle = `-- name: ResetLoginThrottle :exec
+DELETE FROM login_throttle
+WHERE throttle_key = $1
+`
+
+func (q *Queries) ResetLoginThrottle(ctx context.Context, throttleKey string) error {
+	_, err := q.db.Exec(ctx, resetLoginThrottle, throttleKey)
+	return err
+}
+
+const resolveExercise = `-- name: ResolveExercise :one
+SELECT exercise_id, user_id, name, aliases, primary_muscles, secondary_muscles, equipment, movement_pattern, created_at, last_updated
+FROM exercises
+WHERE (user_id IS NULL OR user_id = $1)
+  AND (LOWER(name) = LOWER($2::text) OR LOWER($2::text) = ANY(aliases))
+ORDER BY user_id IS NULL, exercise_id
+LIMIT 1
+`
+
+type ResolveExerciseParams struct {
+	UserID pgtype.Int8 `json:"user_id"`
+	Name   string      `json:"name"`
+}
+
+// Matches a canonical name or alias case-insensitively, preferring the
+// user's own custom exercise over the built-in one
+func (q *Queries) ResolveExercise(ctx context.Context, arg ResolveExerciseParams) (Exercise, error) {
+	row := q.db.QueryRow(ctx, resolveExercise, arg.UserID, arg.Name)
+	var i Exercise
+	err := row.Scan(
+		&i.ExerciseID,
+		&i.UserID,
+		&i.Name,
+		&i.Aliases,
+		&i.PrimaryMuscles,
+		&i.SecondaryMuscles,
+		&i.Equipment,
+		&i.MovementPattern,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const respondToInvite = `-- name: RespondToInvite :one
+UPDATE trainer_clients
+SET status = $1,
+    responded_at = CURRENT_TIMESTAMP,
+    last_updated = CURRENT_TIMESTAMP
+WHERE relationship_id = $2
+  AND client_id = $3
+  AND status = 'pending'
+RETURNING relationship_id, trainer_id, client_id, status, invited_at, responded_at, created_at, last_updated
+`
+
+type RespondToInviteParams struct {
+	Status         string `json:"status"`
+	RelationshipID int64  `json:"relationship_id"`
+	ClientID       int64  `json:"client_id"`
+}
+
+func (q *Queries) RespondToInvite(ctx context.Context, arg RespondToInviteParams) (TrainerClient, error) {
+	row := q.db.QueryRow(ctx, respondToInvite, arg.Status, arg.RelationshipID, arg.ClientID)
+	var i TrainerClient
+	err := row.Scan(
+		&i.RelationshipID,
+		&i.TrainerID,
+		&i.ClientID,
+		&i.Status,
+		&i.InvitedAt,
+		&i.RespondedAt,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const revokeAccessToken = `-- name: RevokeAccessToken :exec
+INSERT INTO revoked_tokens(jti,user_id,expires_at)
+VALUES($1,$2,$3)
+ON CONFLICT (jti) DO NOTHING
+`
+
+type RevokeAccessTokenParams struct {
+	Jti       string           `json:"jti"`
+	UserID    pgtype.Int8      `json:"user_id"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+}
+
+func (q *Queries) RevokeAccessToken(ctx context.Context, arg RevokeAccessTokenParams) error {
+	_, err := q.db.Exec(ctx, revokeAccessToken, arg.Jti, arg.UserID, arg.ExpiresAt)
+	return err
+}
+
+const revokeRefreshTokenFamily = `-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE family_id = $1 AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	_, err := q.db.Exec(ctx, revokeRefreshTokenFamily, familyID)
+	return err
+}
+
+const revokeUserRefreshTokens = `-- name: RevokeUserRefreshTokens :exec
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeUserRefreshTokens(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, revokeUserRefreshTokens, userID)
+	return err
+}
+
+const setUserRoles = `-- name: SetUserRoles :one
+UPDATE users_profile
+SET is_trainer = $2,
+    is_vip = $3,
+    is_admin = $4,
+    last_updated = CURRENT_TIMESTAMP
+WHERE user_id = $1
+RETURNING user_id, date_of_birth, email, height, weight, sex, body_fat, preferred_units, is_trainer, is_vip, is_admin, last_updated
+`
+
+type SetUserRolesParams struct {
+	UserID    int64 `json:"user_id"`
+	IsTrainer bool  `json:"is_trainer"`
+	IsVip     bool  `json:"is_vip"`
+	IsAdmin   bool  `json:"is_admin"`
+}
+
+func (q *Queries) SetUserRoles(ctx context.Context, arg SetUserRolesParams) (UsersProfile, error) {
+	row := q.db.QueryRow(ctx, setUserRoles,
+		arg.UserID,
+		arg.IsTrainer,
+		arg.IsVip,
+		arg.IsAdmin,
+	)
+	var i UsersProfile
+	err := row.Scan(
+		&i.UserID,
+		&i.DateOfBirth,
+		&i.Email,
+		&i.Height,
+		&i.Weight,
+		&i.Sex,
+		&i.BodyFat,
+		&i.PreferredUnits,
+		&i.IsTrainer,
+		&i.IsVip,
+		&i.IsAdmin,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const touchFoodCacheItem = `-- name: TouchFoodCacheItem :exec
+UPDATE food_Cache
+SET use_count = use_count + 1,
+    last_used = CURRENT_TIMESTAMP
+WHERE food_id = $1
+`
+
+func (q *Queries) TouchFoodCacheItem(ctx context.Context, foodID int64) error {
+	_, err := q.db.Exec(ctx, touchFoodCacheItem, foodID)
+	return err
+}
+
+const trainingVolumeStats = `-- name: TrainingVolumeStats :many
+WITH entry_work AS (
+    SELECT e.created_at,
+           e.rpe,
+           e.weight,
+           COALESCE(s.set_count, e.sets) AS set_count,
+           COALESCE(s.tonnage, e.weight * e.sets * e.reps) AS tonnage,
+           best.e1rm AS best_e1rm
+    FROM exercise_entries e
+    LEFT JOIN LATERAL (
+        -- Entries with set details count their completed working sets,
+        -- older entries fall back to the sets x reps summary
+        SELECT COUNT(*) AS set_count, SUM(es.weight * es.reps) AS tonnage
+        FROM exercise_sets es
+        WHERE es.entry_id = e.entry_id AND es.completed AND es.set_type <> 'warmup'
+        HAVING COUNT(*) > 0
+    ) s ON TRUE
+    LEFT JOIN LATERAL (
+        SELECT MAX(prior.estimated_1rm) AS e1rm
+        FROM exercise_entries prior
+        WHERE prior.user_id = e.user_id
+          AND prior.exercise_name = e.exercise_name
+          AND prior.created_at <= e.created_at
+    ) best ON TRUE
+    WHERE e.user_id = $1
+      AND e.created_at >= $2
+      AND e.created_at < $3
+)
+SELECT date_trunc($4::text, created_at)::timestamp AS bucket_start,
+       COUNT(*) AS entries,
+       COALESCE(SUM(set_count), 0)::bigint AS total_sets,
+       COALESCE(SUM(tonnage), 0)::float AS tonnage,
+       COALESCE(AVG(NULLIF(rpe, 0)), 0)::float AS average_rpe,
+       COALESCE(AVG(weight / NULLIF(best_e1rm, 0)), 0)::float AS relative_intensity
+FROM entry_work
+GROUP BY bucket_start
+ORDER BY bucket_start
+`
+
+type TrainingVolumeStatsParams struct {
+	UserID   int64            `json:"user_id"`
+	DateFrom pgtype.Timestamp `json:"date_from"`
+	DateTo   pgtype.Timestamp `json:"date_to"`
+	Bucket   string           `json:"bucket"`
+}
+
+type TrainingVolumeStatsRow struct {
+	BucketStart       pgtype.Timestamp `json:"bucket_start"`
+	Entries           int64            `json:"entries"`
+	TotalSets         int64            `json:"total_sets"`
+	Tonnage           float64          `json:"tonnage"`
+	AverageRpe        float64          `json:"average_rpe"`
+	RelativeIntensity float64          `json:"relative_intensity"`
+}
+
+func (q *Queries) TrainingVolumeStats(ctx context.Context, arg TrainingVolumeStatsParams) ([]TrainingVolumeStatsRow, error) {
+	rows, err := q.db.Query(ctx, trainingVolumeStats,
+		arg.UserID,
+		arg.DateFrom,
+		arg.DateTo,
+		arg.Bucket,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TrainingVolumeStatsRow
+	for rows.Next() {
+		var i TrainingVolumeStatsRow
+		if err := rows.Scan(
+			&i.BucketStart,
+			&i.Entries,
+			&i.TotalSets,
+			&i.Tonnage,
+			&i.AverageRpe,
+			&i.RelativeIntensity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateBodyMeasurement = `-- name: UpdateBodyMeasurement :one
+UPDATE body_measurements
+SET measured_at = $3,
+    bodyweight = $4,
+    body_fat = $5,
+    waist = $6,
+    chest = $7,
+    arms = $8,
+    thighs = $9,
+    notes = $10,
+    last_updated = CURRENT_TIMESTAMP
+WHERE measurement_id = $1 AND user_id = $2
+RETURNING measurement_id, user_id, measured_at, bodyweight, body_fat, waist, chest, arms, thighs, notes, created_at, last_updated
+`
+
+type UpdateBodyMeasurementParams struct {
+	MeasurementID int64            `json:"measurement_id"`
+	UserID        int64            `json:"user_id"`
+	MeasuredAt    pgtype.Timestamp `json:"measured_at"`
+	Bodyweight    pgtype.Numeric   `json:"bodyweight"`
+	BodyFat       pgtype.Numeric   `json:"body_fat"`
+	Waist         pgtype.Numeric   `json:"waist"`
+	Chest         pgtype.Numeric   `json:"chest"`
+	Arms          pgtype.Numeric   `json:"arms"`
+	Thighs        pgtype.Numeric   `json:"thighs"`
+	Notes         pgtype.Text      `json:"notes"`
 }
 
-func (q *Queries) CreateFoodCacheItem(ctx context.Context, arg CreateFoodCacheItemParams) (FoodCache, error) {
-	row := q.db.QueryRow(ctx, createFoodCacheItem,
+func (q *Queries) UpdateBodyMeasurement(ctx context.Context, arg UpdateBodyMeasurementParams) (BodyMeasurement, error) {
+	row := q.db.QueryRow(ctx, updateBodyMeasurement,
+		arg.MeasurementID,
 		arg.UserID,
-		arg.FoodName,
-		arg.Calories100,
-		arg.Protein100,
-		arg.Carbs100,
-		arg.Fats100,
+		arg.MeasuredAt,
+		arg.Bodyweight,
+		arg.BodyFat,
+		arg.Waist,
+		arg.Chest,
+		arg.Arms,
+		arg.Thighs,
+		arg.Notes,
 	)
-	var i FoodCache
+	var i BodyMeasurement
 	err := row.Scan(
-		&i.FoodID,
+		&i.MeasurementID,
 		&i.UserID,
-		&i.FoodName,
-		&i.Calories100,
-		&i.Protein100,
-		&i.Carbs100,
-		&i.Fats100,
+		&i.MeasuredAt,
+		&i.Bodyweight,
+		&i.BodyFat,
+		&i.Waist,
+		&i.Chest,
+		&i.Arms,
+		&i.Thighs,
+		&i.Notes,
 		&i.CreatedAt,
 		&i.LastUpdated,
 	)
 	return i, err
 }
 
-const createFoodItem = `-- name: CreateFoodItem :one
-INSERT INTO food(user_id,food_name,calories_100,protein_100,carbs_100,fats_100)
-VALUES($1,$2,$3,$4,$5,$6)
-RETURNING user_id,food_name,calories_100,protein_100,carbs_100,fats_100
+const updateExerciseEntry = `-- name: UpdateExerciseEntry :one
+UPDATE exercise_entries
+SET exercise_name = $3,
+    weight = $4,
+    sets = $5,
+    reps = $6,
+    rpe = $7,
+    notes = $8,
+    exercise_id = $9,
+    estimated_1rm = $10,
+    last_updated = CURRENT_TIMESTAMP
+WHERE entry_id = $1 AND user_id = $2
+RETURNING entry_id, user_id, created_at, last_updated, exercise_name, weight, sets, reps, rpe, notes, session_id, exercise_id, estimated_1rm, prescription_id
 `
 
-type CreateFoodItemParams struct {
+type UpdateExerciseEntryParams struct {
+	EntryID      int64          `json:"entry_id"`
+	UserID       int64          `json:"user_id"`
+	ExerciseName string         `json:"exercise_name"`
+	Weight       pgtype.Numeric `json:"weight"`
+	Sets         int32          `json:"sets"`
+	Reps         int32          `json:"reps"`
+	Rpe          int32          `json:"rpe"`
+	Notes        pgtype.Text    `json:"notes"`
+	ExerciseID   pgtype.Int8    `json:"exercise_id"`
+	Estimated1rm pgtype.Numeric `json:"estimated_1rm"`
+}
+
+func (q *Queries) UpdateExerciseEntry(ctx context.Context, arg UpdateExerciseEntryParams) (ExerciseEntry, error) {
+	row := q.db.QueryRow(ctx, updateExerciseEntry,
+		arg.EntryID,
+		arg.UserID,
+		arg.ExerciseName,
+		arg.Weight,
+		arg.Sets,
+		arg.Reps,
+		arg.Rpe,
+		arg.Notes,
+		arg.ExerciseID,
+		arg.Estimated1rm,
+	)
+	var i ExerciseEntry
+	err := row.Scan(
+		&i.EntryID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.LastUpdated,
+		&i.ExerciseName,
+		&i.Weight,
+		&i.Sets,
+		&i.Reps,
+		&i.Rpe,
+		&i.Notes,
+		&i.SessionID,
+		&i.ExerciseID,
+		&i.Estimated1rm,
+		&i.PrescriptionID,
+	)
+	return i, err
+}
+
+const updateFoodEntry = `-- name: UpdateFoodEntry :one
+UPDATE food_entries
+SET total_grams = $3,
+    calories = $4,
+    protein = $5,
+    carbs = $6,
+    fats = $7,
+    last_updated = CURRENT_TIMESTAMP
+WHERE nutrition_id = $1 AND user_id = $2
+RETURNING nutrition_id, user_id, food_id, recipe_id, cache_id, calories, total_grams, protein, carbs, fats, created_at, last_updated, plan_item_id
+`
+
+type UpdateFoodEntryParams struct {
+	NutritionID int64   `json:"nutrition_id"`
 	UserID      int64   `json:"user_id"`
-	FoodName    string  `json:"food_name"`
-	Calories100 float64 `json:"calories_100"`
-	Protein100  float64 `json:"protein_100"`
-	Carbs100    float64 `json:"carbs_100"`
-	Fats100     float64 `json:"fats_100"`
+	TotalGrams  float64 `json:"total_grams"`
+	Calories    float64 `json:"calories"`
+	Protein     float64 `json:"protein"`
+	Carbs       float64 `json:"carbs"`
+	Fats        float64 `json:"fats"`
+}
+
+func (q *Queries) UpdateFoodEntry(ctx context.Context, arg UpdateFoodEntryParams) (FoodEntry, error) {
+	row := q.db.QueryRow(ctx, updateFoodEntry,
+		arg.NutritionID,
+		arg.UserID,
+		arg.TotalGrams,
+		arg.Calories,
+		arg.Protein,
+		arg.Carbs,
+		arg.Fats,
+	)
+	var i FoodEntry
+	err := row.Scan(
+		&i.NutritionID,
+		&i.UserID,
+		&i.FoodID,
+		&i.RecipeID,
+		&i.CacheID,
+		&i.Calories,
+		&i.TotalGrams,
+		&i.Protein,
+		&i.Carbs,
+		&i.Fats,
+		&i.CreatedAt,
+		&i.LastUpdated,
+		&i.PlanItemID,
+	)
+	return i, err
 }
 
-type CreateFoodItemRow struct {
+const updateFoodItem = `-- name: UpdateFoodItem :one
+UPDATE food
+SET food_name = $3,
+    calories_100 = $4,
+    protein_100 = $5,
+    carbs_100 = $6,
+    fats_100 = $7,
+    last_updated = CURRENT_TIMESTAMP
+WHERE food_id = $1 AND user_id = $2
+RETURNING food_id, user_id, food_name, calories_100, protein_100, carbs_100, fats_100, created_at, last_updated
+`
+
+type UpdateFoodItemParams struct {
+	FoodID      int64   `json:"food_id"`
 	UserID      int64   `json:"user_id"`
 	FoodName    string  `json:"food_name"`
 	Calories100 float64 `json:"calories_100"`
@@ -74,8 +3732,9 @@ type CreateFoodItemRow struct {
 	Fats100     float64 `json:"fats_100"`
 }
 
-func (q *Queries) CreateFoodItem(ctx context.Context, arg CreateFoodItemParams) (CreateFoodItemRow, error) {
-	row := q.db.QueryRow(ctx, createFoodItem,
+func (q *Queries) UpdateFoodItem(ctx context.Context, arg UpdateFoodItemParams) (Food, error) {
+	row := q.db.QueryRow(ctx, updateFoodItem,
+		arg.FoodID,
 		arg.UserID,
 		arg.FoodName,
 		arg.Calories100,
@@ -83,189 +3742,294 @@ func (q *Queries) CreateFoodItem(ctx context.Context, arg CreateFoodItemParams)
 		arg.Carbs100,
 		arg.Fats100,
 	)
-	var i CreateFoodItemRow
+	var i Food
 	err := row.Scan(
+		&i.FoodID,
 		&i.UserID,
 		&i.FoodName,
 		&i.Calories100,
 		&i.Protein100,
 		&i.Carbs100,
 		&i.Fats100,
+		&i.CreatedAt,
+		&i.LastUpdated,
 	)
 	return i, err
 }
 
-const createUser = `-- name: CreateUser :one
-INSERT INTO users (username, hashed_password)
-VALUES ($1, $2)
-RETURNING user_id, username
+const updateRecipe = `-- name: UpdateRecipe :one
+UPDATE recipes
+SET recipe_name = $3,
+    instructions = $4,
+    servings = $5,
+    last_updated = CURRENT_TIMESTAMP
+WHERE recipe_id = $1 AND user_id = $2
+RETURNING recipe_id, user_id, recipe_name, instructions, servings, created_at, last_updated
 `
 
-type CreateUserParams struct {
-	Username       string `json:"username"`
-	HashedPassword string `json:"hashed_password"`
-}
-
-type CreateUserRow struct {
-	UserID   int64  `json:"user_id"`
-	Username string `json:"username"`
+type UpdateRecipeParams struct {
+	RecipeID     int64       `json:"recipe_id"`
+	UserID       int64       `json:"user_id"`
+	RecipeName   string      `json:"recipe_name"`
+	Instructions pgtype.Text `json:"instructions"`
+	Servings     int32       `json:"servings"`
 }
 
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
-	row := q.db.QueryRow(ctx, createUser, arg.Username, arg.HashedPassword)
-	var i CreateUserRow
-	err := row.Scan(&i.UserID, &i.Username)
+func (q *Queries) UpdateRecipe(ctx context.Context, arg UpdateRecipeParams) (Recipe, error) {
+	row := q.db.QueryRow(ctx, updateRecipe,
+		arg.RecipeID,
+		arg.UserID,
+		arg.RecipeName,
+		arg.Instructions,
+		arg.Servings,
+	)
+	var i Recipe
+	err := row.Scan(
+		&i.RecipeID,
+		&i.UserID,
+		&i.RecipeName,
+		&i.Instructions,
+		&i.Servings,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
 	return i, err
 }
 
-const getUserByID = `-- name: GetUserByID :one
-SELECT user_id,username,hashed_password
-FROM users
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users
+SET hashed_password = $2
 WHERE user_id = $1
 `
 
-type GetUserByIDRow struct {
+type UpdateUserPasswordParams struct {
 	UserID         int64  `json:"user_id"`
-	Username       string `json:"username"`
 	HashedPassword string `json:"hashed_password"`
 }
 
-func (q *Queries) GetUserByID(ctx context.Context, userID int64) (GetUserByIDRow, error) {
-	row := q.db.QueryRow(ctx, getUserByID, userID)
-	var i GetUserByIDRow
-	err := row.Scan(&i.UserID, &i.Username, &i.HashedPassword)
-	return i, err
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.Exec(ctx, updateUserPassword, arg.UserID, arg.HashedPassword)
+	return err
 }
 
-const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT user_id,username, hashed_password
-FROM users
-WHERE username = $1
+const upsertFoodCacheItem = `-- name: UpsertFoodCacheItem :one
+INSERT INTO food_Cache(user_id,food_name,normalized_name,calories_100,protein_100,carbs_100,fats_100)
+VALUES($1,$2,$3,$4,$5,$6,$7)
+ON CONFLICT (user_id, normalized_name, calories_100, protein_100, carbs_100, fats_100)
+DO UPDATE SET food_name = EXCLUDED.food_name,
+              use_count = food_Cache.use_count + 1,
+              last_used = CURRENT_TIMESTAMP,
+              last_updated = CURRENT_TIMESTAMP
+RETURNING food_id, user_id, food_name, normalized_name, calories_100, protein_100, carbs_100, fats_100, use_count, last_used, created_at, last_updated
 `
 
-type GetUserByUsernameRow struct {
-	UserID         int64  `json:"user_id"`
-	Username       string `json:"username"`
-	HashedPassword string `json:"hashed_password"`
+type UpsertFoodCacheItemParams struct {
+	UserID         int64   `json:"user_id"`
+	FoodName       string  `json:"food_name"`
+	NormalizedName string  `json:"normalized_name"`
+	Calories100    float64 `json:"calories_100"`
+	Protein100     float64 `json:"protein_100"`
+	Carbs100       float64 `json:"carbs_100"`
+	Fats100        float64 `json:"fats_100"`
 }
 
-func (q *Queries) GetUserByUsername(ctx context.Context, username string) (GetUserByUsernameRow, error) {
-	row := q.db.QueryRow(ctx, getUserByUsername, username)
-	var i GetUserByUsernameRow
-	err := row.Scan(&i.UserID, &i.Username, &i.HashedPassword)
+func (q *Queries) UpsertFoodCacheItem(ctx context.Context, arg UpsertFoodCacheItemParams) (FoodCache, error) {
+	row := q.db.QueryRow(ctx, upsertFoodCacheItem,
+		arg.UserID,
+		arg.FoodName,
+		arg.NormalizedName,
+		arg.Calories100,
+		arg.Protein100,
+		arg.Carbs100,
+		arg.Fats100,
+	)
+	var i FoodCache
+	err := row.Scan(
+		&i.FoodID,
+		&i.UserID,
+		&i.FoodName,
+		&i.NormalizedName,
+		&i.Calories100,
+		&i.Protein100,
+		&i.Carbs100,
+		&i.Fats100,
+		&i.UseCount,
+		&i.LastUsed,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
 	return i, err
 }
 
-const logExercise = `-- name: LogExercise :one
-INSERT INTO exercise_entries(user_id,exercise_name,weight,sets,reps,rpe,notes)
+const upsertNutritionTarget = `-- name: UpsertNutritionTarget :one
+INSERT INTO nutrition_targets(user_id,day_type,effective_from,calories,protein,carbs,fats)
 VALUES($1,$2,$3,$4,$5,$6,$7)
-RETURNING entry_id, user_id, created_at, last_updated, exercise_name, weight, sets, reps, rpe, notes
+ON CONFLICT (user_id, day_type, effective_from) DO UPDATE
+SET calories = EXCLUDED.calories,
+    protein = EXCLUDED.protein,
+    carbs = EXCLUDED.carbs,
+    fats = EXCLUDED.fats,
+    last_updated = CURRENT_TIMESTAMP
+RETURNING target_id, user_id, day_type, effective_from, calories, protein, carbs, fats, created_at, last_updated, plan_item_id
 `
 
-type LogExerciseParams struct {
-	UserID       int64          `json:"user_id"`
-	ExerciseName string         `json:"exercise_name"`
-	Weight       pgtype.Numeric `json:"weight"`
-	Sets         int32          `json:"sets"`
-	Reps         int32          `json:"reps"`
-	Rpe          int32          `json:"rpe"`
-	Notes        pgtype.Text    `json:"notes"`
+type UpsertNutritionTargetParams struct {
+	UserID        int64       `json:"user_id"`
+	DayType       string      `json:"day_type"`
+	EffectiveFrom pgtype.Date `json:"effective_from"`
+	Calories      float64     `json:"calories"`
+	Protein       float64     `json:"protein"`
+	Carbs         float64     `json:"carbs"`
+	Fats          float64     `json:"fats"`
 }
 
-func (q *Queries) LogExercise(ctx context.Context, arg LogExerciseParams) (ExerciseEntry, error) {
-	row := q.db.QueryRow(ctx, logExercise,
+func (q *Queries) UpsertNutritionTarget(ctx context.Context, arg UpsertNutritionTargetParams) (NutritionTarget, error) {
+	row := q.db.QueryRow(ctx, upsertNutritionTarget,
+		arg.UserID,
+		arg.DayType,
+		arg.EffectiveFrom,
+		arg.Calories,
+		arg.Protein,
+		arg.Carbs,
+		arg.Fats,
+	)
+	var i NutritionTarget
+	err := row.Scan(
+		&i.TargetID,
+		&i.UserID,
+		&i.DayType,
+		&i.EffectiveFrom,
+		&i.Calories,
+		&i.Protein,
+		&i.Carbs,
+		&i.Fats,
+		&i.CreatedAt,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const upsertPersonalRecord = `-- name: UpsertPersonalRecord :one
+INSERT INTO personal_records(user_id,exercise_name,record_type,value,weight,reps,entry_id,achieved_at)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8)
+ON CONFLICT (user_id, exercise_name, record_type) DO UPDATE
+SET value = EXCLUDED.value,
+    weight = EXCLUDED.weight,
+    reps = EXCLUDED.reps,
+    entry_id = EXCLUDED.entry_id,
+    achieved_at = EXCLUDED.achieved_at,
+    last_updated = CURRENT_TIMESTAMP
+WHERE personal_records.value < EXCLUDED.value
+RETURNING record_id, user_id, exercise_name, record_type, value, weight, reps, entry_id, achieved_at, created_at, last_updated
+`
+
+type UpsertPersonalRecordParams struct {
+	UserID       int64            `json:"user_id"`
+	ExerciseName string           `json:"exercise_name"`
+	RecordType   string           `json:"record_type"`
+	Value        pgtype.Numeric   `json:"value"`
+	Weight       pgtype.Numeric   `json:"weight"`
+	Reps         pgtype.Int4      `json:"reps"`
+	EntryID      pgtype.Int8      `json:"entry_id"`
+	AchievedAt   pgtype.Timestamp `json:"achieved_at"`
+}
+
+// Returns no row when the existing record is at least as good
+func (q *Queries) UpsertPersonalRecord(ctx context.Context, arg UpsertPersonalRecordParams) (PersonalRecord, error) {
+	row := q.db.QueryRow(ctx, upsertPersonalRecord,
 		arg.UserID,
 		arg.ExerciseName,
+		arg.RecordType,
+		arg.Value,
 		arg.Weight,
-		arg.Sets,
 		arg.Reps,
-		arg.Rpe,
-		arg.Notes,
+		arg.EntryID,
+		arg.AchievedAt,
 	)
-	var i ExerciseEntry
+	var i PersonalRecord
 	err := row.Scan(
-		&i.EntryID,
+		&i.RecordID,
 		&i.UserID,
-		&i.CreatedAt,
-		&i.LastUpdated,
 		&i.ExerciseName,
+		&i.RecordType,
+		&i.Value,
 		&i.Weight,
-		&i.Sets,
 		&i.Reps,
-		&i.Rpe,
-		&i.Notes,
+		&i.EntryID,
+		&i.AchievedAt,
+		&i.CreatedAt,
+		&i.LastUpdated,
 	)
 	return i, err
 }
 
-const logFoodItem = `-- name: LogFoodItem :one
-INSERT INTO food_entries (
-    user_id,
-    food_id,
-    recipe_id,
-    calories,
-    total_grams,
-    protein,
-    carbs,
-    fats
-) VALUES (
-    $1,  -- user_id (BIGINT, NOT NULL)
-    $2,  -- food_id (BIGINT, can be NULL)
-    $3,  -- recipe_id (BIGINT, can be NULL) 
-    $4,  -- calories (DOUBLE PRECISION, NOT NULL)
-    $5,  -- total_grams (DOUBLE PRECISION, NOT NULL)
-    $6,  -- protein (DOUBLE PRECISION, NOT NULL)
-    $7,  -- carbs (DOUBLE PRECISION, NOT NULL)
-    $8   -- fats (DOUBLE PRECISION, NOT NULL)
-)
-RETURNING nutrition_id, user_id, food_id, recipe_id, calories, total_grams, protein, carbs, fats, created_at, last_updated
+const upsertUserProfile = `-- name: UpsertUserProfile :one
+INSERT INTO users_profile(user_id,date_of_birth,email,height,weight,sex,body_fat,preferred_units)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8)
+ON CONFLICT (user_id) DO UPDATE
+SET date_of_birth = EXCLUDED.date_of_birth,
+    email = EXCLUDED.email,
+    height = EXCLUDED.height,
+    weight = EXCLUDED.weight,
+    sex = EXCLUDED.sex,
+    body_fat = EXCLUDED.body_fat,
+    preferred_units = EXCLUDED.preferred_units,
+    last_updated = CURRENT_TIMESTAMP
+RETURNING user_id, date_of_birth, email, height, weight, sex, body_fat, preferred_units, is_trainer, is_vip, is_admin, last_updated
 `
 
-type LogFoodItemParams struct {
-	UserID     int64       `json:"user_id"`
-	FoodID     pgtype.Int8 `json:"food_id"`
-	RecipeID   pgtype.Int8 `json:"recipe_id"`
-	Calories   float64     `json:"calories"`
-	TotalGrams float64     `json:"total_grams"`
-	Protein    float64     `json:"protein"`
-	Carbs      float64     `json:"carbs"`
-	Fats       float64     `json:"fats"`
+type UpsertUserProfileParams struct {
+	UserID         int64          `json:"user_id"`
+	DateOfBirth    pgtype.Date    `json:"date_of_birth"`
+	Email          pgtype.Text    `json:"email"`
+	Height         pgtype.Numeric `json:"height"`
+	Weight         pgtype.Numeric `json:"weight"`
+	Sex            pgtype.Text    `json:"sex"`
+	BodyFat        pgtype.Numeric `json:"body_fat"`
+	PreferredUnits string         `json:"preferred_units"`
 }
 
-func (q *Queries) LogFoodItem(ctx context.Context, arg LogFoodItemParams) (FoodEntry, error) {
-	row := q.db.QueryRow(ctx, logFoodItem,
+func (q *Queries) UpsertUserProfile(ctx context.Context, arg UpsertUserProfileParams) (UsersProfile, error) {
+	row := q.db.QueryRow(ctx, upsertUserProfile,
 		arg.UserID,
-		arg.FoodID,
-		arg.RecipeID,
-		arg.Calories,
-		arg.TotalGrams,
-		arg.Protein,
-		arg.Carbs,
-		arg.Fats,
+		arg.DateOfBirth,
+		arg.Email,
+		arg.Height,
+		arg.Weight,
+		arg.Sex,
+		arg.BodyFat,
+		arg.PreferredUnits,
 	)
-	var i FoodEntry
+	var i UsersProfile
 	err := row.Scan(
-		&i.NutritionID,
 		&i.UserID,
-		&i.FoodID,
-		&i.RecipeID,
-		&i.Calories,
-		&i.TotalGrams,
-		&i.Protein,
-		&i.Carbs,
-		&i.Fats,
-		&i.CreatedAt,
+		&i.DateOfBirth,
+		&i.Email,
+		&i.Height,
+		&i.Weight,
+		&i.Sex,
+		&i.BodyFat,
+		&i.PreferredUnits,
+		&i.IsTrainer,
+		&i.IsVip,
+		&i.IsAdmin,
 		&i.LastUpdated,
 	)
 	return i, err
 }
 
 const viewFood = `-- name: ViewFood :many
-SELECT calories, protein, carbs, fats
-FROM food_entries
-WHERE user_id = $1 
-  AND created_at BETWEEN $2 AND $3
-ORDER BY created_at
+SELECT fe.nutrition_id, fe.food_id, fe.recipe_id, fe.cache_id,
+       COALESCE(f.food_name, r.recipe_name, fc.food_name)::text AS food_name,
+       fe.total_grams, fe.calories, fe.protein, fe.carbs, fe.fats,
+       fe.created_at, fe.last_updated
+FROM food_entries fe
+LEFT JOIN food f ON f.food_id = fe.food_id
+LEFT JOIN recipes r ON r.recipe_id = fe.recipe_id
+LEFT JOIN food_Cache fc ON fc.food_id = fe.cache_id
+WHERE fe.user_id = $1 
+  AND fe.created_at BETWEEN $2 AND $3
+ORDER BY fe.created_at
 `
 
 type ViewFoodParams struct {
@@ -275,10 +4039,18 @@ type ViewFoodParams struct {
 }
 
 type ViewFoodRow struct {
-	Calories float64 `json:"calories"`
-	Protein  float64 `json:"protein"`
-	Carbs    float64 `json:"carbs"`
-	Fats     float64 `json:"fats"`
+	NutritionID int64            `json:"nutrition_id"`
+	FoodID      pgtype.Int8      `json:"food_id"`
+	RecipeID    pgtype.Int8      `json:"recipe_id"`
+	CacheID     pgtype.Int8      `json:"cache_id"`
+	FoodName    string           `json:"food_name"`
+	TotalGrams  float64          `json:"total_grams"`
+	Calories    float64          `json:"calories"`
+	Protein     float64          `json:"protein"`
+	Carbs       float64          `json:"carbs"`
+	Fats        float64          `json:"fats"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	LastUpdated pgtype.Timestamp `json:"last_updated"`
 }
 
 func (q *Queries) ViewFood(ctx context.Context, arg ViewFoodParams) ([]ViewFoodRow, error) {
@@ -291,10 +4063,18 @@ func (q *Queries) ViewFood(ctx context.Context, arg ViewFoodParams) ([]ViewFoodR
 	for rows.Next() {
 		var i ViewFoodRow
 		if err := rows.Scan(
+			&i.NutritionID,
+			&i.FoodID,
+			&i.RecipeID,
+			&i.CacheID,
+			&i.FoodName,
+			&i.TotalGrams,
 			&i.Calories,
 			&i.Protein,
 			&i.Carbs,
 			&i.Fats,
+			&i.CreatedAt,
+			&i.LastUpdated,
 		); err != nil {
 			return nil, err
 		}
@@ -308,10 +4088,10 @@ func (q *Queries) ViewFood(ctx context.Context, arg ViewFoodParams) ([]ViewFoodR
 
 const viewFoodTotal = `-- name: ViewFoodTotal :one
 SELECT 
-  SUM(calories)::float as total_calories,
-  SUM(protein)::float as total_protein,
-  SUM(carbs)::float as total_carbs,
-  SUM(fats)::float as total_fats
+  COALESCE(SUM(calories), 0)::float as total_calories,
+  COALESCE(SUM(protein), 0)::float as total_protein,
+  COALESCE(SUM(carbs), 0)::float as total_carbs,
+  COALESCE(SUM(fats), 0)::float as total_fats
 FROM food_entries
 WHERE user_id = $1 
   AND created_at BETWEEN $2 AND $3
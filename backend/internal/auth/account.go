@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DeleteAccountHandler removes the user and everything they own. Tables that
+// cascade from users (profile, refresh tokens, trainer links, programs, meal plans)
+// go with the user row; the rest are deleted first in the same transaction.
+// The access token the request was made with is revoked as part of it.
+func (h *AuthHandler) DeleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+	var request DeleteAccountRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(DeleteAccountResponse{
+			Message: "Invalid request body",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(DeleteAccountResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	user, err := h.queries.GetUserByID(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(DeleteAccountResponse{
+			Message: "Database error",
+			Success: false,
+		})
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.HashedPassword), []byte(request.Password)); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(DeleteAccountResponse{
+			Message: "Password is incorrect",
+			Success: false,
+		})
+		return
+	}
+	claims, err := h.requestClaims(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(DeleteAccountResponse{
+			Message: "Unauthorized: invalid token",
+			Success: false,
+		})
+		return
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(DeleteAccountResponse{
+			Message: "Database error",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	// Children before parents: records and sets hang off entries, entries
+	// off sessions and exercises, ingredients off recipes and foods
+	err = qtx.DeleteUserPersonalRecords(r.Context(), userID)
+	if err == nil {
+		err = qtx.DeleteUserExerciseEntries(r.Context(), userID)
+	}
+	if err == nil {
+		err = qtx.DeleteUserWorkoutSessions(r.Context(), userID)
+	}
+	if err == nil {
+		err = qtx.DeleteUserExercises(r.Context(), userID)
+	}
+	if err == nil {
+		err = qtx.DeleteUserFoodEntries(r.Context(), userID)
+	}
+	if err == nil {
+		err = qtx.DeleteUserRecipeIngredients(r.Context(), userID)
+	}
+	if err == nil {
+		err = qtx.DeleteUserRecipes(r.Context(), userID)
+	}
+	if err == nil {
+		err = qtx.DeleteUserFoodCache(r.Context(), userID)
+	}
+	if err == nil {
+		err = qtx.DeleteUserFoods(r.Context(), userID)
+	}
+	if err == nil {
+		err = qtx.DeleteUserNutritionTargets(r.Context(), userID)
+	}
+	if err == nil {
+		err = qtx.DeleteUserMeasurements(r.Context(), userID)
+	}
+	// The revocation outlives the user row, so the token used here can't be
+	// replayed for the rest of its lifetime
+	if err == nil {
+		err = revokeAccessToken(r.Context(), qtx, userID, claims)
+	}
+	if err == nil {
+		err = qtx.DeleteUser(r.Context(), userID)
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(DeleteAccountResponse{
+			Message: "Failed to delete account",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(DeleteAccountResponse{
+		Message: "Account deleted successfully",
+		Success: true,
+	})
+}
@@ -1,26 +1,49 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/Bughay/Trainer-GO/internal/mail"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthHandler struct {
+	pool      *pgxpool.Pool
 	queries   *db.Queries
 	jwtSecret []byte
+	mailer    mail.Sender
+	policy    Policy
+	throttle  *Throttle
 }
 
-func NewAuthHandler(q *db.Queries, jwtSecret string) (*AuthHandler, error) {
+func NewAuthHandler(q *db.Queries, pool *pgxpool.Pool, jwtSecret string, mailer mail.Sender, policy Policy, throttle *Throttle) (*AuthHandler, error) {
 	if jwtSecret == "" {
 		return nil, fmt.Errorf("jwt secret cannot be empty")
 	}
+	if mailer == nil {
+		return nil, fmt.Errorf("mail sender cannot be nil")
+	}
+	if throttle == nil {
+		return nil, fmt.Errorf("login throttle cannot be nil")
+	}
 	return &AuthHandler{
+		pool:      pool,
 		queries:   q,
 		jwtSecret: []byte(jwtSecret),
+		mailer:    mailer,
+		policy:    policy,
+		throttle:  throttle,
 	}, nil
 }
 
@@ -39,6 +62,20 @@ func (h *AuthHandler) UserRegistrationHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	verr := h.policy.ValidateUsername(request.Username)
+	if verr == nil {
+		verr = h.policy.ValidatePassword(request.Password, request.Username, "password")
+	}
+	if verr != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(UserRegistrationResponse{
+			Message: verr.Message,
+			Success: false,
+			Error:   verr,
+		})
+		return
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -53,8 +90,44 @@ func (h *AuthHandler) UserRegistrationHandler(w http.ResponseWriter, r *http.Req
 		Username:       request.Username,
 		HashedPassword: string(hashedPassword),
 	}
-	user, err := h.queries.CreateUser(r.Context(), userParams)
 
+	// The user and their empty profile are created together so every
+	// account has a users_profile row
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(UserRegistrationResponse{
+			Message: "Database error",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	user, err := qtx.CreateUser(r.Context(), userParams)
+	if err == nil {
+		err = qtx.CreateUserProfile(r.Context(), user.UserID)
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+
+	// The unique index is on lower(username), so this also catches names
+	// differing only by case
+	if common.IsUniqueViolation(err) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(UserRegistrationResponse{
+			Message: "Username is already taken",
+			Success: false,
+			Error: &ValidationError{
+				Field:   "username",
+				Code:    "username_taken",
+				Message: "Username is already taken",
+			},
+		})
+		return
+	}
 	if err != nil {
 		// Other database errors
 		w.WriteHeader(http.StatusInternalServerError)
@@ -88,8 +161,33 @@ func (h *AuthHandler) UserLoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ip := h.throttle.ClientIP(r)
+	wait, err := h.throttle.Reserve(r.Context(), request.Username, ip, time.Now())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(UserLoginResponse{
+			Message: "Database error",
+			Success: false,
+		})
+		return
+	}
+	if wait > 0 {
+		// The password isn't checked at all while throttled, so guesses
+		// made during the wait tell the caller nothing
+		h.auditLogin(r.Context(), request.Username, pgtype.Int8{}, ip, loginThrottled)
+		seconds := int(math.Ceil(wait.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(UserLoginResponse{
+			Message: fmt.Sprintf("Too many failed login attempts; try again in %d seconds", seconds),
+			Success: false,
+		})
+		return
+	}
+
 	user, err := h.queries.GetUserByUsername(r.Context(), request.Username)
 	if err != nil {
+		h.auditLogin(r.Context(), request.Username, pgtype.Int8{}, ip, loginUnknownUser)
 		w.WriteHeader(http.StatusUnauthorized)
 		response = UserLoginResponse{
 			Message: "Invalid username or password",
@@ -101,6 +199,7 @@ func (h *AuthHandler) UserLoginHandler(w http.ResponseWriter, r *http.Request) {
 	err = bcrypt.CompareHashAndPassword([]byte(user.HashedPassword), []byte(request.Password))
 	if err != nil {
 		// Password doesn't match
+		h.auditLogin(r.Context(), request.Username, pgtype.Int8{Int64: user.UserID, Valid: true}, ip, loginBadPassword)
 		w.WriteHeader(http.StatusUnauthorized)
 		response = UserLoginResponse{
 			Message: "Invalid username or password", // Same message for security
@@ -109,7 +208,15 @@ func (h *AuthHandler) UserLoginHandler(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(response)
 		return
 	}
-	token, err := h.GenerateToken(int64(user.UserID), user.Username)
+	if err := h.throttle.Succeed(r.Context(), request.Username, ip); err != nil {
+		log.Printf("reset login throttle for %q: %v", request.Username, err)
+	}
+	// Each login starts a new refresh token family
+	familyID, err := randomHex(16)
+	var token, refreshToken string
+	if err == nil {
+		token, refreshToken, err = h.issueTokenPair(r.Context(), h.queries, int64(user.UserID), user.Username, familyID)
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(UserLoginResponse{
@@ -120,12 +227,36 @@ func (h *AuthHandler) UserLoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response = UserLoginResponse{
-		Message: "works", // Same message for security
-		Success: true,
-		Token:   token,
+		Message:      "works", // Same message for security
+		Success:      true,
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTokenTTL.Seconds()),
 	}
 	json.NewEncoder(w).Encode(response)
 
 	return
 
 }
+
+// Outcomes recorded in login_attempts
+const (
+	loginUnknownUser = "unknown_user"
+	loginBadPassword = "bad_password"
+	loginThrottled   = "throttled"
+)
+
+// auditLogin records a failed or throttled login. The failure itself was
+// already counted by Throttle.Reserve. Errors are logged rather than
+// returned so the caller still gets its response.
+func (h *AuthHandler) auditLogin(ctx context.Context, username string, userID pgtype.Int8, ip, outcome string) {
+	err := h.queries.CreateLoginAttempt(ctx, db.CreateLoginAttemptParams{
+		Username:  username,
+		UserID:    userID,
+		IpAddress: ip,
+		Outcome:   outcome,
+	})
+	if err != nil {
+		log.Printf("audit login attempt for %q: %v", username, err)
+	}
+}
@@ -2,6 +2,8 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
@@ -10,20 +12,33 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// accessTokenTTL is kept short because access tokens are only checked
+// against the revocation list, never re-issued; clients renew them with a
+// refresh token
+const accessTokenTTL = 15 * time.Minute
+
 type Claims struct {
 	UserID   int64  `json:"user_id"`
 	Username string `json:"username"`
+	Roles    []Role `json:"roles,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func (h *AuthHandler) GenerateToken(userID int64, username string) (string, error) {
-	// Token expires in 24 hours
-	expirationTime := time.Now().Add(24 * time.Hour)
+func (h *AuthHandler) GenerateToken(userID int64, username string, roles []Role) (string, error) {
+	expirationTime := time.Now().Add(accessTokenTTL)
+
+	// The jti lets a single token be revoked on logout
+	jti, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
 
 	claims := &Claims{
 		UserID:   userID,
 		Username: username,
+		Roles:    roles,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "trainer-go",
@@ -71,3 +86,12 @@ func ExtractTokenFromRequest(r *http.Request) (string, error) {
 
 	return "", errors.New("invalid authorization header format")
 }
+
+// randomHex returns n random bytes hex encoded
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -2,14 +2,9 @@
 package auth
 
 import (
-	"context"
 	"net/http"
 )
 
-type contextKey string
-
-const UserIDKey contextKey = "user_id"
-
 func (h *AuthHandler) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		tokenString, err := ExtractTokenFromRequest(r)
@@ -24,8 +19,31 @@ func (h *AuthHandler) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+		// Tokens issued before revocation support carry no jti and can't be
+		// logged out, so they are no longer accepted
+		if claims.ID == "" {
+			http.Error(w, "Unauthorized: invalid token", http.StatusUnauthorized)
+			return
+		}
+		revoked, err := h.queries.IsAccessTokenRevoked(r.Context(), claims.ID)
+		if err != nil {
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		if revoked {
+			http.Error(w, "Unauthorized: token has been revoked", http.StatusUnauthorized)
+			return
+		}
 
+		roles := claims.Roles
+		if len(roles) == 0 {
+			roles = []Role{RoleUser}
+		}
+		ctx := WithPrincipal(r.Context(), Principal{
+			UserID:   claims.UserID,
+			Username: claims.Username,
+			Roles:    roles,
+		})
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// bcrypt ignores everything past 72 bytes, so longer passwords are rejected
+// rather than silently truncated
+const maxPasswordBytes = 72
+
+//go:embed breached_passwords.txt
+var defaultBreachedPasswords string
+
+// Policy holds the rules usernames and passwords are checked against.
+// Usernames are compared case-insensitively, both here and by the unique
+// index on lower(username).
+type Policy struct {
+	UsernameMinLength int
+	UsernameMaxLength int
+	// UsernamePattern lists the allowed characters and shape of a username
+	UsernamePattern *regexp.Regexp
+	// UsernameRule describes UsernamePattern in error messages
+	UsernameRule      string
+	PasswordMinLength int
+	// BreachedPasswords holds known leaked passwords, lower-cased
+	BreachedPasswords map[string]struct{}
+}
+
+// DefaultPolicy allows 3-30 letters, digits, dots, dashes and underscores
+// starting with a letter or digit, and passwords of at least 8 characters
+// that aren't on the built-in breached list
+func DefaultPolicy() Policy {
+	policy := Policy{
+		UsernameMinLength: 3,
+		UsernameMaxLength: 30,
+		UsernamePattern:   regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`),
+		UsernameRule:      "letters, digits, '.', '-' and '_', starting with a letter or digit",
+		PasswordMinLength: 8,
+		BreachedPasswords: make(map[string]struct{}),
+	}
+	policy.addBreachedPasswords(strings.NewReader(defaultBreachedPasswords))
+	return policy
+}
+
+// LoadBreachedPasswords adds the passwords in path, one per line, to the
+// breached list. Blank lines and lines starting with # are skipped.
+func (p *Policy) LoadBreachedPasswords(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open breached password list: %w", err)
+	}
+	defer f.Close()
+	if err := p.addBreachedPasswords(f); err != nil {
+		return fmt.Errorf("read breached password list: %w", err)
+	}
+	return nil
+}
+
+func (p *Policy) addBreachedPasswords(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.BreachedPasswords[strings.ToLower(line)] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+// ValidationError is a rejected field, returned to clients as a structured
+// error
+type ValidationError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func (p Policy) ValidateUsername(username string) *ValidationError {
+	length := utf8.RuneCountInString(username)
+	switch {
+	case length < p.UsernameMinLength:
+		return &ValidationError{
+			Field:   "username",
+			Code:    "username_too_short",
+			Message: fmt.Sprintf("Username must be at least %d characters", p.UsernameMinLength),
+		}
+	case length > p.UsernameMaxLength:
+		return &ValidationError{
+			Field:   "username",
+			Code:    "username_too_long",
+			Message: fmt.Sprintf("Username must be at most %d characters", p.UsernameMaxLength),
+		}
+	case p.UsernamePattern != nil && !p.UsernamePattern.MatchString(username):
+		return &ValidationError{
+			Field:   "username",
+			Code:    "username_invalid_characters",
+			Message: "Username may only contain " + p.UsernameRule,
+		}
+	}
+	return nil
+}
+
+// ValidatePassword checks password against the policy. username may be
+// empty when it isn't known, which skips the check that they differ.
+func (p Policy) ValidatePassword(password, username, field string) *ValidationError {
+	switch {
+	case utf8.RuneCountInString(password) < p.PasswordMinLength:
+		return &ValidationError{
+			Field:   field,
+			Code:    "password_too_short",
+			Message: fmt.Sprintf("Password must be at least %d characters", p.PasswordMinLength),
+		}
+	case len(password) > maxPasswordBytes:
+		return &ValidationError{
+			Field:   field,
+			Code:    "password_too_long",
+			Message: fmt.Sprintf("Password must be at most %d bytes", maxPasswordBytes),
+		}
+	case username != "" && strings.EqualFold(password, username):
+		return &ValidationError{
+			Field:   field,
+			Code:    "password_matches_username",
+			Message: "Password must not be the same as the username",
+		}
+	}
+	if _, breached := p.BreachedPasswords[strings.ToLower(password)]; breached {
+		return &ValidationError{
+			Field:   field,
+			Code:    "password_breached",
+			Message: "Password appears in a list of breached passwords; choose another",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type Role string
+
+const (
+	RoleUser    Role = "user"
+	RoleTrainer Role = "trainer"
+	RoleVIP     Role = "vip"
+	RoleAdmin   Role = "admin"
+)
+
+// rolesFromProfile maps the users_profile flags to roles. Every account is
+// a user.
+func rolesFromProfile(profile db.UsersProfile) []Role {
+	roles := []Role{RoleUser}
+	if profile.IsTrainer {
+		roles = append(roles, RoleTrainer)
+	}
+	if profile.IsVip {
+		roles = append(roles, RoleVIP)
+	}
+	if profile.IsAdmin {
+		roles = append(roles, RoleAdmin)
+	}
+	return roles
+}
+
+// loadRoles reads the user's roles for a new access token. q may be bound
+// to a transaction.
+func loadRoles(ctx context.Context, q *db.Queries, userID int64) ([]Role, error) {
+	profile, err := q.GetUserProfile(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []Role{RoleUser}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rolesFromProfile(profile), nil
+}
+
+// Principal is the authenticated caller. Roles come from the access token,
+// so a role change takes effect when the token is next refreshed.
+type Principal struct {
+	UserID   int64
+	Username string
+	Roles    []Role
+	// ClientID is set on client-scoped routes, where a trainer acts on a
+	// client's data
+	ClientID int64
+}
+
+func (p Principal) HasRole(role Role) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// SubjectID is the user whose data the request reads: the client on
+// client-scoped routes, otherwise the caller. Handlers that write use UserID
+// instead, so wrapping one in RequireOwnerOrTrainerOf can never make a
+// trainer write into a client's data.
+func (p Principal) SubjectID() int64 {
+	if p.ClientID != 0 {
+		return p.ClientID
+	}
+	return p.UserID
+}
+
+type contextKey string
+
+const principalKey contextKey = "principal"
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext returns the caller set by AuthMiddleware
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(Principal)
+	return principal, ok
+}
+
+// RequireRole lets the request through only if the caller has role. Admins
+// get no bypass: trainer routes go on to check the trainer relationship,
+// which needs the trainer flag itself. It must run inside AuthMiddleware.
+func RequireRole(role Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized: authentication required", http.StatusUnauthorized)
+			return
+		}
+		if !principal.HasRole(role) {
+			http.Error(w, "Forbidden: requires the "+string(role)+" role", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// RequireOwnerOrTrainerOf guards routes acting on the user in the param path
+// wildcard. The caller must be that user or their trainer through an active
+// relationship; in the latter case Principal.ClientID is set to the user.
+// It must run inside AuthMiddleware.
+func (h *AuthHandler) RequireOwnerOrTrainerOf(param string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized: authentication required", http.StatusUnauthorized)
+			return
+		}
+		ownerID, err := strconv.ParseInt(r.PathValue(param), 10, 64)
+		if err != nil || ownerID <= 0 {
+			http.Error(w, "Invalid client id", http.StatusBadRequest)
+			return
+		}
+		if ownerID == principal.UserID {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Checked against the database rather than the token's roles so an
+		// ended relationship cuts off access straight away
+		linked, err := h.queries.IsTrainerOfClient(r.Context(), db.IsTrainerOfClientParams{
+			TrainerID: principal.UserID,
+			ClientID:  ownerID,
+		})
+		if err != nil {
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		if !linked {
+			http.Error(w, "Forbidden: not a trainer of this client", http.StatusForbidden)
+			return
+		}
+		principal.ClientID = ownerID
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	}
+}
@@ -0,0 +1,290 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// refreshTokenTTL bounds how long a session survives without the user
+// signing in again. Every refresh rotates the token but keeps its family.
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+// hashToken returns the form refresh and password reset tokens are stored
+// and looked up by, so a database leak does not expose usable tokens
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueTokenPair creates an access token carrying the user's current roles
+// and a new refresh token in the given family. q may be bound to a
+// transaction.
+func (h *AuthHandler) issueTokenPair(ctx context.Context, q *db.Queries, userID int64, username, familyID string) (string, string, error) {
+	roles, err := loadRoles(ctx, q, userID)
+	if err != nil {
+		return "", "", err
+	}
+	accessToken, err := h.GenerateToken(userID, username, roles)
+	if err != nil {
+		return "", "", err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(b)
+
+	_, err = q.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().UTC().Add(refreshTokenTTL), Valid: true},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// RefreshHandler exchanges a refresh token for a new access and refresh
+// token. Each refresh token works once; presenting one that was already
+// used means it was copied, so the whole family is revoked and both holders
+// have to sign in again.
+func (h *AuthHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	var request RefreshRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.RefreshToken == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(UserLoginResponse{
+			Message: "refresh_token is required",
+			Success: false,
+		})
+		return
+	}
+
+	stored, err := h.queries.GetRefreshTokenByHash(r.Context(), hashToken(request.RefreshToken))
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(UserLoginResponse{
+			Message: "Invalid refresh token",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(UserLoginResponse{
+			Message: "Database error",
+			Success: false,
+		})
+		return
+	}
+
+	if stored.UsedAt.Valid || stored.RevokedAt.Valid {
+		h.revokeReusedFamily(w, r, stored.FamilyID)
+		return
+	}
+	if time.Now().UTC().After(stored.ExpiresAt.Time) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(UserLoginResponse{
+			Message: "Refresh token has expired",
+			Success: false,
+		})
+		return
+	}
+
+	user, err := h.queries.GetUserByID(r.Context(), stored.UserID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(UserLoginResponse{
+			Message: "Database error",
+			Success: false,
+		})
+		return
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(UserLoginResponse{
+			Message: "Database error",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	rows, err := qtx.MarkRefreshTokenUsed(r.Context(), stored.TokenID)
+	if err == nil && rows == 0 {
+		// Another request used the token between the lookup and now
+		tx.Rollback(r.Context())
+		h.revokeReusedFamily(w, r, stored.FamilyID)
+		return
+	}
+	var accessToken, refreshToken string
+	if err == nil {
+		accessToken, refreshToken, err = h.issueTokenPair(r.Context(), qtx, user.UserID, user.Username, stored.FamilyID)
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(UserLoginResponse{
+			Message: "Failed to refresh token",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(UserLoginResponse{
+		Message:      "Token refreshed successfully",
+		Success:      true,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTokenTTL.Seconds()),
+	})
+}
+
+// revokeReusedFamily handles a refresh token presented a second time
+func (h *AuthHandler) revokeReusedFamily(w http.ResponseWriter, r *http.Request, familyID string) {
+	if err := h.queries.RevokeRefreshTokenFamily(r.Context(), familyID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(UserLoginResponse{
+			Message: "Database error",
+			Success: false,
+		})
+		return
+	}
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(UserLoginResponse{
+		Message: "Refresh token has already been used; please sign in again",
+		Success: false,
+	})
+}
+
+// requestClaims parses the request's access token again for the jti and
+// expiry a revocation row needs. AuthMiddleware has already validated it.
+func (h *AuthHandler) requestClaims(r *http.Request) (*Claims, error) {
+	tokenString, err := ExtractTokenFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return h.ValidateToken(tokenString)
+}
+
+// revokeAccessToken stops the token behind claims from being accepted again
+// before it expires. q may be bound to a transaction.
+func revokeAccessToken(ctx context.Context, q *db.Queries, userID int64, claims *Claims) error {
+	return q.RevokeAccessToken(ctx, db.RevokeAccessTokenParams{
+		Jti:       claims.ID,
+		UserID:    pgtype.Int8{Int64: userID, Valid: true},
+		ExpiresAt: pgtype.Timestamp{Time: claims.ExpiresAt.Time.UTC(), Valid: true},
+	})
+}
+
+// LogoutHandler revokes the access token the request was made with and the
+// session behind the given refresh token, or every session when all is set.
+// The body is optional.
+func (h *AuthHandler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	var request LogoutRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(LogoutResponse{
+			Message: "Invalid request body",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(LogoutResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	claims, err := h.requestClaims(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(LogoutResponse{
+			Message: "Unauthorized: invalid token",
+			Success: false,
+		})
+		return
+	}
+
+	var familyID string
+	if request.RefreshToken != "" && !request.All {
+		stored, err := h.queries.GetRefreshTokenByHash(r.Context(), hashToken(request.RefreshToken))
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(LogoutResponse{
+				Message: "Database error",
+				Success: false,
+			})
+			return
+		}
+		// Someone else's refresh token is ignored rather than revoked
+		if err == nil && stored.UserID == userID {
+			familyID = stored.FamilyID
+		}
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(LogoutResponse{
+			Message: "Database error",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	err = revokeAccessToken(r.Context(), qtx, userID, claims)
+	if err == nil && request.All {
+		err = qtx.RevokeUserRefreshTokens(r.Context(), userID)
+	} else if err == nil && familyID != "" {
+		err = qtx.RevokeRefreshTokenFamily(r.Context(), familyID)
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(LogoutResponse{
+			Message: "Failed to log out",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(LogoutResponse{
+		Message: "Logged out successfully",
+		Success: true,
+	})
+}
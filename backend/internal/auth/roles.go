@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// SetRolesHandler lets an admin grant or revoke the trainer, VIP and admin
+// roles. The user's new roles are in their next access token.
+func (h *AuthHandler) SetRolesHandler(w http.ResponseWriter, r *http.Request) {
+	var request SetRolesRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || userID <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(SetRolesResponse{
+			Message: "Invalid user id",
+			Success: false,
+		})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(SetRolesResponse{
+			Message: "Invalid request body",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(SetRolesResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+
+	// Keeps the last admin from locking everyone out by accident
+	if userID == principal.UserID && !request.IsAdmin {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(SetRolesResponse{
+			Message: "Admins cannot remove their own admin role",
+			Success: false,
+		})
+		return
+	}
+
+	profile, err := h.queries.SetUserRoles(r.Context(), db.SetUserRolesParams{
+		UserID:    userID,
+		IsTrainer: request.IsTrainer,
+		IsVip:     request.IsVip,
+		IsAdmin:   request.IsAdmin,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(SetRolesResponse{
+			Message: "User not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(SetRolesResponse{
+			Message: "Database error",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SetRolesResponse{
+		Message: "Roles updated successfully",
+		Success: true,
+		UserID:  profile.UserID,
+		Roles:   rolesFromProfile(profile),
+	})
+}
@@ -9,6 +9,8 @@ type UserRegistrationResponse struct {
 	Message string `json:"message"`
 	Success bool   `json:"success"`
 	UserID  string `json:"user_id,omitempty"`
+	// Error says which field was rejected and why
+	Error *ValidationError `json:"error,omitempty"`
 }
 
 type UserLoginRequest struct {
@@ -20,6 +22,71 @@ type UserLoginResponse struct {
 	Message string `json:"message"`
 	Success bool   `json:"success"`
 	Token   string `json:"token,omitempty"`
+	// RefreshToken is exchanged at /auth/refresh for a new token pair
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// ExpiresIn is the access token lifetime in seconds
+	ExpiresIn int64 `json:"expires_in,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutRequest revokes the access token used for the call and the refresh
+// token's family; All revokes every refresh token the user holds
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	All          bool   `json:"all"`
+}
+
+type LogoutResponse struct {
+	Message string `json:"message"`
+	Success bool   `json:"success"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type PasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type PasswordResponse struct {
+	Message string           `json:"message"`
+	Success bool             `json:"success"`
+	Error   *ValidationError `json:"error,omitempty"`
+}
+
+// DeleteAccountRequest re-confirms the password before the account and all
+// of its data are removed
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type DeleteAccountResponse struct {
+	Message string `json:"message"`
+	Success bool   `json:"success"`
+}
+
+// SetRolesRequest replaces all of a user's optional roles
+type SetRolesRequest struct {
+	IsTrainer bool `json:"is_trainer"`
+	IsVip     bool `json:"is_vip"`
+	IsAdmin   bool `json:"is_admin"`
+}
+
+type SetRolesResponse struct {
+	Message string `json:"message"`
+	Success bool   `json:"success"`
+	UserID  int64  `json:"user_id,omitempty"`
+	Roles   []Role `json:"roles,omitempty"`
 }
 
 type User struct {
@@ -0,0 +1,348 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Attempts is the failed login history of one key
+type Attempts struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// AttemptStore counts failed logins per key. Failures older than the window
+// no longer count; RecordFailure starts the count over in that case.
+// Logins reserve a failure up front with RecordFailure and hand it back with
+// Release, so concurrent attempts can't all slip past the limit.
+type AttemptStore interface {
+	Get(ctx context.Context, key string) (Attempts, error)
+	RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (Attempts, error)
+	Release(ctx context.Context, key string) error
+	Reset(ctx context.Context, key string) error
+	// Prune drops keys whose last failure is before cutoff
+	Prune(ctx context.Context, cutoff time.Time) error
+}
+
+// MemoryAttemptStore keeps attempts in process memory, which is enough for a
+// single instance. Counters are lost on restart.
+type MemoryAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]Attempts
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{attempts: make(map[string]Attempts)}
+}
+
+func (s *MemoryAttemptStore) Get(ctx context.Context, key string) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts[key], nil
+}
+
+func (s *MemoryAttemptStore) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.attempts[key]
+	if now.Sub(a.LastFailure) > window {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastFailure = now
+	s.attempts[key] = a
+	return a, nil
+}
+
+func (s *MemoryAttemptStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.attempts[key]; ok && a.Failures > 0 {
+		a.Failures--
+		s.attempts[key] = a
+	}
+	return nil
+}
+
+func (s *MemoryAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+func (s *MemoryAttemptStore) Prune(ctx context.Context, cutoff time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, a := range s.attempts {
+		if a.LastFailure.Before(cutoff) {
+			delete(s.attempts, key)
+		}
+	}
+	return nil
+}
+
+// PostgresAttemptStore keeps attempts in the login_throttle table so all
+// replicas share the same counters
+type PostgresAttemptStore struct {
+	queries *db.Queries
+}
+
+func NewPostgresAttemptStore(q *db.Queries) *PostgresAttemptStore {
+	return &PostgresAttemptStore{queries: q}
+}
+
+func (s *PostgresAttemptStore) Get(ctx context.Context, key string) (Attempts, error) {
+	row, err := s.queries.GetLoginThrottle(ctx, key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Attempts{}, nil
+	}
+	if err != nil {
+		return Attempts{}, err
+	}
+	return Attempts{Failures: int(row.Failures), LastFailure: row.LastFailure.Time}, nil
+}
+
+func (s *PostgresAttemptStore) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (Attempts, error) {
+	row, err := s.queries.RecordLoginFailure(ctx, db.RecordLoginFailureParams{
+		ThrottleKey: key,
+		FailedAt:    pgtype.Timestamp{Time: now.UTC(), Valid: true},
+		WindowStart: pgtype.Timestamp{Time: now.UTC().Add(-window), Valid: true},
+	})
+	if err != nil {
+		return Attempts{}, err
+	}
+	return Attempts{Failures: int(row.Failures), LastFailure: row.LastFailure.Time}, nil
+}
+
+func (s *PostgresAttemptStore) Release(ctx context.Context, key string) error {
+	return s.queries.ReleaseLoginFailure(ctx, key)
+}
+
+func (s *PostgresAttemptStore) Reset(ctx context.Context, key string) error {
+	return s.queries.ResetLoginThrottle(ctx, key)
+}
+
+func (s *PostgresAttemptStore) Prune(ctx context.Context, cutoff time.Time) error {
+	_, err := s.queries.DeleteStaleLoginThrottles(ctx, pgtype.Timestamp{Time: cutoff.UTC(), Valid: true})
+	return err
+}
+
+// ThrottleRule sets how failures on one kind of key are slowed down. After
+// FreeAttempts failures each further attempt waits BaseDelay, doubling per
+// failure up to MaxDelay; at LockoutAfter failures the key is locked for
+// LockoutDuration.
+type ThrottleRule struct {
+	FreeAttempts    int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutAfter    int
+	LockoutDuration time.Duration
+}
+
+// wait returns how long a key with these attempts must wait before the
+// next login, or zero
+func (rule ThrottleRule) wait(a Attempts, now time.Time) time.Duration {
+	if a.Failures < rule.FreeAttempts {
+		return 0
+	}
+	var delay time.Duration
+	if a.Failures >= rule.LockoutAfter {
+		delay = rule.LockoutDuration
+	} else {
+		delay = rule.BaseDelay
+		for i := rule.FreeAttempts; i < a.Failures && delay < rule.MaxDelay; i++ {
+			delay *= 2
+		}
+		delay = min(delay, rule.MaxDelay)
+	}
+	return max(a.LastFailure.Add(delay).Sub(now), 0)
+}
+
+// Throttle applies separate rules per username and per client IP. The IP
+// rule is looser since many users can share an address.
+type Throttle struct {
+	store    AttemptStore
+	Window   time.Duration
+	Username ThrottleRule
+	IP       ThrottleRule
+	// TrustedProxies are the load balancers whose X-Forwarded-For header is
+	// believed when working out the client IP
+	TrustedProxies []*net.IPNet
+}
+
+func NewThrottle(store AttemptStore) *Throttle {
+	return &Throttle{
+		store:  store,
+		Window: time.Hour,
+		Username: ThrottleRule{
+			FreeAttempts:    3,
+			BaseDelay:       time.Second,
+			MaxDelay:        time.Minute,
+			LockoutAfter:    10,
+			LockoutDuration: 15 * time.Minute,
+		},
+		IP: ThrottleRule{
+			FreeAttempts:    20,
+			BaseDelay:       time.Second,
+			MaxDelay:        time.Minute,
+			LockoutAfter:    100,
+			LockoutDuration: 15 * time.Minute,
+		},
+	}
+}
+
+func usernameKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// current returns the attempts for key that still fall inside the window
+func (t *Throttle) current(ctx context.Context, key string, now time.Time) (Attempts, error) {
+	a, err := t.store.Get(ctx, key)
+	if err != nil {
+		return Attempts{}, err
+	}
+	if now.Sub(a.LastFailure) > t.Window {
+		return Attempts{}, nil
+	}
+	return a, nil
+}
+
+// raced reports whether a reservation lost to a concurrent attempt: past the
+// free attempts only the request that moved the count on by exactly one may
+// go ahead
+func (rule ThrottleRule) raced(before, after Attempts) bool {
+	return after.Failures > rule.FreeAttempts && after.Failures != before.Failures+1
+}
+
+// Reserve counts a login attempt as failed before the password is checked
+// and returns how long the caller must wait, or zero if they may try now.
+// When zero is returned the caller must follow up with Succeed on a correct
+// password; otherwise the reserved failure stands.
+func (t *Throttle) Reserve(ctx context.Context, username, ip string, now time.Time) (time.Duration, error) {
+	userKey, addrKey := usernameKey(username), ipKey(ip)
+	userBefore, err := t.current(ctx, userKey, now)
+	if err != nil {
+		return 0, err
+	}
+	ipBefore, err := t.current(ctx, addrKey, now)
+	if err != nil {
+		return 0, err
+	}
+	if wait := max(t.Username.wait(userBefore, now), t.IP.wait(ipBefore, now)); wait > 0 {
+		return wait, nil
+	}
+
+	userAfter, err := t.store.RecordFailure(ctx, userKey, now, t.Window)
+	if err != nil {
+		return 0, err
+	}
+	ipAfter, err := t.store.RecordFailure(ctx, addrKey, now, t.Window)
+	if err != nil {
+		return 0, errors.Join(err, t.store.Release(ctx, userKey))
+	}
+	if !t.Username.raced(userBefore, userAfter) && !t.IP.raced(ipBefore, ipAfter) {
+		return 0, nil
+	}
+
+	// Another request took the slot; this one is turned away without
+	// counting as a guess
+	if err := errors.Join(t.store.Release(ctx, userKey), t.store.Release(ctx, addrKey)); err != nil {
+		return 0, err
+	}
+	winner := func(a Attempts) Attempts { return Attempts{Failures: a.Failures + 1, LastFailure: now} }
+	return max(t.Username.wait(winner(userBefore), now), t.IP.wait(winner(ipBefore), now), time.Second), nil
+}
+
+// Succeed clears the username's failures and hands back the IP's reserved
+// one. The IP's earlier failures are kept, otherwise one valid account
+// would let an address reset its own limit.
+func (t *Throttle) Succeed(ctx context.Context, username, ip string) error {
+	return errors.Join(t.store.Reset(ctx, usernameKey(username)), t.store.Release(ctx, ipKey(ip)))
+}
+
+// Prune drops counters that have been quiet for longer than the window,
+// including those for usernames that don't exist
+func (t *Throttle) Prune(ctx context.Context, now time.Time) error {
+	return t.store.Prune(ctx, now.Add(-t.Window))
+}
+
+// ParseTrustedProxies reads a comma-separated list of CIDRs or single
+// addresses
+func ParseTrustedProxies(value string) ([]*net.IPNet, error) {
+	var proxies []*net.IPNet
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !strings.Contains(part, "/") {
+			ip := net.ParseIP(part)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address %q", part)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			part = fmt.Sprintf("%s/%d", part, bits)
+		}
+		_, network, err := net.ParseCIDR(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy range %q: %w", part, err)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+func (t *Throttle) trusted(ip net.IP) bool {
+	for _, network := range t.TrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP is the address the request came from. X-Forwarded-For is only
+// read when the connection comes from a trusted proxy, and then the
+// rightmost address that isn't itself a trusted proxy is used, since
+// anything left of that could have been set by the client.
+func (t *Throttle) ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote := net.ParseIP(host)
+	if remote == nil || !t.trusted(remote) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		if !t.trusted(ip) {
+			return ip.String()
+		}
+	}
+	return host
+}
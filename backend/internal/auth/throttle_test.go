@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestThrottleRuleWait(t *testing.T) {
+	rule := ThrottleRule{
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutAfter:    10,
+		LockoutDuration: 15 * time.Minute,
+	}
+	last := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		failures int
+		elapsed  time.Duration
+		want     time.Duration
+	}{
+		{name: "no failures", failures: 0, want: 0},
+		{name: "within free attempts", failures: 2, want: 0},
+		{name: "first delayed attempt", failures: 3, want: time.Second},
+		{name: "delay doubles", failures: 4, want: 2 * time.Second},
+		{name: "delay keeps doubling", failures: 7, want: 16 * time.Second},
+		{name: "delay capped", failures: 9, want: time.Minute},
+		{name: "lockout", failures: 10, want: 15 * time.Minute},
+		{name: "delay partly served", failures: 5, elapsed: 3 * time.Second, want: time.Second},
+		{name: "delay served", failures: 5, elapsed: 10 * time.Second, want: 0},
+		{name: "lockout partly served", failures: 12, elapsed: 5 * time.Minute, want: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rule.wait(Attempts{Failures: tt.failures, LastFailure: last}, last.Add(tt.elapsed))
+			if got != tt.want {
+				t.Errorf("wait() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThrottleReserve(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	throttle := NewThrottle(NewMemoryAttemptStore())
+
+	// Each step is one login at start+at; the wrong password leaves the
+	// reserved failure in place
+	steps := []struct {
+		name string
+		at   time.Duration
+		want time.Duration
+	}{
+		{name: "first attempt", at: 0, want: 0},
+		{name: "second attempt", at: 0, want: 0},
+		{name: "third attempt", at: 0, want: 0},
+		{name: "fourth attempt waits", at: 0, want: time.Second},
+		{name: "after the wait", at: time.Second, want: 0},
+		{name: "wait doubles", at: time.Second, want: 2 * time.Second},
+		{name: "failures expire with the window", at: 2 * time.Hour, want: 0},
+	}
+	for _, step := range steps {
+		got, err := throttle.Reserve(ctx, "Alice", "203.0.113.7", start.Add(step.at))
+		if err != nil {
+			t.Fatalf("%s: Reserve() error = %v", step.name, err)
+		}
+		if got != step.want {
+			t.Errorf("%s: Reserve() = %v, want %v", step.name, got, step.want)
+		}
+	}
+
+	// Usernames are counted case-insensitively
+	got, _ := throttle.store.Get(ctx, usernameKey("alice"))
+	if got.Failures != 1 {
+		t.Errorf("failures after the window = %d, want 1", got.Failures)
+	}
+}
+
+func TestThrottleLockout(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryAttemptStore()
+	throttle := NewThrottle(store)
+	for range throttle.Username.LockoutAfter {
+		store.RecordFailure(ctx, usernameKey("alice"), now, throttle.Window)
+	}
+
+	wait, err := throttle.Reserve(ctx, "alice", "203.0.113.7", now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if want := 14 * time.Minute; wait != want {
+		t.Errorf("Reserve() = %v, want %v", wait, want)
+	}
+	if got, _ := store.Get(ctx, ipKey("203.0.113.7")); got.Failures != 0 {
+		t.Errorf("throttled attempt counted against the IP: %d failures", got.Failures)
+	}
+}
+
+func TestThrottleSucceed(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryAttemptStore()
+	throttle := NewThrottle(store)
+	for range 3 {
+		throttle.Reserve(ctx, "alice", "203.0.113.7", now)
+	}
+
+	if _, err := throttle.Reserve(ctx, "alice", "203.0.113.7", now.Add(time.Second)); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if err := throttle.Succeed(ctx, "alice", "203.0.113.7"); err != nil {
+		t.Fatalf("Succeed() error = %v", err)
+	}
+
+	if got, _ := store.Get(ctx, usernameKey("alice")); got.Failures != 0 {
+		t.Errorf("username failures after success = %d, want 0", got.Failures)
+	}
+	// Only the successful attempt's reservation is handed back
+	if got, _ := store.Get(ctx, ipKey("203.0.113.7")); got.Failures != 3 {
+		t.Errorf("IP failures after success = %d, want 3", got.Failures)
+	}
+}
+
+// racingStore lets another login record a failure between Reserve reading
+// the count and recording its own
+type racingStore struct {
+	*MemoryAttemptStore
+	raced bool
+}
+
+func (s *racingStore) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (Attempts, error) {
+	if !s.raced {
+		s.raced = true
+		s.MemoryAttemptStore.RecordFailure(ctx, key, now, window)
+	}
+	return s.MemoryAttemptStore.RecordFailure(ctx, key, now, window)
+}
+
+func TestThrottleReserveRace(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	store := &racingStore{MemoryAttemptStore: NewMemoryAttemptStore()}
+	throttle := NewThrottle(store)
+	for range 3 {
+		store.MemoryAttemptStore.RecordFailure(ctx, usernameKey("alice"), now.Add(-time.Minute), throttle.Window)
+	}
+
+	wait, err := throttle.Reserve(ctx, "alice", "203.0.113.7", now)
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if want := 2 * time.Second; wait != want {
+		t.Errorf("Reserve() = %v, want %v", wait, want)
+	}
+	// The losing request's reservations are released, the winner's stays
+	if got, _ := store.Get(ctx, usernameKey("alice")); got.Failures != 4 {
+		t.Errorf("username failures = %d, want 4", got.Failures)
+	}
+	if got, _ := store.Get(ctx, ipKey("203.0.113.7")); got.Failures != 0 {
+		t.Errorf("IP failures = %d, want 0", got.Failures)
+	}
+}
+
+func TestThrottlePrune(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryAttemptStore()
+	throttle := NewThrottle(store)
+	store.RecordFailure(ctx, usernameKey("made-up"), now.Add(-2*time.Hour), throttle.Window)
+	store.RecordFailure(ctx, usernameKey("alice"), now.Add(-time.Minute), throttle.Window)
+
+	if err := throttle.Prune(ctx, now); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if _, ok := store.attempts[usernameKey("made-up")]; ok {
+		t.Error("Prune() kept a counter older than the window")
+	}
+	if _, ok := store.attempts[usernameKey("alice")]; !ok {
+		t.Error("Prune() dropped a counter inside the window")
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{value: "", want: nil},
+		{value: "10.0.0.0/8", want: []string{"10.0.0.0/8"}},
+		{value: " 10.0.0.0/8, 192.168.1.1 ,, ::1", want: []string{"10.0.0.0/8", "192.168.1.1/32", "::1/128"}},
+		{value: "proxy.internal", wantErr: true},
+		{value: "10.0.0.0/33", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			proxies, err := ParseTrustedProxies(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrustedProxies() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(proxies) != len(tt.want) {
+				t.Fatalf("ParseTrustedProxies() = %v, want %v", proxies, tt.want)
+			}
+			for i, network := range proxies {
+				if network.String() != tt.want[i] {
+					t.Errorf("proxy %d = %v, want %v", i, network, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		trusted    bool
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{name: "no proxies configured", remoteAddr: "10.0.0.5:4000", forwarded: []string{"203.0.113.7"}, want: "10.0.0.5"},
+		{name: "untrusted peer", trusted: true, remoteAddr: "198.51.100.2:4000", forwarded: []string{"203.0.113.7"}, want: "198.51.100.2"},
+		{name: "trusted proxy", trusted: true, remoteAddr: "10.0.0.5:4000", forwarded: []string{"203.0.113.7"}, want: "203.0.113.7"},
+		{name: "chained proxies", trusted: true, remoteAddr: "10.0.0.5:4000", forwarded: []string{"203.0.113.7, 10.0.0.9"}, want: "203.0.113.7"},
+		{name: "spoofed left entries", trusted: true, remoteAddr: "10.0.0.5:4000", forwarded: []string{"1.2.3.4, 203.0.113.7"}, want: "203.0.113.7"},
+		{name: "repeated headers", trusted: true, remoteAddr: "10.0.0.5:4000", forwarded: []string{"1.2.3.4", "203.0.113.7"}, want: "203.0.113.7"},
+		{name: "no header", trusted: true, remoteAddr: "10.0.0.5:4000", want: "10.0.0.5"},
+		{name: "garbage header", trusted: true, remoteAddr: "10.0.0.5:4000", forwarded: []string{"unknown"}, want: "10.0.0.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttle := NewThrottle(NewMemoryAttemptStore())
+			if tt.trusted {
+				throttle.TrustedProxies = proxies
+			}
+			r := httptest.NewRequest("POST", "/auth/login", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if got := throttle.ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Package common holds the small request and database helpers every
+// handler package needs
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ParseIDParam reads a positive int64 path wildcard such as {id}
+func ParseIDParam(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return id, nil
+}
+
+// IsUniqueViolation reports whether err is a Postgres unique constraint
+// violation
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// NumericToFloat64 reads NULL as zero
+func NumericToFloat64(value pgtype.Numeric) float64 {
+	f, err := value.Float64Value()
+	if err != nil || !f.Valid {
+		return 0
+	}
+	return f.Float64
+}
+
+// OptionalNumeric stores zero as NULL, i.e. "not set". Values keep two
+// decimals, the widest scale of any column; columns with one decimal are
+// rounded by Postgres on insert.
+func OptionalNumeric(value float64) pgtype.Numeric {
+	if value == 0 {
+		return pgtype.Numeric{Valid: false}
+	}
+	var n pgtype.Numeric
+	if err := n.Scan(strconv.FormatFloat(value, 'f', 2, 64)); err != nil {
+		return pgtype.Numeric{Valid: false}
+	}
+	return n
+}
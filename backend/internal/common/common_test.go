@@ -0,0 +1,26 @@
+package common
+
+import "testing"
+
+func TestOptionalNumeric(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  float64
+		valid bool
+	}{
+		{value: 0},
+		{value: 72.25, want: 72.25, valid: true},
+		{value: 0.29, want: 0.29, valid: true},
+		{value: 101.456, want: 101.46, valid: true},
+	}
+	for _, tt := range tests {
+		n := OptionalNumeric(tt.value)
+		if n.Valid != tt.valid {
+			t.Errorf("OptionalNumeric(%v).Valid = %v, want %v", tt.value, n.Valid, tt.valid)
+			continue
+		}
+		if got := NumericToFloat64(n); got != tt.want {
+			t.Errorf("OptionalNumeric(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
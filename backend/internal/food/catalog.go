@@ -0,0 +1,326 @@
+package food
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+func toFoodItem(food db.Food) FoodItem {
+	return FoodItem{
+		FoodID:      food.FoodID,
+		UserID:      food.UserID,
+		FoodName:    food.FoodName,
+		Calories100: food.Calories100,
+		Protein100:  food.Protein100,
+		Carbs100:    food.Carbs100,
+		Fats100:     food.Fats100,
+		CreatedAt:   food.CreatedAt.Time,
+		LastUpdated: food.LastUpdated.Time,
+	}
+}
+
+// validateFoodItem trims the name in place and returns a client-facing message
+// when the item cannot be saved
+func validateFoodItem(name *string, calories100, protein100, carbs100, fats100 float64) string {
+	*name = strings.TrimSpace(*name)
+	if *name == "" {
+		return "food_name is required"
+	}
+	if calories100 < 0 || protein100 < 0 || carbs100 < 0 || fats100 < 0 {
+		return "macros cannot be negative"
+	}
+	if protein100+carbs100+fats100 > 100 {
+		return "protein, carbs and fats cannot exceed 100g per 100g"
+	}
+	return ""
+}
+
+// parsePagination reads ?limit= and ?offset=, applying defaults and the page size cap
+func parsePagination(query url.Values) (int32, int32, error) {
+	limit := int64(defaultPageLimit)
+	offset := int64(0)
+	var err error
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err = strconv.ParseInt(limitStr, 10, 32)
+		if err != nil || limit <= 0 {
+			return 0, 0, fmt.Errorf("'limit' must be a positive integer")
+		}
+		if limit > maxPageLimit {
+			limit = maxPageLimit
+		}
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err = strconv.ParseInt(offsetStr, 10, 32)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("'offset' must be a non-negative integer")
+		}
+	}
+	return int32(limit), int32(offset), nil
+}
+
+func (h *FoodHandler) ListFoodItemsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+	limit, offset, err := parsePagination(query)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ListFoodItemsResponse{
+			Message: err.Error(),
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ListFoodItemsResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+	search := strings.TrimSpace(query.Get("q"))
+
+	foods, err := h.queries.ListFoodItems(r.Context(), db.ListFoodItemsParams{
+		UserID:     userID,
+		Search:     search,
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ListFoodItemsResponse{
+			Message: fmt.Sprintf("Failed to fetch food items: %v", err),
+			Success: false,
+		})
+		return
+	}
+	total, err := h.queries.CountFoodItems(r.Context(), db.CountFoodItemsParams{
+		UserID: userID,
+		Search: search,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ListFoodItemsResponse{
+			Message: fmt.Sprintf("Failed to count food items: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	items := make([]FoodItem, 0, len(foods))
+	for _, food := range foods {
+		items = append(items, toFoodItem(food))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ListFoodItemsResponse{
+		Message: "Food items retrieved successfully",
+		Success: true,
+		Foods:   items,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
+
+func (h *FoodHandler) GetFoodItemHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	foodID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: "Invalid food id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	food, err := h.queries.GetFoodItem(r.Context(), db.GetFoodItemParams{
+		FoodID: foodID,
+		UserID: userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: "Food item not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: fmt.Sprintf("Failed to fetch food item: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	item := toFoodItem(food)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(FoodItemResponse{
+		Message: "Food item retrieved successfully",
+		Success: true,
+		Food:    &item,
+	})
+}
+
+func (h *FoodHandler) UpdateFoodItemHandler(w http.ResponseWriter, r *http.Request) {
+	var request UpdateFoodItemRequest
+	w.Header().Set("Content-Type", "application/json")
+	foodID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: "Invalid food id",
+			Success: false,
+		})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+	if message := validateFoodItem(&request.FoodName, request.Calories100, request.Protein100, request.Carbs100, request.Fats100); message != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: message,
+			Success: false,
+		})
+		return
+	}
+
+	food, err := h.queries.UpdateFoodItem(r.Context(), db.UpdateFoodItemParams{
+		FoodID:      foodID,
+		UserID:      userID,
+		FoodName:    request.FoodName,
+		Calories100: request.Calories100,
+		Protein100:  request.Protein100,
+		Carbs100:    request.Carbs100,
+		Fats100:     request.Fats100,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: "Food item not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: "failed to update food item",
+			Success: false,
+		})
+		return
+	}
+
+	item := toFoodItem(food)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(FoodItemResponse{
+		Message: "Food item updated",
+		Success: true,
+		Food:    &item,
+	})
+}
+
+func (h *FoodHandler) DeleteFoodItemHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	foodID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: "Invalid food id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	deleted, err := h.queries.DeleteFoodItem(r.Context(), db.DeleteFoodItemParams{
+		FoodID: foodID,
+		UserID: userID,
+	})
+	if isForeignKeyViolation(err) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: "Food item is used by logged entries or recipes and cannot be deleted",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: "failed to delete food item",
+			Success: false,
+		})
+		return
+	}
+	if deleted == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(FoodItemResponse{
+			Message: "Food item not found",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(FoodItemResponse{
+		Message: "Food item deleted",
+		Success: true,
+	})
+}
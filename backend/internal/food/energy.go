@@ -0,0 +1,258 @@
+package food
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	GoalCut      = "cut"
+	GoalMaintain = "maintain"
+	GoalBulk     = "bulk"
+)
+
+var activityMultipliers = map[string]float64{
+	"sedentary":   1.2,
+	"light":       1.375,
+	"moderate":    1.55,
+	"active":      1.725,
+	"very_active": 1.9,
+}
+
+// goalCalories scales maintenance calories for each goal; goalProtein is
+// grams of protein per kg of bodyweight
+var (
+	goalCalories = map[string]float64{GoalCut: 0.80, GoalMaintain: 1.00, GoalBulk: 1.10}
+	goalProtein  = map[string]float64{GoalCut: 2.2, GoalMaintain: 1.8, GoalBulk: 1.8}
+)
+
+const (
+	// kcalPerKg is the energy in a kilogram of bodyweight change
+	kcalPerKg = 7700
+
+	// The adaptive estimate needs this much data inside adaptiveWindowDays
+	adaptiveWindowDays  = 28
+	minIntakeDays       = 14
+	minWeightSpanInDays = 14
+
+	fatShareOfCalories = 0.25
+)
+
+// ageOn returns completed years between birth and now
+func ageOn(birth, now time.Time) int {
+	age := now.Year() - birth.Year()
+	if now.Month() < birth.Month() || (now.Month() == birth.Month() && now.Day() < birth.Day()) {
+		age--
+	}
+	return age
+}
+
+// mifflinStJeor takes kg, cm and years
+func mifflinStJeor(weight, height float64, age int, sex string) float64 {
+	bmr := 10*weight + 6.25*height - 5*float64(age)
+	if sex == "male" {
+		return bmr + 5
+	}
+	return bmr - 161
+}
+
+// katchMcArdle works from lean mass, so it needs a body fat percentage
+func katchMcArdle(weight, bodyFat float64) float64 {
+	return 370 + 21.6*weight*(1-bodyFat/100)
+}
+
+// weightSlope fits a least-squares line through the readings and returns
+// its slope in kg per day
+func weightSlope(readings []db.ListBodyweightReadingsRow) float64 {
+	if len(readings) < 2 {
+		return 0
+	}
+	start := readings[0].MeasuredAt.Time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, reading := range readings {
+		x := reading.MeasuredAt.Time.Sub(start).Hours() / 24
+		sumX += x
+		sumY += reading.Weight
+		sumXY += x * reading.Weight
+		sumXX += x * x
+	}
+	n := float64(len(readings))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// macroSplit sets protein from bodyweight, fat as a fixed share of calories
+// and gives carbs the remainder. Without a bodyweight protein falls back to
+// a quarter of calories.
+func macroSplit(calories, weight float64, goal string) Macros {
+	protein := calories * 0.25 / 4
+	if weight > 0 {
+		protein = weight * goalProtein[goal]
+	}
+	fats := calories * fatShareOfCalories / 9
+	carbs := math.Max(0, (calories-protein*4-fats*9)/4)
+	return Macros{
+		Calories: math.Round(calories),
+		Protein:  math.Round(protein),
+		Carbs:    math.Round(carbs),
+		Fats:     math.Round(fats),
+	}
+}
+
+// RecommendationHandler estimates maintenance calories and suggests targets
+// for ?goal= (cut, maintain, bulk) at ?activity= (sedentary through
+// very_active). The adaptive TDEE from logged intake and bodyweight trend is
+// preferred when there is enough data; otherwise the profile-based formulas
+// are used, Katch-McArdle when body fat is known.
+func (h *FoodHandler) RecommendationHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+
+	goal := query.Get("goal")
+	if goal == "" {
+		goal = GoalMaintain
+	}
+	if _, ok := goalCalories[goal]; !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RecommendationResponse{
+			Message: "'goal' must be one of cut, maintain, bulk",
+			Success: false,
+		})
+		return
+	}
+	activity := query.Get("activity")
+	if activity == "" {
+		activity = "moderate"
+	}
+	multiplier, ok := activityMultipliers[activity]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RecommendationResponse{
+			Message: "'activity' must be one of sedentary, light, moderate, active, very_active",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(RecommendationResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	profile, err := h.queries.GetUserProfile(r.Context(), userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecommendationResponse{
+			Message: fmt.Sprintf("Failed to fetch profile: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	now := time.Now().UTC()
+	windowStart := now.Truncate(24*time.Hour).AddDate(0, 0, -adaptiveWindowDays)
+	intake, err := h.queries.DailyFoodTotals(r.Context(), db.DailyFoodTotalsParams{
+		UserID:   userID,
+		DateFrom: timeToPgTimestamp(windowStart),
+		// Today is still being logged, so it would drag the average down
+		DateTo: timeToPgTimestamp(now.Truncate(24 * time.Hour)),
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecommendationResponse{
+			Message: fmt.Sprintf("Failed to fetch intake: %v", err),
+			Success: false,
+		})
+		return
+	}
+	readings, err := h.queries.ListBodyweightReadings(r.Context(), db.ListBodyweightReadingsParams{
+		UserID:   userID,
+		DateFrom: timeToPgTimestamp(windowStart),
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecommendationResponse{
+			Message: fmt.Sprintf("Failed to fetch bodyweight: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	response := RecommendationResponse{
+		Message:  "Recommendation calculated",
+		Success:  true,
+		Goal:     goal,
+		Activity: activity,
+	}
+
+	weight := common.NumericToFloat64(profile.Weight)
+	if len(readings) > 0 {
+		weight = readings[len(readings)-1].Weight
+	}
+	height := common.NumericToFloat64(profile.Height)
+	bodyFat := common.NumericToFloat64(profile.BodyFat)
+	if weight > 0 && height > 0 && profile.DateOfBirth.Valid && profile.Sex.Valid {
+		response.BMR.MifflinStJeor = math.Round(mifflinStJeor(weight, height, ageOn(profile.DateOfBirth.Time, now), profile.Sex.String))
+		response.TDEE.MifflinStJeor = math.Round(response.BMR.MifflinStJeor * multiplier)
+	}
+	if weight > 0 && bodyFat > 0 {
+		response.BMR.KatchMcArdle = math.Round(katchMcArdle(weight, bodyFat))
+		response.TDEE.KatchMcArdle = math.Round(response.BMR.KatchMcArdle * multiplier)
+	}
+
+	if len(intake) >= minIntakeDays && len(readings) >= 2 &&
+		readings[len(readings)-1].MeasuredAt.Time.Sub(readings[0].MeasuredAt.Time) >= minWeightSpanInDays*24*time.Hour {
+		var total float64
+		for _, day := range intake {
+			total += day.Calories
+		}
+		averageIntake := total / float64(len(intake))
+		slope := weightSlope(readings)
+		response.TDEE.Adaptive = math.Round(averageIntake - slope*kcalPerKg)
+		response.Adaptive = &AdaptiveDetails{
+			IntakeDays:          len(intake),
+			AverageIntake:       math.Round(averageIntake),
+			WeightReadings:      len(readings),
+			WeightChangePerWeek: math.Round(slope*7*100) / 100,
+		}
+	}
+
+	switch {
+	case response.TDEE.Adaptive > 0:
+		response.TDEE.Used, response.TDEE.Source = response.TDEE.Adaptive, "adaptive"
+	case response.TDEE.KatchMcArdle > 0:
+		response.TDEE.Used, response.TDEE.Source = response.TDEE.KatchMcArdle, "katch_mcardle"
+	case response.TDEE.MifflinStJeor > 0:
+		response.TDEE.Used, response.TDEE.Source = response.TDEE.MifflinStJeor, "mifflin_st_jeor"
+	default:
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(RecommendationResponse{
+			Message: fmt.Sprintf("Not enough data: set weight, height, date of birth and sex in your profile, or log food and bodyweight for at least %d days", minIntakeDays),
+			Success: false,
+		})
+		return
+	}
+
+	target := macroSplit(response.TDEE.Used*goalCalories[goal], weight, goal)
+	response.Target = &target
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,104 @@
+package food
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestAgeOn(t *testing.T) {
+	tests := []struct {
+		name  string
+		birth time.Time
+		now   time.Time
+		want  int
+	}{
+		{name: "day before birthday", birth: date(1990, 6, 15), now: date(2026, 6, 14), want: 35},
+		{name: "on birthday", birth: date(1990, 6, 15), now: date(2026, 6, 15), want: 36},
+		{name: "earlier month", birth: date(1990, 6, 15), now: date(2026, 5, 30), want: 35},
+		{name: "leap day before birthday", birth: date(2000, 2, 29), now: date(2026, 2, 28), want: 25},
+		{name: "leap day after birthday", birth: date(2000, 2, 29), now: date(2026, 3, 1), want: 26},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ageOn(tt.birth, tt.now); got != tt.want {
+				t.Errorf("ageOn() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBMRFormulas(t *testing.T) {
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{name: "Mifflin-St Jeor male", got: mifflinStJeor(80, 180, 30, "male"), want: 1780},
+		{name: "Mifflin-St Jeor female", got: mifflinStJeor(60, 165, 25, "female"), want: 1345.25},
+		{name: "Katch-McArdle", got: katchMcArdle(80, 20), want: 1752.4},
+		{name: "Katch-McArdle without fat", got: katchMcArdle(70, 0), want: 1882},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if math.Abs(tt.got-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeightSlope(t *testing.T) {
+	reading := func(day int, weight float64) db.ListBodyweightReadingsRow {
+		at := date(2026, 3, 1).AddDate(0, 0, day)
+		return db.ListBodyweightReadingsRow{MeasuredAt: pgtype.Timestamp{Time: at, Valid: true}, Weight: weight}
+	}
+
+	tests := []struct {
+		name     string
+		readings []db.ListBodyweightReadingsRow
+		want     float64
+	}{
+		{name: "no readings", want: 0},
+		{name: "single reading", readings: []db.ListBodyweightReadingsRow{reading(0, 80)}, want: 0},
+		{name: "steady loss", readings: []db.ListBodyweightReadingsRow{reading(0, 80), reading(7, 79.3), reading(14, 78.6)}, want: -0.1},
+		{name: "irregular gain", readings: []db.ListBodyweightReadingsRow{reading(0, 70), reading(2, 70.4), reading(10, 72)}, want: 0.2},
+		// Readings at the same moment leave a zero denominator
+		{name: "same timestamp", readings: []db.ListBodyweightReadingsRow{reading(3, 80), reading(3, 81)}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weightSlope(tt.readings); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("weightSlope() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMacroSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		calories float64
+		weight   float64
+		goal     string
+		want     Macros
+	}{
+		{name: "protein from bodyweight", calories: 2000, weight: 80, goal: GoalCut, want: Macros{Calories: 2000, Protein: 176, Carbs: 199, Fats: 56}},
+		{name: "no bodyweight", calories: 2000, goal: GoalMaintain, want: Macros{Calories: 2000, Protein: 125, Carbs: 250, Fats: 56}},
+		{name: "carbs never negative", calories: 800, weight: 120, goal: GoalCut, want: Macros{Calories: 800, Protein: 264, Carbs: 0, Fats: 22}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := macroSplit(tt.calories, tt.weight, tt.goal); got != tt.want {
+				t.Errorf("macroSplit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,166 @@
+package food
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+)
+
+// entryMacros scales the macros stored on an entry to a new weight. The
+// food, recipe or recent food it was logged from may have been edited since,
+// so the entry's own values are used and past days stay as they were logged.
+func entryMacros(entry db.FoodEntry, grams float64) Macros {
+	logged := Macros{Calories: entry.Calories, Protein: entry.Protein, Carbs: entry.Carbs, Fats: entry.Fats}
+	return logged.scale(grams / entry.TotalGrams)
+}
+
+func (h *FoodHandler) UpdateFoodEntryHandler(w http.ResponseWriter, r *http.Request) {
+	var request UpdateFoodEntryRequest
+	w.Header().Set("Content-Type", "application/json")
+	entryID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(FoodEntryResponse{
+			Message: "Invalid entry id",
+			Success: false,
+		})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(FoodEntryResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	if request.TotalGrams <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(FoodEntryResponse{
+			Message: "total_grams must be positive",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(FoodEntryResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	entry, err := h.queries.GetFoodEntry(r.Context(), db.GetFoodEntryParams{
+		NutritionID: entryID,
+		UserID:      userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(FoodEntryResponse{
+			Message: "Food entry not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(FoodEntryResponse{
+			Message: "failed to load food entry",
+			Success: false,
+		})
+		return
+	}
+
+	if entry.TotalGrams <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(FoodEntryResponse{
+			Message: "Food entry has no logged weight to scale",
+			Success: false,
+		})
+		return
+	}
+	macros := entryMacros(entry, request.TotalGrams)
+
+	updated, err := h.queries.UpdateFoodEntry(r.Context(), db.UpdateFoodEntryParams{
+		NutritionID: entryID,
+		UserID:      userID,
+		TotalGrams:  request.TotalGrams,
+		Calories:    macros.Calories,
+		Protein:     macros.Protein,
+		Carbs:       macros.Carbs,
+		Fats:        macros.Fats,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(FoodEntryResponse{
+			Message: "failed to update food entry",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(FoodEntryResponse{
+		Message: "Food entry updated",
+		Success: true,
+		Entry:   &updated,
+	})
+}
+
+func (h *FoodHandler) DeleteFoodEntryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	entryID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(FoodEntryResponse{
+			Message: "Invalid entry id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(FoodEntryResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	deleted, err := h.queries.DeleteFoodEntry(r.Context(), db.DeleteFoodEntryParams{
+		NutritionID: entryID,
+		UserID:      userID,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(FoodEntryResponse{
+			Message: "failed to delete food entry",
+			Success: false,
+		})
+		return
+	}
+	if deleted == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(FoodEntryResponse{
+			Message: "Food entry not found",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(FoodEntryResponse{
+		Message: "Food entry deleted",
+		Success: true,
+	})
+}
@@ -2,21 +2,28 @@ package food
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Bughay/Trainer-GO/db"
 	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type FoodHandler struct {
+	pool    *pgxpool.Pool
 	queries *db.Queries
 }
 
-func NewFoodHandler(q *db.Queries) *FoodHandler {
+func NewFoodHandler(q *db.Queries, pool *pgxpool.Pool) *FoodHandler {
 	return &FoodHandler{
+		pool:    pool,
 		queries: q,
 	}
 }
@@ -33,6 +40,13 @@ func timeToPgTimestamp(t time.Time) pgtype.Timestamp {
 	}
 }
 
+// isForeignKeyViolation reports whether err is a postgres foreign_key_violation,
+// i.e. the row is still referenced by another table
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
 func parseDate(dateStr string) (time.Time, error) {
 	if dateStr == "" {
 		// Default to today
@@ -67,7 +81,7 @@ func (h *FoodHandler) CreateFoodItemHandler(w http.ResponseWriter, r *http.Reque
 		})
 		return
 	}
-	userID, ok := r.Context().Value(auth.UserIDKey).(int64)
+	principal, ok := auth.PrincipalFromContext(r.Context())
 	if !ok {
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(CreateFoodItemResponse{
@@ -76,6 +90,15 @@ func (h *FoodHandler) CreateFoodItemHandler(w http.ResponseWriter, r *http.Reque
 		})
 		return
 	}
+	userID := principal.UserID
+	if message := validateFoodItem(&request.FoodName, request.Calories100, request.Protein100, request.Carbs100, request.Fats100); message != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(CreateFoodItemResponse{
+			Message: message,
+			Success: false,
+		})
+		return
+	}
 	params := db.CreateFoodItemParams{
 		UserID:      userID,
 		FoodName:    request.FoodName,
@@ -97,14 +120,7 @@ func (h *FoodHandler) CreateFoodItemHandler(w http.ResponseWriter, r *http.Reque
 	response := CreateFoodItemResponse{
 		Message: "Food item created",
 		Success: true,
-		Food: FoodItem{
-			UserID:      foodItem.UserID,
-			FoodName:    foodItem.FoodName,
-			Calories100: foodItem.Calories100,
-			Protein100:  foodItem.Protein100,
-			Carbs100:    foodItem.Carbs100,
-			Fats100:     foodItem.Fats100,
-		},
+		Food:    toFoodItem(foodItem),
 	}
 	w.WriteHeader(http.StatusCreated)
 
@@ -122,7 +138,7 @@ func (h *FoodHandler) LogFoodHandler(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	userID, ok := r.Context().Value(auth.UserIDKey).(int64)
+	principal, ok := auth.PrincipalFromContext(r.Context())
 	if !ok {
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(CreateFoodItemResponse{
@@ -131,28 +147,194 @@ func (h *FoodHandler) LogFoodHandler(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	foodCacheParams := db.CreateFoodCacheItemParams{
-		UserID:      userID,
-		FoodName:    request.FoodName,
-		Calories100: (request.Calories / request.TotalGrams),
-		Protein100:  (request.Protein / request.TotalGrams),
-		Carbs100:    (request.Carbs / request.TotalGrams),
-		Fats100:     (request.Fats / request.TotalGrams),
-	}
-	logfoodCache, err := h.queries.CreateFoodCacheItem(r.Context(), foodCacheParams)
-
-	logFoodParams := db.LogFoodItemParams{
-		UserID:     userID,
-		FoodID:     int64ToPgInt8(logfoodCache.FoodID, true),
-		RecipeID:   int64ToPgInt8(0, false),
-		Calories:   request.Calories,
-		TotalGrams: request.TotalGrams,
-		Protein:    request.Protein,
-		Carbs:      request.Carbs,
-		Fats:       request.Fats,
-	}
-	logfood, err := h.queries.LogFoodItem(r.Context(), logFoodParams)
-	fmt.Println(logfood)
+	userID := principal.UserID
+	if request.TotalGrams <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(LogFoodItemResponse{
+			Message: "total_grams must be positive",
+			Success: false,
+		})
+		return
+	}
+
+	sources := 0
+	for _, id := range []int64{request.FoodID, request.RecipeID, request.CacheID} {
+		if id != 0 {
+			sources++
+		}
+	}
+	if sources > 1 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(LogFoodItemResponse{
+			Message: "provide only one of food_id, recipe_id or cache_id",
+			Success: false,
+		})
+		return
+	}
+
+	// Cache writes and the entry itself are saved together so a failed log
+	// doesn't leave a stray cache row or bump its last-used time
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(LogFoodItemResponse{
+			Message: "failed to log food item",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	var logFoodParams db.LogFoodItemParams
+	if request.FoodID != 0 {
+		// Catalog items store per-100g macros, so only the grams are needed
+		foodItem, err := h.queries.GetFoodItem(r.Context(), db.GetFoodItemParams{
+			FoodID: request.FoodID,
+			UserID: userID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(LogFoodItemResponse{
+				Message: "Food item not found",
+				Success: false,
+			})
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(LogFoodItemResponse{
+				Message: "failed to load food item",
+				Success: false,
+			})
+			return
+		}
+		macros := macrosFor(request.TotalGrams, foodItem.Calories100, foodItem.Protein100, foodItem.Carbs100, foodItem.Fats100)
+		logFoodParams = db.LogFoodItemParams{
+			UserID:     userID,
+			FoodID:     int64ToPgInt8(foodItem.FoodID, true),
+			RecipeID:   int64ToPgInt8(0, false),
+			CacheID:    int64ToPgInt8(0, false),
+			Calories:   macros.Calories,
+			TotalGrams: request.TotalGrams,
+			Protein:    macros.Protein,
+			Carbs:      macros.Carbs,
+			Fats:       macros.Fats,
+		}
+	} else if request.RecipeID != 0 {
+		// Recipes are logged by weight: the entry gets the recipe's per-100g
+		// macros scaled to the grams eaten
+		recipe, err := h.loadRecipe(r.Context(), userID, request.RecipeID)
+		if errors.Is(err, errRecipeNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(LogFoodItemResponse{
+				Message: "Recipe not found",
+				Success: false,
+			})
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(LogFoodItemResponse{
+				Message: "failed to load recipe",
+				Success: false,
+			})
+			return
+		}
+		macros := recipe.Per100g.scale(request.TotalGrams / 100)
+		logFoodParams = db.LogFoodItemParams{
+			UserID:     userID,
+			FoodID:     int64ToPgInt8(0, false),
+			RecipeID:   int64ToPgInt8(recipe.RecipeID, true),
+			CacheID:    int64ToPgInt8(0, false),
+			Calories:   macros.Calories,
+			TotalGrams: request.TotalGrams,
+			Protein:    macros.Protein,
+			Carbs:      macros.Carbs,
+			Fats:       macros.Fats,
+		}
+	} else if request.CacheID != 0 {
+		// One-tap re-log of a recent food
+		cached, err := qtx.GetFoodCacheItem(r.Context(), db.GetFoodCacheItemParams{
+			FoodID: request.CacheID,
+			UserID: userID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(LogFoodItemResponse{
+				Message: "Recent food not found",
+				Success: false,
+			})
+			return
+		}
+		if err == nil {
+			err = qtx.TouchFoodCacheItem(r.Context(), cached.FoodID)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(LogFoodItemResponse{
+				Message: "failed to load recent food",
+				Success: false,
+			})
+			return
+		}
+		macros := macrosFor(request.TotalGrams, cached.Calories100, cached.Protein100, cached.Carbs100, cached.Fats100)
+		logFoodParams = db.LogFoodItemParams{
+			UserID:     userID,
+			FoodID:     int64ToPgInt8(0, false),
+			RecipeID:   int64ToPgInt8(0, false),
+			CacheID:    int64ToPgInt8(cached.FoodID, true),
+			Calories:   macros.Calories,
+			TotalGrams: request.TotalGrams,
+			Protein:    macros.Protein,
+			Carbs:      macros.Carbs,
+			Fats:       macros.Fats,
+		}
+	} else {
+		normalizedName := normalizeFoodName(request.FoodName)
+		if normalizedName == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(LogFoodItemResponse{
+				Message: "food_name is required",
+				Success: false,
+			})
+			return
+		}
+		foodCacheParams := db.UpsertFoodCacheItemParams{
+			UserID:         userID,
+			FoodName:       strings.TrimSpace(request.FoodName),
+			NormalizedName: normalizedName,
+			Calories100:    per100(request.Calories, request.TotalGrams),
+			Protein100:     per100(request.Protein, request.TotalGrams),
+			Carbs100:       per100(request.Carbs, request.TotalGrams),
+			Fats100:        per100(request.Fats, request.TotalGrams),
+		}
+		logfoodCache, err := qtx.UpsertFoodCacheItem(r.Context(), foodCacheParams)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(LogFoodItemResponse{
+				Message: "failed to log food item",
+				Success: false,
+			})
+			return
+		}
+
+		logFoodParams = db.LogFoodItemParams{
+			UserID:     userID,
+			FoodID:     int64ToPgInt8(0, false),
+			RecipeID:   int64ToPgInt8(0, false),
+			CacheID:    int64ToPgInt8(logfoodCache.FoodID, true),
+			Calories:   request.Calories,
+			TotalGrams: request.TotalGrams,
+			Protein:    request.Protein,
+			Carbs:      request.Carbs,
+			Fats:       request.Fats,
+		}
+	}
+	logfood, err := qtx.LogFoodItem(r.Context(), logFoodParams)
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(CreateFoodItemResponse{
@@ -165,6 +347,7 @@ func (h *FoodHandler) LogFoodHandler(w http.ResponseWriter, r *http.Request) {
 	response := LogFoodItemResponse{
 		Message: "success",
 		Success: true,
+		Entry:   &logfood,
 	}
 	w.WriteHeader(http.StatusCreated)
 
@@ -223,7 +406,7 @@ func (h *FoodHandler) ViewFoodHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID, ok := r.Context().Value(auth.UserIDKey).(int64)
+	principal, ok := auth.PrincipalFromContext(r.Context())
 	if !ok {
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(CreateFoodItemResponse{
@@ -232,6 +415,7 @@ func (h *FoodHandler) ViewFoodHandler(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	userID := principal.SubjectID()
 	viewFoodParams := db.ViewFoodParams{
 		UserID:      userID,
 		CreatedAt:   timeToPgTimestamp(dateFrom),
@@ -312,7 +496,7 @@ func (h *FoodHandler) ViewFoodTotalHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	userID, ok := r.Context().Value(auth.UserIDKey).(int64)
+	principal, ok := auth.PrincipalFromContext(r.Context())
 	if !ok {
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(ViewFoodTotalResponse{
@@ -321,6 +505,7 @@ func (h *FoodHandler) ViewFoodTotalHandler(w http.ResponseWriter, r *http.Reques
 		})
 		return
 	}
+	userID := principal.SubjectID()
 
 	viewFoodTotalParams := db.ViewFoodTotalParams{
 		UserID:      userID,
@@ -0,0 +1,482 @@
+package food
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const maxCycleDays = 28
+
+var errFoodNotFound = errors.New("food item not found")
+
+// validateMealPlan returns a client-facing message when the plan cannot be
+// saved
+func validateMealPlan(request *CreateMealPlanRequest) string {
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		return "name is required"
+	}
+	if request.CycleDays < 1 || request.CycleDays > maxCycleDays {
+		return fmt.Sprintf("cycle_days must be between 1 and %d", maxCycleDays)
+	}
+	if len(request.Days) == 0 {
+		return "a meal plan needs at least one day"
+	}
+	seen := make(map[int]bool, len(request.Days))
+	for _, day := range request.Days {
+		if day.Day < 1 || day.Day > request.CycleDays {
+			return fmt.Sprintf("day must be between 1 and %d", request.CycleDays)
+		}
+		if seen[day.Day] {
+			return fmt.Sprintf("day %d is listed twice", day.Day)
+		}
+		seen[day.Day] = true
+		if len(day.Meals) == 0 {
+			return fmt.Sprintf("day %d has no meals", day.Day)
+		}
+		for _, meal := range day.Meals {
+			if strings.TrimSpace(meal.Name) == "" {
+				return "every meal needs a name"
+			}
+			if len(meal.Items) == 0 {
+				return fmt.Sprintf("meal %q on day %d has no items", meal.Name, day.Day)
+			}
+			for _, item := range meal.Items {
+				if (item.FoodID == 0) == (item.RecipeID == 0) {
+					return "each item needs exactly one of food_id or recipe_id"
+				}
+				if item.TotalGrams <= 0 {
+					return "item total_grams must be positive"
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// planItemParams looks up an item's food or recipe in the trainer's catalog
+// and copies its name and macros for the requested grams
+func (h *FoodHandler) planItemParams(ctx context.Context, trainerID int64, item MealPlanItemRequest) (db.CreateMealPlanItemParams, error) {
+	params := db.CreateMealPlanItemParams{TotalGrams: item.TotalGrams}
+	var macros Macros
+	if item.FoodID != 0 {
+		foodItem, err := h.queries.GetFoodItem(ctx, db.GetFoodItemParams{
+			FoodID: item.FoodID,
+			UserID: trainerID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return params, errFoodNotFound
+		}
+		if err != nil {
+			return params, err
+		}
+		params.FoodID = int64ToPgInt8(foodItem.FoodID, true)
+		params.ItemName = foodItem.FoodName
+		macros = macrosFor(item.TotalGrams, foodItem.Calories100, foodItem.Protein100, foodItem.Carbs100, foodItem.Fats100)
+	} else {
+		recipe, err := h.loadRecipe(ctx, trainerID, item.RecipeID)
+		if err != nil {
+			return params, err
+		}
+		params.RecipeID = int64ToPgInt8(recipe.RecipeID, true)
+		params.ItemName = recipe.RecipeName
+		macros = recipe.Per100g.scale(item.TotalGrams / 100)
+	}
+	params.Calories, params.Protein, params.Carbs, params.Fats = macros.Calories, macros.Protein, macros.Carbs, macros.Fats
+	return params, nil
+}
+
+// groupPlanItems nests the flattened rows from ListMealPlanItems into days
+// and meals, summing macros at each level
+func groupPlanItems(rows []db.ListMealPlanItemsRow) []PlannedDay {
+	days := []PlannedDay{}
+	for _, row := range rows {
+		if len(days) == 0 || days[len(days)-1].DayNumber != row.DayNumber {
+			days = append(days, PlannedDay{DayNumber: row.DayNumber, Name: row.DayName.String, Meals: []PlannedMeal{}})
+		}
+		day := &days[len(days)-1]
+		if len(day.Meals) == 0 || day.Meals[len(day.Meals)-1].MealID != row.MealID {
+			day.Meals = append(day.Meals, PlannedMeal{MealID: row.MealID, Name: row.MealName, Items: []PlannedItem{}})
+		}
+		meal := &day.Meals[len(day.Meals)-1]
+		macros := Macros{Calories: row.Calories, Protein: row.Protein, Carbs: row.Carbs, Fats: row.Fats}
+		meal.Items = append(meal.Items, PlannedItem{
+			ItemID:     row.ItemID,
+			FoodID:     row.FoodID.Int64,
+			RecipeID:   row.RecipeID.Int64,
+			ItemName:   row.ItemName,
+			TotalGrams: row.TotalGrams,
+			Macros:     macros,
+		})
+		meal.Total = meal.Total.add(macros)
+		day.Total = day.Total.add(macros)
+	}
+	return days
+}
+
+// CreateMealPlanHandler stores a meal plan with its days, meals and items in
+// one transaction. Only trainers can author meal plans, from their own foods
+// and recipes.
+func (h *FoodHandler) CreateMealPlanHandler(w http.ResponseWriter, r *http.Request) {
+	var request CreateMealPlanRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(MealPlanResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	if message := validateMealPlan(&request); message != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(MealPlanResponse{
+			Message: message,
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(MealPlanResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	// Price every item before writing so a missing food fails cleanly
+	items := make([][][]db.CreateMealPlanItemParams, len(request.Days))
+	for d, day := range request.Days {
+		items[d] = make([][]db.CreateMealPlanItemParams, len(day.Meals))
+		for m, meal := range day.Meals {
+			for _, item := range meal.Items {
+				params, err := h.planItemParams(r.Context(), userID, item)
+				if errors.Is(err, errFoodNotFound) || errors.Is(err, errRecipeNotFound) {
+					message := "Food item not found"
+					if errors.Is(err, errRecipeNotFound) {
+						message = "Recipe not found"
+					}
+					w.WriteHeader(http.StatusNotFound)
+					json.NewEncoder(w).Encode(MealPlanResponse{
+						Message: fmt.Sprintf("%s in meal %q on day %d", message, meal.Name, day.Day),
+						Success: false,
+					})
+					return
+				}
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					json.NewEncoder(w).Encode(MealPlanResponse{
+						Message: "failed to load meal plan items",
+						Success: false,
+					})
+					return
+				}
+				items[d][m] = append(items[d][m], params)
+			}
+		}
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(MealPlanResponse{
+			Message: "failed to create meal plan",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	plan, err := qtx.CreateMealPlan(r.Context(), db.CreateMealPlanParams{
+		TrainerID:   userID,
+		Name:        request.Name,
+		Description: pgtype.Text{String: request.Description, Valid: request.Description != ""},
+		CycleDays:   int32(request.CycleDays),
+	})
+	for d, dayRequest := range request.Days {
+		if err != nil {
+			break
+		}
+		var day db.MealPlanDay
+		day, err = qtx.CreateMealPlanDay(r.Context(), db.CreateMealPlanDayParams{
+			PlanID:    plan.PlanID,
+			DayNumber: int32(dayRequest.Day),
+			Name:      pgtype.Text{String: dayRequest.Name, Valid: dayRequest.Name != ""},
+		})
+		for m, mealRequest := range dayRequest.Meals {
+			if err != nil {
+				break
+			}
+			var meal db.MealPlanMeal
+			meal, err = qtx.CreateMealPlanMeal(r.Context(), db.CreateMealPlanMealParams{
+				DayID:     day.DayID,
+				MealOrder: int32(m + 1),
+				Name:      strings.TrimSpace(mealRequest.Name),
+			})
+			for i, params := range items[d][m] {
+				if err != nil {
+					break
+				}
+				params.MealID = meal.MealID
+				params.ItemOrder = int32(i + 1)
+				_, err = qtx.CreateMealPlanItem(r.Context(), params)
+			}
+		}
+	}
+	var rows []db.ListMealPlanItemsRow
+	if err == nil {
+		rows, err = qtx.ListMealPlanItems(r.Context(), db.ListMealPlanItemsParams{PlanID: plan.PlanID})
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(MealPlanResponse{
+			Message: "failed to create meal plan",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(MealPlanResponse{
+		Message: "Meal plan created",
+		Success: true,
+		Plan:    &MealPlanDetail{MealPlan: plan, Days: groupPlanItems(rows)},
+	})
+}
+
+func (h *FoodHandler) ListMealPlansHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ListMealPlansResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	plans, err := h.queries.ListMealPlans(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ListMealPlansResponse{
+			Message: fmt.Sprintf("Failed to fetch meal plans: %v", err),
+			Success: false,
+		})
+		return
+	}
+	if plans == nil {
+		plans = []db.MealPlan{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ListMealPlansResponse{
+		Message: "Meal plans retrieved successfully",
+		Success: true,
+		Plans:   plans,
+	})
+}
+
+func (h *FoodHandler) GetMealPlanHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	planID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(MealPlanResponse{
+			Message: "Invalid meal plan id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(MealPlanResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	plan, err := h.queries.GetMealPlan(r.Context(), db.GetMealPlanParams{
+		PlanID:    planID,
+		TrainerID: userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(MealPlanResponse{
+			Message: "Meal plan not found",
+			Success: false,
+		})
+		return
+	}
+	var rows []db.ListMealPlanItemsRow
+	if err == nil {
+		rows, err = h.queries.ListMealPlanItems(r.Context(), db.ListMealPlanItemsParams{PlanID: plan.PlanID})
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(MealPlanResponse{
+			Message: fmt.Sprintf("Failed to fetch meal plan: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(MealPlanResponse{
+		Message: "Meal plan retrieved successfully",
+		Success: true,
+		Plan:    &MealPlanDetail{MealPlan: plan, Days: groupPlanItems(rows)},
+	})
+}
+
+// AssignMealPlanHandler starts one of the trainer's meal plans for a linked
+// client. Any meal plan the client was following is ended.
+func (h *FoodHandler) AssignMealPlanHandler(w http.ResponseWriter, r *http.Request) {
+	var request AssignMealPlanRequest
+	w.Header().Set("Content-Type", "application/json")
+	planID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(MealPlanAssignmentResponse{
+			Message: "Invalid meal plan id",
+			Success: false,
+		})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(MealPlanAssignmentResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	startDate := time.Now().UTC().Truncate(24 * time.Hour)
+	if request.StartDate != "" {
+		startDate, err = time.Parse("2006-01-02", request.StartDate)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(MealPlanAssignmentResponse{
+				Message: "Invalid 'start_date' format. Use YYYY-MM-DD",
+				Success: false,
+			})
+			return
+		}
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(MealPlanAssignmentResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	plan, err := h.queries.GetMealPlan(r.Context(), db.GetMealPlanParams{
+		PlanID:    planID,
+		TrainerID: userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(MealPlanAssignmentResponse{
+			Message: "Meal plan not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(MealPlanAssignmentResponse{
+			Message: "failed to load meal plan",
+			Success: false,
+		})
+		return
+	}
+	linked, err := h.queries.IsTrainerOfClient(r.Context(), db.IsTrainerOfClientParams{
+		TrainerID: userID,
+		ClientID:  request.ClientID,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(MealPlanAssignmentResponse{
+			Message: "failed to check client",
+			Success: false,
+		})
+		return
+	}
+	if !linked {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(MealPlanAssignmentResponse{
+			Message: "You can only assign meal plans to your active clients",
+			Success: false,
+		})
+		return
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(MealPlanAssignmentResponse{
+			Message: "failed to assign meal plan",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	err = qtx.EndActiveMealPlanAssignments(r.Context(), request.ClientID)
+	var assignment db.MealPlanAssignment
+	if err == nil {
+		assignment, err = qtx.CreateMealPlanAssignment(r.Context(), db.CreateMealPlanAssignmentParams{
+			PlanID:    plan.PlanID,
+			ClientID:  request.ClientID,
+			StartDate: pgtype.Date{Time: startDate, Valid: true},
+		})
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(MealPlanAssignmentResponse{
+			Message: "failed to assign meal plan",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(MealPlanAssignmentResponse{
+		Message:    "Meal plan assigned",
+		Success:    true,
+		Assignment: &assignment,
+	})
+}
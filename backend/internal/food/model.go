@@ -1,6 +1,10 @@
 package food
 
-import "github.com/Bughay/Trainer-GO/db"
+import (
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+)
 
 type CreateFoodItemRequest struct {
 	FoodName    string  `json:"food_name"`
@@ -17,6 +21,9 @@ type CreateFoodItemResponse struct {
 }
 
 type LogFoodItemRequest struct {
+	FoodID     int64   `json:"food_id,omitempty"`
+	RecipeID   int64   `json:"recipe_id,omitempty"`
+	CacheID    int64   `json:"cache_id,omitempty"`
 	FoodName   string  `json:"food_name"`
 	TotalGrams float64 `json:"total_grams"`
 	Calories   float64 `json:"calories"`
@@ -25,12 +32,24 @@ type LogFoodItemRequest struct {
 	Fats       float64 `json:"fats"`
 }
 type LogFoodItemResponse struct {
-	Message string `json:"message"`
-	Success bool   `json:"success"`
+	Message string        `json:"message"`
+	Success bool          `json:"success"`
+	Entry   *db.FoodEntry `json:"entry,omitempty"`
 }
 
 type FoodItem struct {
-	UserID      int64   `json:"user_id"`
+	FoodID      int64     `json:"food_id"`
+	UserID      int64     `json:"user_id"`
+	FoodName    string    `json:"food_name"`
+	Calories100 float64   `json:"calories_100"`
+	Protein100  float64   `json:"protein_100"`
+	Carbs100    float64   `json:"carbs_100"`
+	Fats100     float64   `json:"fats_100"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+type UpdateFoodItemRequest struct {
 	FoodName    string  `json:"food_name"`
 	Calories100 float64 `json:"calories_100"`
 	Protein100  float64 `json:"protein_100"`
@@ -38,6 +57,21 @@ type FoodItem struct {
 	Fats100     float64 `json:"fats_100"`
 }
 
+type FoodItemResponse struct {
+	Message string    `json:"message"`
+	Success bool      `json:"success"`
+	Food    *FoodItem `json:"food,omitempty"`
+}
+
+type ListFoodItemsResponse struct {
+	Message string     `json:"message"`
+	Success bool       `json:"success"`
+	Foods   []FoodItem `json:"foods"`
+	Total   int64      `json:"total"`
+	Limit   int32      `json:"limit"`
+	Offset  int32      `json:"offset"`
+}
+
 type ViewFoodRequest struct {
 	DateFrom string `json:"from"`
 	DateTo   string `json:"to"`
@@ -61,3 +95,267 @@ type ViewFoodTotalResponse struct {
 	Success bool   `json:"success"`
 	Totals  ViewFoodRow
 }
+
+type Macros struct {
+	Calories float64 `json:"calories"`
+	Protein  float64 `json:"protein"`
+	Carbs    float64 `json:"carbs"`
+	Fats     float64 `json:"fats"`
+}
+
+type RecipeIngredientRequest struct {
+	FoodID     int64   `json:"food_id"`
+	TotalGrams float64 `json:"total_grams"`
+}
+
+type CreateRecipeRequest struct {
+	RecipeName   string                    `json:"recipe_name"`
+	Instructions string                    `json:"instructions"`
+	Servings     int                       `json:"servings"`
+	Ingredients  []RecipeIngredientRequest `json:"ingredients"`
+}
+
+type UpdateRecipeRequest struct {
+	RecipeName   string                    `json:"recipe_name"`
+	Instructions string                    `json:"instructions"`
+	Servings     int                       `json:"servings"`
+	Ingredients  []RecipeIngredientRequest `json:"ingredients"`
+}
+
+type RecipeIngredient struct {
+	IngredientID int64   `json:"ingredient_id"`
+	FoodID       int64   `json:"food_id"`
+	FoodName     string  `json:"food_name"`
+	TotalGrams   float64 `json:"total_grams"`
+	Macros       Macros  `json:"macros"`
+}
+
+type Recipe struct {
+	RecipeID     int64              `json:"recipe_id"`
+	RecipeName   string             `json:"recipe_name"`
+	Instructions string             `json:"instructions,omitempty"`
+	Servings     int32              `json:"servings"`
+	TotalGrams   float64            `json:"total_grams"`
+	Ingredients  []RecipeIngredient `json:"ingredients"`
+	Total        Macros             `json:"total"`
+	PerServing   Macros             `json:"per_serving"`
+	Per100g      Macros             `json:"per_100g"`
+	CreatedAt    time.Time          `json:"created_at"`
+	LastUpdated  time.Time          `json:"last_updated"`
+}
+
+type RecipeResponse struct {
+	Message string  `json:"message"`
+	Success bool    `json:"success"`
+	Recipe  *Recipe `json:"recipe,omitempty"`
+}
+
+type ListRecipesResponse struct {
+	Message string   `json:"message"`
+	Success bool     `json:"success"`
+	Recipes []Recipe `json:"recipes"`
+}
+
+type RecentFood struct {
+	CacheID     int64     `json:"cache_id"`
+	FoodName    string    `json:"food_name"`
+	Calories100 float64   `json:"calories_100"`
+	Protein100  float64   `json:"protein_100"`
+	Carbs100    float64   `json:"carbs_100"`
+	Fats100     float64   `json:"fats_100"`
+	UseCount    int32     `json:"use_count"`
+	LastUsed    time.Time `json:"last_used"`
+}
+
+type RecentFoodsResponse struct {
+	Message string       `json:"message"`
+	Success bool         `json:"success"`
+	Foods   []RecentFood `json:"foods"`
+}
+
+type UpdateFoodEntryRequest struct {
+	TotalGrams float64 `json:"total_grams"`
+}
+
+type FoodEntryResponse struct {
+	Message string        `json:"message"`
+	Success bool          `json:"success"`
+	Entry   *db.FoodEntry `json:"entry,omitempty"`
+}
+
+type NutritionTargetRequest struct {
+	DayType  string  `json:"day_type"`
+	Calories float64 `json:"calories"`
+	Protein  float64 `json:"protein"`
+	Carbs    float64 `json:"carbs"`
+	Fats     float64 `json:"fats"`
+}
+
+type SetNutritionTargetsRequest struct {
+	// EffectiveFrom is YYYY-MM-DD and defaults to today
+	EffectiveFrom string                   `json:"effective_from"`
+	Targets       []NutritionTargetRequest `json:"targets"`
+}
+
+type NutritionTargetsResponse struct {
+	Message string               `json:"message"`
+	Success bool                 `json:"success"`
+	Targets []db.NutritionTarget `json:"targets"`
+}
+
+type AdherenceDay struct {
+	Date    string  `json:"date"`
+	DayType string  `json:"day_type"`
+	Totals  Macros  `json:"totals"`
+	Target  *Macros `json:"target"`
+	Percent *Macros `json:"percent,omitempty"`
+	Hit     bool    `json:"hit"`
+}
+
+type AdherenceResponse struct {
+	Message        string         `json:"message"`
+	Success        bool           `json:"success"`
+	Days           []AdherenceDay `json:"days"`
+	DaysWithTarget int            `json:"days_with_target"`
+	DaysHit        int            `json:"days_hit"`
+	HitRate        float64        `json:"hit_rate"`
+	CurrentStreak  int            `json:"current_streak"`
+	LongestStreak  int            `json:"longest_streak"`
+}
+
+type EnergyEstimates struct {
+	MifflinStJeor float64 `json:"mifflin_st_jeor,omitempty"`
+	KatchMcArdle  float64 `json:"katch_mcardle,omitempty"`
+	Adaptive      float64 `json:"adaptive,omitempty"`
+	// Used is the TDEE the target is built from, taken from Source
+	Used   float64 `json:"used,omitempty"`
+	Source string  `json:"source,omitempty"`
+}
+
+type AdaptiveDetails struct {
+	IntakeDays          int     `json:"intake_days"`
+	AverageIntake       float64 `json:"average_intake"`
+	WeightReadings      int     `json:"weight_readings"`
+	WeightChangePerWeek float64 `json:"weight_change_per_week"`
+}
+
+type RecommendationResponse struct {
+	Message  string           `json:"message"`
+	Success  bool             `json:"success"`
+	Goal     string           `json:"goal,omitempty"`
+	Activity string           `json:"activity,omitempty"`
+	BMR      EnergyEstimates  `json:"bmr"`
+	TDEE     EnergyEstimates  `json:"tdee"`
+	Adaptive *AdaptiveDetails `json:"adaptive,omitempty"`
+	Target   *Macros          `json:"target,omitempty"`
+}
+
+// MealPlanItemRequest takes exactly one of food_id or recipe_id from the
+// trainer's catalog
+type MealPlanItemRequest struct {
+	FoodID     int64   `json:"food_id,omitempty"`
+	RecipeID   int64   `json:"recipe_id,omitempty"`
+	TotalGrams float64 `json:"total_grams"`
+}
+
+type MealPlanMealRequest struct {
+	Name  string                `json:"name"`
+	Items []MealPlanItemRequest `json:"items"`
+}
+
+type MealPlanDayRequest struct {
+	Day   int                   `json:"day"`
+	Name  string                `json:"name"`
+	Meals []MealPlanMealRequest `json:"meals"`
+}
+
+type CreateMealPlanRequest struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	CycleDays   int                  `json:"cycle_days"`
+	Days        []MealPlanDayRequest `json:"days"`
+}
+
+type PlannedItem struct {
+	ItemID     int64   `json:"item_id"`
+	FoodID     int64   `json:"food_id,omitempty"`
+	RecipeID   int64   `json:"recipe_id,omitempty"`
+	ItemName   string  `json:"item_name"`
+	TotalGrams float64 `json:"total_grams"`
+	Macros     Macros  `json:"macros"`
+	// EntryIDs are the food entries logged from this item on the day shown
+	EntryIDs []int64 `json:"entry_ids,omitempty"`
+}
+
+type PlannedMeal struct {
+	MealID int64         `json:"meal_id"`
+	Name   string        `json:"name"`
+	Items  []PlannedItem `json:"items"`
+	Total  Macros        `json:"total"`
+}
+
+type PlannedDay struct {
+	DayNumber int32         `json:"day_number"`
+	Name      string        `json:"name,omitempty"`
+	Meals     []PlannedMeal `json:"meals"`
+	Total     Macros        `json:"total"`
+}
+
+type MealPlanDetail struct {
+	db.MealPlan
+	Days []PlannedDay `json:"days"`
+}
+
+type MealPlanResponse struct {
+	Message string          `json:"message"`
+	Success bool            `json:"success"`
+	Plan    *MealPlanDetail `json:"plan,omitempty"`
+}
+
+type ListMealPlansResponse struct {
+	Message string        `json:"message"`
+	Success bool          `json:"success"`
+	Plans   []db.MealPlan `json:"plans"`
+}
+
+type AssignMealPlanRequest struct {
+	ClientID  int64  `json:"client_id"`
+	StartDate string `json:"start_date"` // YYYY-MM-DD, defaults to today
+}
+
+type MealPlanAssignmentResponse struct {
+	Message    string                 `json:"message"`
+	Success    bool                   `json:"success"`
+	Assignment *db.MealPlanAssignment `json:"assignment,omitempty"`
+}
+
+type PlanDayResponse struct {
+	Message  string      `json:"message"`
+	Success  bool        `json:"success"`
+	Date     string      `json:"date,omitempty"`
+	PlanID   int64       `json:"plan_id,omitempty"`
+	PlanName string      `json:"plan_name,omitempty"`
+	Day      *PlannedDay `json:"day,omitempty"`
+}
+
+type EatPlanItemRequest struct {
+	// TotalGrams defaults to the planned amount
+	TotalGrams float64 `json:"total_grams"`
+}
+
+type PlanComparisonDay struct {
+	Date      string `json:"date"`
+	DayNumber int32  `json:"day_number,omitempty"`
+	Planned   Macros `json:"planned"`
+	Actual    Macros `json:"actual"`
+	// Difference is actual minus planned
+	Difference Macros `json:"difference"`
+}
+
+type PlanComparisonResponse struct {
+	Message  string              `json:"message"`
+	Success  bool                `json:"success"`
+	PlanID   int64               `json:"plan_id,omitempty"`
+	PlanName string              `json:"plan_name,omitempty"`
+	Days     []PlanComparisonDay `json:"days"`
+}
@@ -0,0 +1,386 @@
+package food
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const maxComparisonDays = 31
+
+// planDayNumber maps a date onto the plan's repeating cycle; it returns 0
+// before the assignment starts
+func planDayNumber(assignment db.GetActiveMealPlanAssignmentRow, date time.Time) int32 {
+	elapsed := int(date.Sub(assignment.StartDate.Time).Hours() / 24)
+	if elapsed < 0 {
+		return 0
+	}
+	return int32(elapsed%int(assignment.CycleDays)) + 1
+}
+
+// PlanDayHandler returns the client's meal plan for today, or ?date=, with
+// the food entries already logged against each item
+func (h *FoodHandler) PlanDayHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := r.URL.Query().Get("date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(PlanDayResponse{
+				Message: "Invalid 'date' format. Use YYYY-MM-DD",
+				Success: false,
+			})
+			return
+		}
+		date = parsed
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(PlanDayResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	assignment, err := h.queries.GetActiveMealPlanAssignment(r.Context(), userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(PlanDayResponse{
+			Message: "No active meal plan",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(PlanDayResponse{
+			Message: fmt.Sprintf("Failed to fetch meal plan: %v", err),
+			Success: false,
+		})
+		return
+	}
+	dayNumber := planDayNumber(assignment, date)
+	if dayNumber == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(PlanDayResponse{
+			Message: fmt.Sprintf("Meal plan starts on %s", assignment.StartDate.Time.Format("2006-01-02")),
+			Success: false,
+		})
+		return
+	}
+
+	rows, err := h.queries.ListMealPlanItems(r.Context(), db.ListMealPlanItemsParams{
+		PlanID:    assignment.PlanID,
+		DayNumber: pgtype.Int4{Int32: dayNumber, Valid: true},
+	})
+	var entries []db.FoodEntry
+	if err == nil {
+		entries, err = h.queries.ListPlanItemEntries(r.Context(), db.ListPlanItemEntriesParams{
+			UserID:   userID,
+			DateFrom: timeToPgTimestamp(date),
+			DateTo:   timeToPgTimestamp(date.AddDate(0, 0, 1)),
+		})
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(PlanDayResponse{
+			Message: fmt.Sprintf("Failed to fetch meal plan: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	eaten := make(map[int64][]int64)
+	for _, entry := range entries {
+		eaten[entry.PlanItemID.Int64] = append(eaten[entry.PlanItemID.Int64], entry.NutritionID)
+	}
+	day := PlannedDay{DayNumber: dayNumber, Meals: []PlannedMeal{}}
+	if days := groupPlanItems(rows); len(days) > 0 {
+		day = days[0]
+	}
+	for m := range day.Meals {
+		for i := range day.Meals[m].Items {
+			day.Meals[m].Items[i].EntryIDs = eaten[day.Meals[m].Items[i].ItemID]
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(PlanDayResponse{
+		Message:  "Meal plan retrieved successfully",
+		Success:  true,
+		Date:     date.Format("2006-01-02"),
+		PlanID:   assignment.PlanID,
+		PlanName: assignment.Name,
+		Day:      &day,
+	})
+}
+
+// EatPlanItemHandler logs a planned item as a food entry. The item is stored
+// in the client's food_Cache, since the food or recipe behind it belongs to
+// the trainer, and the entry keeps a link back to the plan item.
+func (h *FoodHandler) EatPlanItemHandler(w http.ResponseWriter, r *http.Request) {
+	var request EatPlanItemRequest
+	w.Header().Set("Content-Type", "application/json")
+	itemID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(LogFoodItemResponse{
+			Message: "Invalid plan item id",
+			Success: false,
+		})
+		return
+	}
+	// The body is optional: an empty one eats the planned amount
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(LogFoodItemResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	if request.TotalGrams < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(LogFoodItemResponse{
+			Message: "total_grams must be positive",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(LogFoodItemResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	item, err := h.queries.GetAssignedPlanItem(r.Context(), db.GetAssignedPlanItemParams{
+		ItemID:   itemID,
+		ClientID: userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(LogFoodItemResponse{
+			Message: "Plan item not found in your active meal plan",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(LogFoodItemResponse{
+			Message: "failed to load plan item",
+			Success: false,
+		})
+		return
+	}
+
+	grams := request.TotalGrams
+	if grams == 0 {
+		grams = item.TotalGrams
+	}
+	macros := Macros{Calories: item.Calories, Protein: item.Protein, Carbs: item.Carbs, Fats: item.Fats}.scale(grams / item.TotalGrams)
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(LogFoodItemResponse{
+			Message: "failed to log plan item",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	cached, err := qtx.UpsertFoodCacheItem(r.Context(), db.UpsertFoodCacheItemParams{
+		UserID:         userID,
+		FoodName:       item.ItemName,
+		NormalizedName: normalizeFoodName(item.ItemName),
+		Calories100:    per100(item.Calories, item.TotalGrams),
+		Protein100:     per100(item.Protein, item.TotalGrams),
+		Carbs100:       per100(item.Carbs, item.TotalGrams),
+		Fats100:        per100(item.Fats, item.TotalGrams),
+	})
+	var entry db.FoodEntry
+	if err == nil {
+		entry, err = qtx.LogFoodItem(r.Context(), db.LogFoodItemParams{
+			UserID:     userID,
+			FoodID:     int64ToPgInt8(0, false),
+			RecipeID:   int64ToPgInt8(0, false),
+			CacheID:    int64ToPgInt8(cached.FoodID, true),
+			Calories:   macros.Calories,
+			TotalGrams: grams,
+			Protein:    macros.Protein,
+			Carbs:      macros.Carbs,
+			Fats:       macros.Fats,
+			PlanItemID: int64ToPgInt8(item.ItemID, true),
+		})
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(LogFoodItemResponse{
+			Message: "failed to log plan item",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(LogFoodItemResponse{
+		Message: "success",
+		Success: true,
+		Entry:   &entry,
+	})
+}
+
+// PlanComparisonHandler shows planned against actual macros for each day
+// between ?from= and ?to= (both inclusive). Actual intake comes from
+// ViewFoodTotal, so it counts everything logged that day, on plan or not.
+func (h *FoodHandler) PlanComparisonHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+
+	if query.Get("from") == "" || query.Get("to") == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(PlanComparisonResponse{
+			Message: "'from' and 'to' date parameters are required. Format: YYYY-MM-DD",
+			Success: false,
+		})
+		return
+	}
+	dateFrom, err := time.Parse("2006-01-02", query.Get("from"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(PlanComparisonResponse{
+			Message: "Invalid 'from' date format. Use YYYY-MM-DD",
+			Success: false,
+		})
+		return
+	}
+	dateTo, err := time.Parse("2006-01-02", query.Get("to"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(PlanComparisonResponse{
+			Message: "Invalid 'to' date format. Use YYYY-MM-DD",
+			Success: false,
+		})
+		return
+	}
+	if dateTo.Before(dateFrom) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(PlanComparisonResponse{
+			Message: "'to' date must be after 'from' date",
+			Success: false,
+		})
+		return
+	}
+	if dateTo.Sub(dateFrom) >= maxComparisonDays*24*time.Hour {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(PlanComparisonResponse{
+			Message: fmt.Sprintf("The range can cover at most %d days", maxComparisonDays),
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(PlanComparisonResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	assignment, err := h.queries.GetActiveMealPlanAssignment(r.Context(), userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(PlanComparisonResponse{
+			Message: "No active meal plan",
+			Success: false,
+		})
+		return
+	}
+	var rows []db.ListMealPlanItemsRow
+	if err == nil {
+		rows, err = h.queries.ListMealPlanItems(r.Context(), db.ListMealPlanItemsParams{PlanID: assignment.PlanID})
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(PlanComparisonResponse{
+			Message: fmt.Sprintf("Failed to fetch meal plan: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	planned := make(map[int32]Macros)
+	for _, day := range groupPlanItems(rows) {
+		planned[day.DayNumber] = day.Total
+	}
+
+	days := []PlanComparisonDay{}
+	for date := dateFrom; !date.After(dateTo); date = date.AddDate(0, 0, 1) {
+		// ViewFoodTotal's range is inclusive at both ends
+		totals, err := h.queries.ViewFoodTotal(r.Context(), db.ViewFoodTotalParams{
+			UserID:      userID,
+			CreatedAt:   timeToPgTimestamp(date),
+			CreatedAt_2: timeToPgTimestamp(date.AddDate(0, 0, 1).Add(-time.Microsecond)),
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(PlanComparisonResponse{
+				Message: fmt.Sprintf("Failed to fetch food totals: %v", err),
+				Success: false,
+			})
+			return
+		}
+		dayNumber := planDayNumber(assignment, date)
+		actual := Macros{
+			Calories: totals.TotalCalories,
+			Protein:  totals.TotalProtein,
+			Carbs:    totals.TotalCarbs,
+			Fats:     totals.TotalFats,
+		}
+		days = append(days, PlanComparisonDay{
+			Date:       date.Format("2006-01-02"),
+			DayNumber:  dayNumber,
+			Planned:    planned[dayNumber],
+			Actual:     actual,
+			Difference: actual.add(planned[dayNumber].scale(-1)),
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(PlanComparisonResponse{
+		Message:  "Meal plan comparison retrieved successfully",
+		Success:  true,
+		PlanID:   assignment.PlanID,
+		PlanName: assignment.Name,
+		Days:     days,
+	})
+}
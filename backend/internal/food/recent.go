@@ -0,0 +1,90 @@
+package food
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+)
+
+const defaultRecentLimit = 20
+
+// normalizeFoodName lower-cases the name and collapses whitespace so "Oats",
+// " oats " and "OATS" share one food_Cache row
+func normalizeFoodName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// per100 converts a logged total to a per-100g value, rounded to two decimals
+// so repeated logs of the same food hit the food_Cache unique constraint
+func per100(total, grams float64) float64 {
+	return math.Round(total/grams*100*100) / 100
+}
+
+// RecentFoodsHandler lists the user's quick-logged foods, most used first.
+// ListRecentFoods ranks by use_count decayed by the weeks since last use.
+func (h *FoodHandler) RecentFoodsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	limit := int64(defaultRecentLimit)
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.ParseInt(limitStr, 10, 32)
+		if err != nil || parsed <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(RecentFoodsResponse{
+				Message: "'limit' must be a positive integer",
+				Success: false,
+			})
+			return
+		}
+		limit = min(parsed, maxPageLimit)
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(RecentFoodsResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	cached, err := h.queries.ListRecentFoods(r.Context(), db.ListRecentFoodsParams{
+		UserID: userID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecentFoodsResponse{
+			Message: fmt.Sprintf("Failed to fetch recent foods: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	foods := make([]RecentFood, 0, len(cached))
+	for _, item := range cached {
+		foods = append(foods, RecentFood{
+			CacheID:     item.FoodID,
+			FoodName:    item.FoodName,
+			Calories100: item.Calories100,
+			Protein100:  item.Protein100,
+			Carbs100:    item.Carbs100,
+			Fats100:     item.Fats100,
+			UseCount:    item.UseCount,
+			LastUsed:    item.LastUsed.Time,
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(RecentFoodsResponse{
+		Message: "Recent foods retrieved successfully",
+		Success: true,
+		Foods:   foods,
+	})
+}
@@ -0,0 +1,541 @@
+package food
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var errRecipeNotFound = errors.New("recipe not found")
+
+// macrosFor scales per-100g values to the given amount of grams
+func macrosFor(grams, calories100, protein100, carbs100, fats100 float64) Macros {
+	factor := grams / 100
+	return Macros{
+		Calories: calories100 * factor,
+		Protein:  protein100 * factor,
+		Carbs:    carbs100 * factor,
+		Fats:     fats100 * factor,
+	}
+}
+
+func (m Macros) add(other Macros) Macros {
+	return Macros{
+		Calories: m.Calories + other.Calories,
+		Protein:  m.Protein + other.Protein,
+		Carbs:    m.Carbs + other.Carbs,
+		Fats:     m.Fats + other.Fats,
+	}
+}
+
+func (m Macros) scale(factor float64) Macros {
+	return Macros{
+		Calories: m.Calories * factor,
+		Protein:  m.Protein * factor,
+		Carbs:    m.Carbs * factor,
+		Fats:     m.Fats * factor,
+	}
+}
+
+// buildRecipe sums the ingredient macros and derives the per-serving and per-100g values
+func buildRecipe(recipe db.Recipe, ingredients []db.ListRecipeIngredientsRow) Recipe {
+	result := Recipe{
+		RecipeID:     recipe.RecipeID,
+		RecipeName:   recipe.RecipeName,
+		Instructions: recipe.Instructions.String,
+		Servings:     recipe.Servings,
+		Ingredients:  make([]RecipeIngredient, 0, len(ingredients)),
+		CreatedAt:    recipe.CreatedAt.Time,
+		LastUpdated:  recipe.LastUpdated.Time,
+	}
+
+	for _, ingredient := range ingredients {
+		macros := macrosFor(ingredient.TotalGrams, ingredient.Calories100, ingredient.Protein100, ingredient.Carbs100, ingredient.Fats100)
+		result.Ingredients = append(result.Ingredients, RecipeIngredient{
+			IngredientID: ingredient.IngredientID,
+			FoodID:       ingredient.FoodID,
+			FoodName:     ingredient.FoodName,
+			TotalGrams:   ingredient.TotalGrams,
+			Macros:       macros,
+		})
+		result.TotalGrams += ingredient.TotalGrams
+		result.Total = result.Total.add(macros)
+	}
+
+	if recipe.Servings > 0 {
+		result.PerServing = result.Total.scale(1 / float64(recipe.Servings))
+	}
+	if result.TotalGrams > 0 {
+		result.Per100g = result.Total.scale(100 / result.TotalGrams)
+	}
+	return result
+}
+
+func (h *FoodHandler) loadRecipe(ctx context.Context, userID, recipeID int64) (Recipe, error) {
+	recipe, err := h.queries.GetRecipe(ctx, db.GetRecipeParams{
+		RecipeID: recipeID,
+		UserID:   userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Recipe{}, errRecipeNotFound
+	}
+	if err != nil {
+		return Recipe{}, err
+	}
+
+	ingredients, err := h.queries.ListRecipeIngredients(ctx, recipeID)
+	if err != nil {
+		return Recipe{}, err
+	}
+	return buildRecipe(recipe, ingredients), nil
+}
+
+// validateRecipe normalises the request in place and returns a client-facing
+// message when it cannot be saved
+func (h *FoodHandler) validateRecipe(ctx context.Context, userID int64, name *string, servings *int, ingredients []RecipeIngredientRequest) (string, error) {
+	*name = strings.TrimSpace(*name)
+	if *name == "" {
+		return "recipe_name is required", nil
+	}
+	if *servings == 0 {
+		*servings = 1
+	}
+	if *servings < 0 {
+		return "servings must be positive", nil
+	}
+	if len(ingredients) == 0 {
+		return "a recipe needs at least one ingredient", nil
+	}
+
+	foodIDs := make([]int64, 0, len(ingredients))
+	seen := make(map[int64]bool)
+	for _, ingredient := range ingredients {
+		if ingredient.FoodID <= 0 {
+			return "every ingredient needs a food_id", nil
+		}
+		if ingredient.TotalGrams <= 0 {
+			return "ingredient total_grams must be positive", nil
+		}
+		if !seen[ingredient.FoodID] {
+			seen[ingredient.FoodID] = true
+			foodIDs = append(foodIDs, ingredient.FoodID)
+		}
+	}
+
+	owned, err := h.queries.CountUserFoods(ctx, db.CountUserFoodsParams{
+		UserID:  userID,
+		FoodIds: foodIDs,
+	})
+	if err != nil {
+		return "", err
+	}
+	if owned != int64(len(foodIDs)) {
+		return "one or more food_id values do not exist in your food catalog", nil
+	}
+	return "", nil
+}
+
+func addRecipeIngredients(ctx context.Context, qtx *db.Queries, recipeID int64, ingredients []RecipeIngredientRequest) error {
+	for _, ingredient := range ingredients {
+		_, err := qtx.AddRecipeIngredient(ctx, db.AddRecipeIngredientParams{
+			RecipeID:   recipeID,
+			FoodID:     ingredient.FoodID,
+			TotalGrams: ingredient.TotalGrams,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *FoodHandler) CreateRecipeHandler(w http.ResponseWriter, r *http.Request) {
+	var request CreateRecipeRequest
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	message, err := h.validateRecipe(r.Context(), userID, &request.RecipeName, &request.Servings, request.Ingredients)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "failed to validate ingredients",
+			Success: false,
+		})
+		return
+	}
+	if message != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: message,
+			Success: false,
+		})
+		return
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "failed to create recipe",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	recipe, err := qtx.CreateRecipe(r.Context(), db.CreateRecipeParams{
+		UserID:       userID,
+		RecipeName:   request.RecipeName,
+		Instructions: pgtype.Text{String: request.Instructions, Valid: request.Instructions != ""},
+		Servings:     int32(request.Servings),
+	})
+	if err == nil {
+		err = addRecipeIngredients(r.Context(), qtx, recipe.RecipeID, request.Ingredients)
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "failed to create recipe",
+			Success: false,
+		})
+		return
+	}
+
+	created, err := h.loadRecipe(r.Context(), userID, recipe.RecipeID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "recipe created but could not be loaded",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(RecipeResponse{
+		Message: "Recipe created",
+		Success: true,
+		Recipe:  &created,
+	})
+}
+
+func (h *FoodHandler) ListRecipesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ListRecipesResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	recipes, err := h.queries.ListRecipes(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ListRecipesResponse{
+			Message: fmt.Sprintf("Failed to fetch recipes: %v", err),
+			Success: false,
+		})
+		return
+	}
+	ingredients, err := h.queries.ListUserRecipeIngredients(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ListRecipesResponse{
+			Message: fmt.Sprintf("Failed to fetch recipe ingredients: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	byRecipe := make(map[int64][]db.ListRecipeIngredientsRow)
+	for _, ingredient := range ingredients {
+		byRecipe[ingredient.RecipeID] = append(byRecipe[ingredient.RecipeID], db.ListRecipeIngredientsRow(ingredient))
+	}
+	result := make([]Recipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		result = append(result, buildRecipe(recipe, byRecipe[recipe.RecipeID]))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ListRecipesResponse{
+		Message: "Recipes retrieved successfully",
+		Success: true,
+		Recipes: result,
+	})
+}
+
+func (h *FoodHandler) GetRecipeHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	recipeID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "Invalid recipe id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	recipe, err := h.loadRecipe(r.Context(), userID, recipeID)
+	if errors.Is(err, errRecipeNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "Recipe not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: fmt.Sprintf("Failed to fetch recipe: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(RecipeResponse{
+		Message: "Recipe retrieved successfully",
+		Success: true,
+		Recipe:  &recipe,
+	})
+}
+
+func (h *FoodHandler) UpdateRecipeHandler(w http.ResponseWriter, r *http.Request) {
+	var request UpdateRecipeRequest
+	w.Header().Set("Content-Type", "application/json")
+	recipeID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "Invalid recipe id",
+			Success: false,
+		})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	message, err := h.validateRecipe(r.Context(), userID, &request.RecipeName, &request.Servings, request.Ingredients)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "failed to validate ingredients",
+			Success: false,
+		})
+		return
+	}
+	if message != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: message,
+			Success: false,
+		})
+		return
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "failed to update recipe",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	_, err = qtx.UpdateRecipe(r.Context(), db.UpdateRecipeParams{
+		RecipeID:     recipeID,
+		UserID:       userID,
+		RecipeName:   request.RecipeName,
+		Instructions: pgtype.Text{String: request.Instructions, Valid: request.Instructions != ""},
+		Servings:     int32(request.Servings),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "Recipe not found",
+			Success: false,
+		})
+		return
+	}
+	if err == nil {
+		err = qtx.DeleteRecipeIngredients(r.Context(), recipeID)
+	}
+	if err == nil {
+		err = addRecipeIngredients(r.Context(), qtx, recipeID, request.Ingredients)
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "failed to update recipe",
+			Success: false,
+		})
+		return
+	}
+
+	updated, err := h.loadRecipe(r.Context(), userID, recipeID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "recipe updated but could not be loaded",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(RecipeResponse{
+		Message: "Recipe updated",
+		Success: true,
+		Recipe:  &updated,
+	})
+}
+
+func (h *FoodHandler) DeleteRecipeHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	recipeID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "Invalid recipe id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "failed to delete recipe",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	_, err = qtx.GetRecipe(r.Context(), db.GetRecipeParams{
+		RecipeID: recipeID,
+		UserID:   userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "Recipe not found",
+			Success: false,
+		})
+		return
+	}
+	if err == nil {
+		err = qtx.DeleteRecipeIngredients(r.Context(), recipeID)
+	}
+	if err == nil {
+		_, err = qtx.DeleteRecipe(r.Context(), db.DeleteRecipeParams{
+			RecipeID: recipeID,
+			UserID:   userID,
+		})
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+	if isForeignKeyViolation(err) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "Recipe has logged food entries and cannot be deleted",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RecipeResponse{
+			Message: "failed to delete recipe",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(RecipeResponse{
+		Message: "Recipe deleted",
+		Success: true,
+	})
+}
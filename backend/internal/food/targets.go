@@ -0,0 +1,399 @@
+package food
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	DayTypeAny      = "any"
+	DayTypeTraining = "training"
+	DayTypeRest     = "rest"
+)
+
+const (
+	// A day hits its target when calories land within this share of the
+	// target and protein reaches at least proteinFloor of it
+	calorieTolerance = 0.10
+	proteinFloor     = 0.90
+
+	maxAdherenceDays = 366
+)
+
+// targetFor picks the newest target in effect on day. A training or rest
+// target beats an 'any' target with the same effective date. targets must be
+// ordered newest first, as ListNutritionTargets returns them.
+func targetFor(targets []db.NutritionTarget, day time.Time, dayType string) *db.NutritionTarget {
+	var best *db.NutritionTarget
+	for i := range targets {
+		target := &targets[i]
+		if target.EffectiveFrom.Time.After(day) || (target.DayType != dayType && target.DayType != DayTypeAny) {
+			continue
+		}
+		if best != nil && target.EffectiveFrom.Time.Before(best.EffectiveFrom.Time) {
+			break
+		}
+		if best == nil || target.DayType == dayType {
+			best = target
+		}
+	}
+	return best
+}
+
+func percentOf(actual, target float64) float64 {
+	if target <= 0 {
+		return 0
+	}
+	return math.Round(actual/target*1000) / 10
+}
+
+func targetHit(totals, target Macros) bool {
+	return math.Abs(totals.Calories-target.Calories) <= target.Calories*calorieTolerance &&
+		totals.Protein >= target.Protein*proteinFloor
+}
+
+// streaks returns the run of hit days ending at the last day and the longest
+// run in the range. Days without a target neither extend nor break a run.
+// Today does not break the current run while it is still being logged, and
+// days after today, which ?to= can reach, are ignored for it.
+func streaks(days []AdherenceDay, today string) (current, longest int) {
+	run := 0
+	for _, day := range days {
+		if day.Target == nil {
+			continue
+		}
+		if day.Hit {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+
+	for i := len(days) - 1; i >= 0; i-- {
+		day := days[i]
+		// Dates are YYYY-MM-DD, so they compare in calendar order
+		if day.Target == nil || day.Date > today || (day.Date == today && !day.Hit) {
+			continue
+		}
+		if !day.Hit {
+			break
+		}
+		current++
+	}
+	return current, longest
+}
+
+func (h *FoodHandler) ListNutritionTargetsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(NutritionTargetsResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	targets, err := h.queries.ListNutritionTargets(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(NutritionTargetsResponse{
+			Message: fmt.Sprintf("Failed to fetch nutrition targets: %v", err),
+			Success: false,
+		})
+		return
+	}
+	if targets == nil {
+		targets = []db.NutritionTarget{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(NutritionTargetsResponse{
+		Message: "Nutrition targets retrieved successfully",
+		Success: true,
+		Targets: targets,
+	})
+}
+
+// SetNutritionTargetsHandler stores targets taking effect from one date.
+// Earlier targets are kept so adherence for past days is judged against the
+// numbers that applied at the time.
+func (h *FoodHandler) SetNutritionTargetsHandler(w http.ResponseWriter, r *http.Request) {
+	var request SetNutritionTargetsRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(NutritionTargetsResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	effectiveFrom := time.Now().UTC().Truncate(24 * time.Hour)
+	if request.EffectiveFrom != "" {
+		var err error
+		effectiveFrom, err = time.Parse("2006-01-02", request.EffectiveFrom)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(NutritionTargetsResponse{
+				Message: "Invalid 'effective_from' date format. Use YYYY-MM-DD",
+				Success: false,
+			})
+			return
+		}
+	}
+	if len(request.Targets) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(NutritionTargetsResponse{
+			Message: "at least one target is required",
+			Success: false,
+		})
+		return
+	}
+	seen := make(map[string]bool, len(request.Targets))
+	for i := range request.Targets {
+		target := &request.Targets[i]
+		target.DayType = strings.ToLower(strings.TrimSpace(target.DayType))
+		if target.DayType == "" {
+			target.DayType = DayTypeAny
+		}
+		message := ""
+		switch {
+		case target.DayType != DayTypeAny && target.DayType != DayTypeTraining && target.DayType != DayTypeRest:
+			message = "day_type must be one of any, training, rest"
+		case seen[target.DayType]:
+			message = fmt.Sprintf("day_type %q is listed more than once", target.DayType)
+		case target.Calories <= 0:
+			message = "calories must be positive"
+		case target.Protein < 0 || target.Carbs < 0 || target.Fats < 0:
+			message = "macros cannot be negative"
+		}
+		if message != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(NutritionTargetsResponse{
+				Message: message,
+				Success: false,
+			})
+			return
+		}
+		seen[target.DayType] = true
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(NutritionTargetsResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(NutritionTargetsResponse{
+			Message: "failed to save nutrition targets",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	saved := make([]db.NutritionTarget, 0, len(request.Targets))
+	for _, target := range request.Targets {
+		var row db.NutritionTarget
+		row, err = qtx.UpsertNutritionTarget(r.Context(), db.UpsertNutritionTargetParams{
+			UserID:        userID,
+			DayType:       target.DayType,
+			EffectiveFrom: pgtype.Date{Time: effectiveFrom, Valid: true},
+			Calories:      target.Calories,
+			Protein:       target.Protein,
+			Carbs:         target.Carbs,
+			Fats:          target.Fats,
+		})
+		if err != nil {
+			break
+		}
+		saved = append(saved, row)
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(NutritionTargetsResponse{
+			Message: "failed to save nutrition targets",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(NutritionTargetsResponse{
+		Message: "Nutrition targets saved",
+		Success: true,
+		Targets: saved,
+	})
+}
+
+// AdherenceHandler compares each day's totals between ?from= and ?to= (both
+// inclusive) with the target in effect that day. Days with exercise entries
+// use the training target, the rest use the rest target.
+func (h *FoodHandler) AdherenceHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+
+	dateFrom, err := time.Parse("2006-01-02", query.Get("from"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(AdherenceResponse{
+			Message: "'from' date parameter is required. Format: YYYY-MM-DD",
+			Success: false,
+		})
+		return
+	}
+	dateTo, err := time.Parse("2006-01-02", query.Get("to"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(AdherenceResponse{
+			Message: "'to' date parameter is required. Format: YYYY-MM-DD",
+			Success: false,
+		})
+		return
+	}
+	if dateTo.Before(dateFrom) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(AdherenceResponse{
+			Message: "'to' date must be after 'from' date",
+			Success: false,
+		})
+		return
+	}
+	if dateTo.Sub(dateFrom) >= maxAdherenceDays*24*time.Hour {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(AdherenceResponse{
+			Message: fmt.Sprintf("date range cannot exceed %d days", maxAdherenceDays),
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(AdherenceResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	rangeStart := timeToPgTimestamp(dateFrom)
+	rangeEnd := timeToPgTimestamp(dateTo.AddDate(0, 0, 1))
+	totals, err := h.queries.DailyFoodTotals(r.Context(), db.DailyFoodTotalsParams{
+		UserID:   userID,
+		DateFrom: rangeStart,
+		DateTo:   rangeEnd,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(AdherenceResponse{
+			Message: fmt.Sprintf("Failed to fetch daily totals: %v", err),
+			Success: false,
+		})
+		return
+	}
+	trainingDays, err := h.queries.ListTrainingDays(r.Context(), db.ListTrainingDaysParams{
+		UserID:   userID,
+		DateFrom: rangeStart,
+		DateTo:   rangeEnd,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(AdherenceResponse{
+			Message: fmt.Sprintf("Failed to fetch training days: %v", err),
+			Success: false,
+		})
+		return
+	}
+	targets, err := h.queries.ListNutritionTargets(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(AdherenceResponse{
+			Message: fmt.Sprintf("Failed to fetch nutrition targets: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	totalsByDay := make(map[string]Macros, len(totals))
+	for _, row := range totals {
+		totalsByDay[row.Day.Time.Format("2006-01-02")] = Macros{
+			Calories: row.Calories,
+			Protein:  row.Protein,
+			Carbs:    row.Carbs,
+			Fats:     row.Fats,
+		}
+	}
+	trained := make(map[string]bool, len(trainingDays))
+	for _, day := range trainingDays {
+		trained[day.Time.Format("2006-01-02")] = true
+	}
+
+	response := AdherenceResponse{
+		Message: "Adherence retrieved successfully",
+		Success: true,
+		Days:    []AdherenceDay{},
+	}
+	for day := dateFrom; !day.After(dateTo); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		entry := AdherenceDay{
+			Date:    key,
+			DayType: DayTypeRest,
+			Totals:  totalsByDay[key],
+		}
+		if trained[key] {
+			entry.DayType = DayTypeTraining
+		}
+		if target := targetFor(targets, day, entry.DayType); target != nil {
+			goal := Macros{
+				Calories: target.Calories,
+				Protein:  target.Protein,
+				Carbs:    target.Carbs,
+				Fats:     target.Fats,
+			}
+			entry.Target = &goal
+			entry.Percent = &Macros{
+				Calories: percentOf(entry.Totals.Calories, goal.Calories),
+				Protein:  percentOf(entry.Totals.Protein, goal.Protein),
+				Carbs:    percentOf(entry.Totals.Carbs, goal.Carbs),
+				Fats:     percentOf(entry.Totals.Fats, goal.Fats),
+			}
+			entry.Hit = targetHit(entry.Totals, goal)
+			response.DaysWithTarget++
+			if entry.Hit {
+				response.DaysHit++
+			}
+		}
+		response.Days = append(response.Days, entry)
+	}
+	response.HitRate = percentOf(float64(response.DaysHit), float64(response.DaysWithTarget))
+	response.CurrentStreak, response.LongestStreak = streaks(response.Days, time.Now().UTC().Format("2006-01-02"))
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
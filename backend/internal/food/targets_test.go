@@ -0,0 +1,58 @@
+package food
+
+import "testing"
+
+func TestStreaks(t *testing.T) {
+	target := &Macros{Calories: 2000, Protein: 150}
+	day := func(date string, hit bool) AdherenceDay {
+		return AdherenceDay{Date: date, Target: target, Hit: hit}
+	}
+
+	tests := []struct {
+		name    string
+		days    []AdherenceDay
+		current int
+		longest int
+	}{
+		{
+			name:    "run ending today",
+			days:    []AdherenceDay{day("2026-05-01", false), day("2026-05-02", true), day("2026-05-03", true)},
+			current: 2, longest: 2,
+		},
+		{
+			name:    "today still being logged",
+			days:    []AdherenceDay{day("2026-05-01", true), day("2026-05-02", true), day("2026-05-03", false)},
+			current: 2, longest: 2,
+		},
+		{
+			name: "range runs past today",
+			days: []AdherenceDay{
+				day("2026-05-01", true), day("2026-05-02", true), day("2026-05-03", false),
+				day("2026-05-04", false), day("2026-05-05", false),
+			},
+			current: 2, longest: 2,
+		},
+		{
+			name: "days without a target are skipped",
+			days: []AdherenceDay{
+				day("2026-04-29", true), day("2026-04-30", true),
+				{Date: "2026-05-01"}, day("2026-05-02", true), day("2026-05-03", true),
+			},
+			current: 4, longest: 4,
+		},
+		{
+			name:    "missed day breaks the run",
+			days:    []AdherenceDay{day("2026-04-30", true), day("2026-05-01", true), day("2026-05-02", false), day("2026-05-03", true)},
+			current: 1, longest: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, longest := streaks(tt.days, "2026-05-03")
+			if current != tt.current || longest != tt.longest {
+				t.Errorf("streaks() = (%d, %d), want (%d, %d)", current, longest, tt.current, tt.longest)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// mail/mail.go
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers outgoing mail. Handlers depend on this interface so a
+// real provider can be dropped in without touching them.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender writes messages to the standard logger, for local development
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileSender writes each message to its own file in dir
+type FileSender struct {
+	dir string
+}
+
+func NewFileSender(dir string) (*FileSender, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("mail directory cannot be empty")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create mail directory: %w", err)
+	}
+	return &FileSender{dir: dir}, nil
+}
+
+func (s *FileSender) Send(ctx context.Context, msg Message) error {
+	// Addresses can contain characters that are awkward in file names
+	to := strings.NewReplacer("/", "_", "\\", "_", "@", "_at_").Replace(msg.To)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), to)
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", msg.To, msg.Subject, msg.Body)
+	return os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0o600)
+}
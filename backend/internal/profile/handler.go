@@ -0,0 +1,206 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	UnitsMetric   = "metric"
+	UnitsImperial = "imperial"
+)
+
+type ProfileHandler struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+}
+
+func NewProfileHandler(q *db.Queries, pool *pgxpool.Pool) *ProfileHandler {
+	return &ProfileHandler{
+		pool:    pool,
+		queries: q,
+	}
+}
+
+func toProfile(row db.UsersProfile) Profile {
+	profile := Profile{
+		UserID:         row.UserID,
+		Email:          row.Email.String,
+		Height:         common.NumericToFloat64(row.Height),
+		Weight:         common.NumericToFloat64(row.Weight),
+		Sex:            row.Sex.String,
+		BodyFat:        common.NumericToFloat64(row.BodyFat),
+		PreferredUnits: row.PreferredUnits,
+		IsTrainer:      row.IsTrainer,
+		IsVip:          row.IsVip,
+		IsAdmin:        row.IsAdmin,
+		LastUpdated:    row.LastUpdated.Time,
+	}
+	if row.DateOfBirth.Valid {
+		profile.DateOfBirth = row.DateOfBirth.Time.Format("2006-01-02")
+	}
+	return profile
+}
+
+// validateProfile normalises the request in place and returns a
+// client-facing message when it cannot be saved
+func validateProfile(request *UpdateProfileRequest, now time.Time) string {
+	request.Email = strings.ToLower(strings.TrimSpace(request.Email))
+	if request.Email != "" {
+		address, err := mail.ParseAddress(request.Email)
+		if err != nil || address.Address != request.Email {
+			return "email is not a valid address"
+		}
+	}
+	if request.DateOfBirth != "" {
+		birth, err := time.Parse("2006-01-02", request.DateOfBirth)
+		if err != nil {
+			return "Invalid 'date_of_birth' format. Use YYYY-MM-DD"
+		}
+		if birth.After(now) || birth.Year() < 1900 {
+			return "date_of_birth is out of range"
+		}
+	}
+	if request.Height != 0 && (request.Height < 50 || request.Height > 300) {
+		return "height must be between 50 and 300 cm"
+	}
+	if request.Weight != 0 && (request.Weight < 20 || request.Weight > 500) {
+		return "weight must be between 20 and 500 kg"
+	}
+	if request.BodyFat != 0 && (request.BodyFat < 2 || request.BodyFat > 70) {
+		return "body_fat must be between 2 and 70 percent"
+	}
+	request.Sex = strings.ToLower(strings.TrimSpace(request.Sex))
+	if request.Sex != "" && request.Sex != "male" && request.Sex != "female" {
+		return "sex must be male or female"
+	}
+	request.PreferredUnits = strings.ToLower(strings.TrimSpace(request.PreferredUnits))
+	if request.PreferredUnits == "" {
+		request.PreferredUnits = UnitsMetric
+	}
+	if request.PreferredUnits != UnitsMetric && request.PreferredUnits != UnitsImperial {
+		return "preferred_units must be metric or imperial"
+	}
+	return ""
+}
+
+func (h *ProfileHandler) GetProfileHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ProfileResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	row, err := h.queries.GetUserProfile(r.Context(), userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Accounts registered before profiles were created at sign-up
+		row = db.UsersProfile{UserID: userID, PreferredUnits: UnitsMetric}
+		err = nil
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ProfileResponse{
+			Message: fmt.Sprintf("Failed to fetch profile: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	profile := toProfile(row)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ProfileResponse{
+		Message: "Profile retrieved successfully",
+		Success: true,
+		Profile: &profile,
+	})
+}
+
+func (h *ProfileHandler) UpdateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	var request UpdateProfileRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ProfileResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	if message := validateProfile(&request, time.Now()); message != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ProfileResponse{
+			Message: message,
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ProfileResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	params := db.UpsertUserProfileParams{
+		UserID:         userID,
+		Email:          pgtype.Text{String: request.Email, Valid: request.Email != ""},
+		Height:         common.OptionalNumeric(request.Height),
+		Weight:         common.OptionalNumeric(request.Weight),
+		Sex:            pgtype.Text{String: request.Sex, Valid: request.Sex != ""},
+		BodyFat:        common.OptionalNumeric(request.BodyFat),
+		PreferredUnits: request.PreferredUnits,
+	}
+	if request.DateOfBirth != "" {
+		birth, _ := time.Parse("2006-01-02", request.DateOfBirth)
+		params.DateOfBirth = pgtype.Date{Time: birth, Valid: true}
+	}
+
+	row, err := h.queries.UpsertUserProfile(r.Context(), params)
+	if common.IsUniqueViolation(err) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ProfileResponse{
+			Message: "Email is already in use",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ProfileResponse{
+			Message: "failed to update profile",
+			Success: false,
+		})
+		return
+	}
+
+	profile := toProfile(row)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ProfileResponse{
+		Message: "Profile updated",
+		Success: true,
+		Profile: &profile,
+	})
+}
@@ -0,0 +1,369 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// trendSmoothing is the share of each day's gap between the scale and the
+// trend that the trend closes, as in the Hacker's Diet moving average
+const trendSmoothing = 0.1
+
+// parseMeasuredAt parses an optional RFC3339 timestamp, defaulting to now
+func parseMeasuredAt(value string) (pgtype.Timestamp, error) {
+	if value == "" {
+		return pgtype.Timestamp{Time: time.Now().UTC(), Valid: true}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return pgtype.Timestamp{}, err
+	}
+	return pgtype.Timestamp{Time: t.UTC(), Valid: true}, nil
+}
+
+// validateMeasurement returns a client-facing message when the measurement
+// cannot be saved
+func validateMeasurement(request MeasurementRequest) string {
+	values := []float64{request.Bodyweight, request.BodyFat, request.Waist, request.Chest, request.Arms, request.Thighs}
+	recorded := false
+	for _, value := range values {
+		if value < 0 {
+			return "measurements cannot be negative"
+		}
+		recorded = recorded || value > 0
+	}
+	if !recorded {
+		return "at least one of bodyweight, body_fat, waist, chest, arms, thighs is required"
+	}
+	// Zero body_fat means it wasn't measured
+	if request.BodyFat != 0 && (request.BodyFat < 2 || request.BodyFat > 70) {
+		return "body_fat must be between 2 and 70 percent"
+	}
+	return ""
+}
+
+// withTrend adds an exponential moving average of bodyweight to each
+// measurement and returns the trend's rate of change per week. The
+// smoothing is applied per elapsed day so irregular weigh-ins are weighted
+// by the time between them; weigh-ins less than a day apart still count as
+// a day so a second reading on the same morning isn't ignored. The trend is
+// kept at full precision and only rounded for output.
+func withTrend(measurements []db.BodyMeasurement) ([]MeasurementWithTrend, float64) {
+	result := make([]MeasurementWithTrend, 0, len(measurements))
+	type point struct {
+		at    time.Time
+		trend float64
+	}
+	var points []point
+	for _, measurement := range measurements {
+		item := MeasurementWithTrend{BodyMeasurement: measurement}
+		weight := common.NumericToFloat64(measurement.Bodyweight)
+		if weight > 0 {
+			trend := weight
+			if len(points) > 0 {
+				previous := points[len(points)-1]
+				days := measurement.MeasuredAt.Time.Sub(previous.at).Hours() / 24
+				alpha := 1 - math.Pow(1-trendSmoothing, math.Max(days, 1))
+				trend = previous.trend + alpha*(weight-previous.trend)
+			}
+			points = append(points, point{at: measurement.MeasuredAt.Time, trend: trend})
+			rounded := math.Round(trend*100) / 100
+			item.Trend = &rounded
+		}
+		result = append(result, item)
+	}
+	if len(points) < 2 {
+		return result, 0
+	}
+
+	// Compare with the trend a week before the latest weigh-in, or the
+	// earliest one when the log is shorter than that
+	last := points[len(points)-1]
+	reference := points[0]
+	for _, p := range points {
+		if last.at.Sub(p.at) < 7*24*time.Hour {
+			break
+		}
+		reference = p
+	}
+	days := last.at.Sub(reference.at).Hours() / 24
+	if days <= 0 {
+		return result, 0
+	}
+	return result, math.Round((last.trend-reference.trend)/days*7*100) / 100
+}
+
+func (h *ProfileHandler) ListMeasurementsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+
+	params := db.ListBodyMeasurementsParams{}
+	if from := query.Get("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ListMeasurementsResponse{
+				Message: "Invalid 'from' date format. Use YYYY-MM-DD",
+				Success: false,
+			})
+			return
+		}
+		params.DateFrom = pgtype.Timestamp{Time: t, Valid: true}
+	}
+	if to := query.Get("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ListMeasurementsResponse{
+				Message: "Invalid 'to' date format. Use YYYY-MM-DD",
+				Success: false,
+			})
+			return
+		}
+		// 'to' is inclusive
+		params.DateTo = pgtype.Timestamp{Time: t.AddDate(0, 0, 1), Valid: true}
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ListMeasurementsResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+	params.UserID = userID
+
+	measurements, err := h.queries.ListBodyMeasurements(r.Context(), params)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ListMeasurementsResponse{
+			Message: fmt.Sprintf("Failed to fetch measurements: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	items, weeklyRate := withTrend(measurements)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ListMeasurementsResponse{
+		Message:      "Measurements retrieved successfully",
+		Success:      true,
+		Measurements: items,
+		WeeklyRate:   weeklyRate,
+	})
+}
+
+func (h *ProfileHandler) CreateMeasurementHandler(w http.ResponseWriter, r *http.Request) {
+	var request MeasurementRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	measuredAt, err := parseMeasuredAt(request.MeasuredAt)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "Invalid 'measured_at'. Use RFC3339, e.g. 2006-01-02T15:04:05Z",
+			Success: false,
+		})
+		return
+	}
+	if message := validateMeasurement(request); message != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: message,
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	measurement, err := h.queries.CreateBodyMeasurement(r.Context(), db.CreateBodyMeasurementParams{
+		UserID:     userID,
+		MeasuredAt: measuredAt,
+		Bodyweight: common.OptionalNumeric(request.Bodyweight),
+		BodyFat:    common.OptionalNumeric(request.BodyFat),
+		Waist:      common.OptionalNumeric(request.Waist),
+		Chest:      common.OptionalNumeric(request.Chest),
+		Arms:       common.OptionalNumeric(request.Arms),
+		Thighs:     common.OptionalNumeric(request.Thighs),
+		Notes:      pgtype.Text{String: request.Notes, Valid: request.Notes != ""},
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "failed to save measurement",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(MeasurementResponse{
+		Message:     "Measurement saved",
+		Success:     true,
+		Measurement: &measurement,
+	})
+}
+
+func (h *ProfileHandler) UpdateMeasurementHandler(w http.ResponseWriter, r *http.Request) {
+	var request MeasurementRequest
+	w.Header().Set("Content-Type", "application/json")
+	measurementID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "Invalid measurement id",
+			Success: false,
+		})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	measuredAt, err := parseMeasuredAt(request.MeasuredAt)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "Invalid 'measured_at'. Use RFC3339, e.g. 2006-01-02T15:04:05Z",
+			Success: false,
+		})
+		return
+	}
+	if message := validateMeasurement(request); message != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: message,
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	measurement, err := h.queries.UpdateBodyMeasurement(r.Context(), db.UpdateBodyMeasurementParams{
+		MeasurementID: measurementID,
+		UserID:        userID,
+		MeasuredAt:    measuredAt,
+		Bodyweight:    common.OptionalNumeric(request.Bodyweight),
+		BodyFat:       common.OptionalNumeric(request.BodyFat),
+		Waist:         common.OptionalNumeric(request.Waist),
+		Chest:         common.OptionalNumeric(request.Chest),
+		Arms:          common.OptionalNumeric(request.Arms),
+		Thighs:        common.OptionalNumeric(request.Thighs),
+		Notes:         pgtype.Text{String: request.Notes, Valid: request.Notes != ""},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "Measurement not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "failed to update measurement",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(MeasurementResponse{
+		Message:     "Measurement updated",
+		Success:     true,
+		Measurement: &measurement,
+	})
+}
+
+func (h *ProfileHandler) DeleteMeasurementHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	measurementID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "Invalid measurement id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	deleted, err := h.queries.DeleteBodyMeasurement(r.Context(), db.DeleteBodyMeasurementParams{
+		MeasurementID: measurementID,
+		UserID:        userID,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "failed to delete measurement",
+			Success: false,
+		})
+		return
+	}
+	if deleted == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(MeasurementResponse{
+			Message: "Measurement not found",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(MeasurementResponse{
+		Message: "Measurement deleted",
+		Success: true,
+	})
+}
@@ -0,0 +1,111 @@
+package profile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestWithTrend(t *testing.T) {
+	start := time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+	weighIn := func(day int, bodyweight float64) db.BodyMeasurement {
+		return db.BodyMeasurement{
+			MeasuredAt: pgtype.Timestamp{Time: start.AddDate(0, 0, day), Valid: true},
+			Bodyweight: common.OptionalNumeric(bodyweight),
+			Waist:      common.OptionalNumeric(85),
+		}
+	}
+
+	tests := []struct {
+		name         string
+		measurements []db.BodyMeasurement
+		trends       []float64 // 0 means no trend for that measurement
+		weeklyRate   float64
+	}{
+		{
+			name:         "single weigh-in",
+			measurements: []db.BodyMeasurement{weighIn(0, 80)},
+			trends:       []float64{80},
+		},
+		{
+			// The 3 and 10 day gaps close more of the distance to the
+			// scale than a single day would
+			name: "irregular gaps",
+			measurements: []db.BodyMeasurement{
+				weighIn(0, 80),
+				weighIn(1, 81),
+				weighIn(4, 79),
+				weighIn(4, 0),
+				weighIn(14, 78),
+			},
+			trends:     []float64{80, 80.1, 79.8, 0, 78.63},
+			weeklyRate: -0.82,
+		},
+		{
+			// Rounding each step would leave the trend stuck at 80
+			name: "small daily changes",
+			measurements: func() []db.BodyMeasurement {
+				measurements := []db.BodyMeasurement{weighIn(0, 80)}
+				for day := 1; day <= 10; day++ {
+					measurements = append(measurements, weighIn(day, 80.04))
+				}
+				return measurements
+			}(),
+			trends:     []float64{80, 80, 80.01, 80.01, 80.01, 80.02, 80.02, 80.02, 80.02, 80.02, 80.03},
+			weeklyRate: 0.02,
+		},
+		{
+			// A second reading at the same time still moves the trend
+			name: "same-time weigh-ins",
+			measurements: []db.BodyMeasurement{
+				weighIn(0, 80),
+				weighIn(0, 82),
+			},
+			trends: []float64{80, 80.2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, weeklyRate := withTrend(tt.measurements)
+			if len(result) != len(tt.trends) {
+				t.Fatalf("withTrend() returned %d measurements, want %d", len(result), len(tt.trends))
+			}
+			for i, item := range result {
+				switch {
+				case tt.trends[i] == 0 && item.Trend != nil:
+					t.Errorf("measurement %d trend = %v, want none", i, *item.Trend)
+				case tt.trends[i] != 0 && item.Trend == nil:
+					t.Errorf("measurement %d has no trend, want %v", i, tt.trends[i])
+				case tt.trends[i] != 0 && *item.Trend != tt.trends[i]:
+					t.Errorf("measurement %d trend = %v, want %v", i, *item.Trend, tt.trends[i])
+				}
+			}
+			if weeklyRate != tt.weeklyRate {
+				t.Errorf("withTrend() weekly rate = %v, want %v", weeklyRate, tt.weeklyRate)
+			}
+		})
+	}
+}
+
+func TestValidateMeasurementBodyFat(t *testing.T) {
+	tests := []struct {
+		bodyFat float64
+		valid   bool
+	}{
+		{bodyFat: 0, valid: true},
+		{bodyFat: 1.5, valid: false},
+		{bodyFat: 2, valid: true},
+		{bodyFat: 70, valid: true},
+		{bodyFat: 71, valid: false},
+	}
+	for _, tt := range tests {
+		msg := validateMeasurement(MeasurementRequest{Bodyweight: 80, BodyFat: tt.bodyFat})
+		if (msg == "") != tt.valid {
+			t.Errorf("validateMeasurement(body_fat %v) = %q, want valid %v", tt.bodyFat, msg, tt.valid)
+		}
+	}
+}
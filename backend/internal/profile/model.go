@@ -0,0 +1,72 @@
+package profile
+
+import (
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+)
+
+// UpdateProfileRequest replaces the whole profile; omitted fields are
+// cleared. Height is in cm and weight in kg whatever preferred_units says.
+type UpdateProfileRequest struct {
+	DateOfBirth    string  `json:"date_of_birth"`
+	Email          string  `json:"email"`
+	Height         float64 `json:"height"`
+	Weight         float64 `json:"weight"`
+	Sex            string  `json:"sex"`
+	BodyFat        float64 `json:"body_fat"`
+	PreferredUnits string  `json:"preferred_units"`
+}
+
+type Profile struct {
+	UserID         int64     `json:"user_id"`
+	DateOfBirth    string    `json:"date_of_birth,omitempty"`
+	Email          string    `json:"email,omitempty"`
+	Height         float64   `json:"height,omitempty"`
+	Weight         float64   `json:"weight,omitempty"`
+	Sex            string    `json:"sex,omitempty"`
+	BodyFat        float64   `json:"body_fat,omitempty"`
+	PreferredUnits string    `json:"preferred_units"`
+	IsTrainer      bool      `json:"is_trainer"`
+	IsVip          bool      `json:"is_vip"`
+	IsAdmin        bool      `json:"is_admin"`
+	LastUpdated    time.Time `json:"last_updated"`
+}
+
+type ProfileResponse struct {
+	Message string   `json:"message"`
+	Success bool     `json:"success"`
+	Profile *Profile `json:"profile,omitempty"`
+}
+
+// MeasurementRequest values are kg, percent and cm; zero means not measured
+type MeasurementRequest struct {
+	MeasuredAt string  `json:"measured_at"`
+	Bodyweight float64 `json:"bodyweight"`
+	BodyFat    float64 `json:"body_fat"`
+	Waist      float64 `json:"waist"`
+	Chest      float64 `json:"chest"`
+	Arms       float64 `json:"arms"`
+	Thighs     float64 `json:"thighs"`
+	Notes      string  `json:"notes"`
+}
+
+type MeasurementResponse struct {
+	Message     string              `json:"message"`
+	Success     bool                `json:"success"`
+	Measurement *db.BodyMeasurement `json:"measurement,omitempty"`
+}
+
+type MeasurementWithTrend struct {
+	db.BodyMeasurement
+	// Trend is the smoothed bodyweight, set when the measurement has one
+	Trend *float64 `json:"trend,omitempty"`
+}
+
+type ListMeasurementsResponse struct {
+	Message      string                 `json:"message"`
+	Success      bool                   `json:"success"`
+	Measurements []MeasurementWithTrend `json:"measurements"`
+	// WeeklyRate is the change in trend weight per week, in kg
+	WeeklyRate float64 `json:"weekly_rate"`
+}
@@ -0,0 +1,335 @@
+package trainer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Relationship states stored in trainer_clients.status
+const (
+	StatusPending  = "pending"
+	StatusActive   = "active"
+	StatusDeclined = "declined"
+	StatusEnded    = "ended"
+)
+
+type TrainerHandler struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+}
+
+func NewTrainerHandler(q *db.Queries, pool *pgxpool.Pool) *TrainerHandler {
+	return &TrainerHandler{
+		pool:    pool,
+		queries: q,
+	}
+}
+
+func formatTimestamp(ts pgtype.Timestamp) string {
+	if !ts.Valid {
+		return ""
+	}
+	return ts.Time.Format(time.RFC3339)
+}
+
+// InviteClientHandler lets a trainer invite another user by username. The
+// link stays pending until the client accepts it.
+func (h *TrainerHandler) InviteClientHandler(w http.ResponseWriter, r *http.Request) {
+	var request InviteClientRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	request.Username = strings.TrimSpace(request.Username)
+	if request.Username == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "username is required",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	client, err := h.queries.GetUserByUsername(r.Context(), request.Username)
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "User not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "failed to look up user",
+			Success: false,
+		})
+		return
+	}
+	if client.UserID == userID {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "You cannot invite yourself",
+			Success: false,
+		})
+		return
+	}
+
+	relationship, err := h.queries.InviteClient(r.Context(), db.InviteClientParams{
+		TrainerID: userID,
+		ClientID:  client.UserID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "This user is already your client or has a pending invite",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "failed to create invite",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(RelationshipResponse{
+		Message:      "Invite sent",
+		Success:      true,
+		Relationship: &relationship,
+	})
+}
+
+func (h *TrainerHandler) AcceptInviteHandler(w http.ResponseWriter, r *http.Request) {
+	h.respondToInvite(w, r, StatusActive, "Invite accepted")
+}
+
+func (h *TrainerHandler) DeclineInviteHandler(w http.ResponseWriter, r *http.Request) {
+	h.respondToInvite(w, r, StatusDeclined, "Invite declined")
+}
+
+// respondToInvite moves a pending invite addressed to the caller to status
+func (h *TrainerHandler) respondToInvite(w http.ResponseWriter, r *http.Request, status, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	relationshipID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "Invalid invite id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	relationship, err := h.queries.RespondToInvite(r.Context(), db.RespondToInviteParams{
+		Status:         status,
+		RelationshipID: relationshipID,
+		ClientID:       userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "Pending invite not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "failed to update invite",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(RelationshipResponse{
+		Message:      message,
+		Success:      true,
+		Relationship: &relationship,
+	})
+}
+
+// EndRelationshipHandler lets either the trainer or the client end a link,
+// or withdraw a pending invite
+func (h *TrainerHandler) EndRelationshipHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	relationshipID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "Invalid relationship id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	ended, err := h.queries.EndTrainerRelationship(r.Context(), db.EndTrainerRelationshipParams{
+		RelationshipID: relationshipID,
+		UserID:         userID,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "failed to end relationship",
+			Success: false,
+		})
+		return
+	}
+	if ended == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(RelationshipResponse{
+			Message: "Relationship not found",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(RelationshipResponse{
+		Message: "Relationship ended",
+		Success: true,
+	})
+}
+
+// ListClientsHandler returns the caller's active and pending clients
+func (h *TrainerHandler) ListClientsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ListRelationshipsResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	rows, err := h.queries.ListTrainerClients(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ListRelationshipsResponse{
+			Message: fmt.Sprintf("Failed to fetch clients: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	relationships := make([]Relationship, 0, len(rows))
+	for _, row := range rows {
+		relationships = append(relationships, Relationship{
+			RelationshipID: row.RelationshipID,
+			UserID:         row.UserID,
+			Username:       row.Username,
+			Status:         row.Status,
+			InvitedAt:      formatTimestamp(row.InvitedAt),
+			RespondedAt:    formatTimestamp(row.RespondedAt),
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ListRelationshipsResponse{
+		Message:       "Clients retrieved successfully",
+		Success:       true,
+		Relationships: relationships,
+	})
+}
+
+// ListTrainersHandler returns the caller's trainers and the invites waiting
+// for an answer
+func (h *TrainerHandler) ListTrainersHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ListRelationshipsResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	rows, err := h.queries.ListClientTrainers(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ListRelationshipsResponse{
+			Message: fmt.Sprintf("Failed to fetch trainers: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	relationships := make([]Relationship, 0, len(rows))
+	for _, row := range rows {
+		relationships = append(relationships, Relationship{
+			RelationshipID: row.RelationshipID,
+			UserID:         row.UserID,
+			Username:       row.Username,
+			Status:         row.Status,
+			InvitedAt:      formatTimestamp(row.InvitedAt),
+			RespondedAt:    formatTimestamp(row.RespondedAt),
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ListRelationshipsResponse{
+		Message:       "Trainers retrieved successfully",
+		Success:       true,
+		Relationships: relationships,
+	})
+}
@@ -0,0 +1,114 @@
+package trainer
+
+import "github.com/Bughay/Trainer-GO/db"
+
+type InviteClientRequest struct {
+	Username string `json:"username"`
+}
+
+type RelationshipResponse struct {
+	Message      string            `json:"message"`
+	Success      bool              `json:"success"`
+	Relationship *db.TrainerClient `json:"relationship,omitempty"`
+}
+
+// Relationship is one side of a trainer-client link as seen by the other:
+// UserID and Username are the client for a trainer and vice versa
+type Relationship struct {
+	RelationshipID int64  `json:"relationship_id"`
+	UserID         int64  `json:"user_id"`
+	Username       string `json:"username"`
+	Status         string `json:"status"`
+	InvitedAt      string `json:"invited_at"`
+	RespondedAt    string `json:"responded_at,omitempty"`
+}
+
+type ListRelationshipsResponse struct {
+	Message       string         `json:"message"`
+	Success       bool           `json:"success"`
+	Relationships []Relationship `json:"relationships"`
+}
+
+type PrescriptionRequest struct {
+	// ExerciseID picks a catalog exercise directly; otherwise exercise_name
+	// is matched against catalog names and aliases
+	ExerciseID   int64   `json:"exercise_id,omitempty"`
+	ExerciseName string  `json:"exercise_name"`
+	Sets         int     `json:"sets"`
+	Reps         int     `json:"reps"`
+	LoadPercent  float64 `json:"load_percent"` // percent of the client's 1RM
+	RPE          float64 `json:"rpe"`
+	RestSeconds  int     `json:"rest_seconds"`
+	Notes        string  `json:"notes"`
+}
+
+type ProgramDayRequest struct {
+	Week      int                   `json:"week"`
+	Day       int                   `json:"day"`
+	Name      string                `json:"name"`
+	Exercises []PrescriptionRequest `json:"exercises"`
+}
+
+type CreateProgramRequest struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Weeks       int                 `json:"weeks"`
+	Days        []ProgramDayRequest `json:"days"`
+}
+
+type PrescriptionDetail struct {
+	db.ProgramExercise
+	// Actual and Completed are only set on progress reads
+	Actual    []db.ExerciseEntry `json:"actual,omitempty"`
+	Completed *bool              `json:"completed,omitempty"`
+}
+
+type ProgramDayDetail struct {
+	db.ProgramDay
+	Date      string               `json:"date,omitempty"`
+	Exercises []PrescriptionDetail `json:"exercises"`
+}
+
+type ProgramDetail struct {
+	db.Program
+	Days []ProgramDayDetail `json:"days"`
+}
+
+type ProgramResponse struct {
+	Message string         `json:"message"`
+	Success bool           `json:"success"`
+	Program *ProgramDetail `json:"program,omitempty"`
+}
+
+type ListProgramsResponse struct {
+	Message  string       `json:"message"`
+	Success  bool         `json:"success"`
+	Programs []db.Program `json:"programs"`
+}
+
+type AssignProgramRequest struct {
+	ClientID  int64  `json:"client_id"`
+	StartDate string `json:"start_date"` // YYYY-MM-DD, defaults to today
+}
+
+type AssignmentResponse struct {
+	Message    string                `json:"message"`
+	Success    bool                  `json:"success"`
+	Assignment *db.ProgramAssignment `json:"assignment,omitempty"`
+}
+
+type ProgramProgressResponse struct {
+	Message      string `json:"message"`
+	Success      bool   `json:"success"`
+	AssignmentID int64  `json:"assignment_id,omitempty"`
+	ProgramID    int64  `json:"program_id,omitempty"`
+	ProgramName  string `json:"program_name,omitempty"`
+	StartDate    string `json:"start_date,omitempty"`
+	// Prescribed counts the exercises due up to today and Completed those
+	// with at least the target number of sets logged against them.
+	// Adherence is Completed as a percentage of Prescribed.
+	Prescribed int                `json:"prescribed"`
+	Completed  int                `json:"completed"`
+	Adherence  float64            `json:"adherence"`
+	Days       []ProgramDayDetail `json:"days"`
+}
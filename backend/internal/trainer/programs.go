@@ -0,0 +1,572 @@
+package trainer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const maxProgramWeeks = 52
+
+var errExerciseNotFound = errors.New("exercise not found")
+
+// dayDate is the calendar date of a program day for an assignment starting
+// on start
+func dayDate(start time.Time, day db.ProgramDay) time.Time {
+	return start.AddDate(0, 0, int(day.WeekNumber-1)*7+int(day.DayNumber-1))
+}
+
+// validateProgram returns a client-facing message when the program cannot be
+// saved
+func validateProgram(request *CreateProgramRequest) string {
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		return "name is required"
+	}
+	if request.Weeks < 1 || request.Weeks > maxProgramWeeks {
+		return fmt.Sprintf("weeks must be between 1 and %d", maxProgramWeeks)
+	}
+	if len(request.Days) == 0 {
+		return "a program needs at least one day"
+	}
+	seen := make(map[[2]int]bool, len(request.Days))
+	for _, day := range request.Days {
+		if day.Week < 1 || day.Week > request.Weeks {
+			return fmt.Sprintf("day week must be between 1 and %d", request.Weeks)
+		}
+		if day.Day < 1 || day.Day > 7 {
+			return "day must be between 1 and 7"
+		}
+		if seen[[2]int{day.Week, day.Day}] {
+			return fmt.Sprintf("week %d day %d is listed twice", day.Week, day.Day)
+		}
+		seen[[2]int{day.Week, day.Day}] = true
+		if len(day.Exercises) == 0 {
+			return fmt.Sprintf("week %d day %d has no exercises", day.Week, day.Day)
+		}
+		for _, exercise := range day.Exercises {
+			if exercise.ExerciseID == 0 && strings.TrimSpace(exercise.ExerciseName) == "" {
+				return "each exercise needs an exercise_id or exercise_name"
+			}
+			if exercise.Sets <= 0 || exercise.Reps <= 0 {
+				return "sets and reps must be positive"
+			}
+			if exercise.LoadPercent < 0 || exercise.LoadPercent > 100 {
+				return "load_percent must be between 0 and 100"
+			}
+			if exercise.RPE < 0 || exercise.RPE > 10 {
+				return "rpe must be between 0 and 10"
+			}
+			if exercise.RestSeconds < 0 {
+				return "rest_seconds cannot be negative"
+			}
+		}
+	}
+	return ""
+}
+
+// resolveExercise matches a prescription to the catalog the same way logged
+// entries are, so planned and actual share an exercise_id. Unknown names are
+// kept as free text.
+func (h *TrainerHandler) resolveExercise(ctx context.Context, trainerID int64, request PrescriptionRequest) (string, pgtype.Int8, error) {
+	owner := pgtype.Int8{Int64: trainerID, Valid: true}
+	if request.ExerciseID != 0 {
+		exercise, err := h.queries.GetExercise(ctx, db.GetExerciseParams{
+			ExerciseID: request.ExerciseID,
+			UserID:     owner,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", pgtype.Int8{}, errExerciseNotFound
+		}
+		if err != nil {
+			return "", pgtype.Int8{}, err
+		}
+		return exercise.Name, pgtype.Int8{Int64: exercise.ExerciseID, Valid: true}, nil
+	}
+	name := strings.TrimSpace(request.ExerciseName)
+	exercise, err := h.queries.ResolveExercise(ctx, db.ResolveExerciseParams{
+		UserID: owner,
+		Name:   name,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return name, pgtype.Int8{}, nil
+	}
+	if err != nil {
+		return "", pgtype.Int8{}, err
+	}
+	return exercise.Name, pgtype.Int8{Int64: exercise.ExerciseID, Valid: true}, nil
+}
+
+// loadProgramDays returns the program's days with their prescriptions
+func (h *TrainerHandler) loadProgramDays(ctx context.Context, programID int64) ([]ProgramDayDetail, error) {
+	days, err := h.queries.ListProgramDays(ctx, programID)
+	if err != nil {
+		return nil, err
+	}
+	exercises, err := h.queries.ListProgramExercises(ctx, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ProgramDayDetail, 0, len(days))
+	index := make(map[int64]int, len(days))
+	for _, day := range days {
+		index[day.DayID] = len(result)
+		result = append(result, ProgramDayDetail{ProgramDay: day, Exercises: []PrescriptionDetail{}})
+	}
+	for _, exercise := range exercises {
+		if i, found := index[exercise.DayID]; found {
+			result[i].Exercises = append(result[i].Exercises, PrescriptionDetail{ProgramExercise: exercise})
+		}
+	}
+	return result, nil
+}
+
+// CreateProgramHandler stores a program with all of its days and prescribed
+// exercises in one transaction. Only trainers can author programs.
+func (h *TrainerHandler) CreateProgramHandler(w http.ResponseWriter, r *http.Request) {
+	var request CreateProgramRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ProgramResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	if message := validateProgram(&request); message != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ProgramResponse{
+			Message: message,
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ProgramResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	// Resolve every exercise up front so a bad id fails before anything is written
+	for d := range request.Days {
+		for e := range request.Days[d].Exercises {
+			exercise := &request.Days[d].Exercises[e]
+			name, exerciseID, err := h.resolveExercise(r.Context(), userID, *exercise)
+			if errors.Is(err, errExerciseNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(ProgramResponse{
+					Message: fmt.Sprintf("Exercise %d not found", exercise.ExerciseID),
+					Success: false,
+				})
+				return
+			}
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(ProgramResponse{
+					Message: "failed to look up exercise",
+					Success: false,
+				})
+				return
+			}
+			exercise.ExerciseName, exercise.ExerciseID = name, exerciseID.Int64
+		}
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ProgramResponse{
+			Message: "failed to create program",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	program, err := qtx.CreateProgram(r.Context(), db.CreateProgramParams{
+		TrainerID:   userID,
+		Name:        request.Name,
+		Description: pgtype.Text{String: request.Description, Valid: request.Description != ""},
+		Weeks:       int32(request.Weeks),
+	})
+	detail := ProgramDetail{Program: program, Days: []ProgramDayDetail{}}
+	for _, dayRequest := range request.Days {
+		if err != nil {
+			break
+		}
+		var day db.ProgramDay
+		day, err = qtx.CreateProgramDay(r.Context(), db.CreateProgramDayParams{
+			ProgramID:  program.ProgramID,
+			WeekNumber: int32(dayRequest.Week),
+			DayNumber:  int32(dayRequest.Day),
+			Name:       pgtype.Text{String: dayRequest.Name, Valid: dayRequest.Name != ""},
+		})
+		dayDetail := ProgramDayDetail{ProgramDay: day, Exercises: []PrescriptionDetail{}}
+		for i, exercise := range dayRequest.Exercises {
+			if err != nil {
+				break
+			}
+			var prescription db.ProgramExercise
+			prescription, err = qtx.CreateProgramExercise(r.Context(), db.CreateProgramExerciseParams{
+				DayID:         day.DayID,
+				ExerciseOrder: int32(i + 1),
+				ExerciseName:  exercise.ExerciseName,
+				ExerciseID:    pgtype.Int8{Int64: exercise.ExerciseID, Valid: exercise.ExerciseID != 0},
+				TargetSets:    int32(exercise.Sets),
+				TargetReps:    int32(exercise.Reps),
+				LoadPercent:   common.OptionalNumeric(exercise.LoadPercent),
+				TargetRpe:     common.OptionalNumeric(exercise.RPE),
+				RestSeconds:   pgtype.Int4{Int32: int32(exercise.RestSeconds), Valid: exercise.RestSeconds != 0},
+				Notes:         pgtype.Text{String: exercise.Notes, Valid: exercise.Notes != ""},
+			})
+			dayDetail.Exercises = append(dayDetail.Exercises, PrescriptionDetail{ProgramExercise: prescription})
+		}
+		detail.Days = append(detail.Days, dayDetail)
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ProgramResponse{
+			Message: "failed to create program",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ProgramResponse{
+		Message: "Program created",
+		Success: true,
+		Program: &detail,
+	})
+}
+
+func (h *TrainerHandler) ListProgramsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ListProgramsResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	programs, err := h.queries.ListPrograms(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ListProgramsResponse{
+			Message: fmt.Sprintf("Failed to fetch programs: %v", err),
+			Success: false,
+		})
+		return
+	}
+	if programs == nil {
+		programs = []db.Program{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ListProgramsResponse{
+		Message:  "Programs retrieved successfully",
+		Success:  true,
+		Programs: programs,
+	})
+}
+
+func (h *TrainerHandler) GetProgramHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	programID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ProgramResponse{
+			Message: "Invalid program id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ProgramResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	program, err := h.queries.GetProgram(r.Context(), db.GetProgramParams{
+		ProgramID: programID,
+		TrainerID: userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ProgramResponse{
+			Message: "Program not found",
+			Success: false,
+		})
+		return
+	}
+	var days []ProgramDayDetail
+	if err == nil {
+		days, err = h.loadProgramDays(r.Context(), program.ProgramID)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ProgramResponse{
+			Message: fmt.Sprintf("Failed to fetch program: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ProgramResponse{
+		Message: "Program retrieved successfully",
+		Success: true,
+		Program: &ProgramDetail{Program: program, Days: days},
+	})
+}
+
+// AssignProgramHandler starts one of the trainer's programs for a linked
+// client. Any program the client was following is ended.
+func (h *TrainerHandler) AssignProgramHandler(w http.ResponseWriter, r *http.Request) {
+	var request AssignProgramRequest
+	w.Header().Set("Content-Type", "application/json")
+	programID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(AssignmentResponse{
+			Message: "Invalid program id",
+			Success: false,
+		})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(AssignmentResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	startDate := time.Now().UTC().Truncate(24 * time.Hour)
+	if request.StartDate != "" {
+		startDate, err = time.Parse("2006-01-02", request.StartDate)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(AssignmentResponse{
+				Message: "Invalid 'start_date' format. Use YYYY-MM-DD",
+				Success: false,
+			})
+			return
+		}
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(AssignmentResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	program, err := h.queries.GetProgram(r.Context(), db.GetProgramParams{
+		ProgramID: programID,
+		TrainerID: userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(AssignmentResponse{
+			Message: "Program not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(AssignmentResponse{
+			Message: "failed to load program",
+			Success: false,
+		})
+		return
+	}
+	linked, err := h.queries.IsTrainerOfClient(r.Context(), db.IsTrainerOfClientParams{
+		TrainerID: userID,
+		ClientID:  request.ClientID,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(AssignmentResponse{
+			Message: "failed to check client",
+			Success: false,
+		})
+		return
+	}
+	if !linked {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(AssignmentResponse{
+			Message: "You can only assign programs to your active clients",
+			Success: false,
+		})
+		return
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(AssignmentResponse{
+			Message: "failed to assign program",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	err = qtx.EndActiveAssignments(r.Context(), request.ClientID)
+	var assignment db.ProgramAssignment
+	if err == nil {
+		assignment, err = qtx.CreateProgramAssignment(r.Context(), db.CreateProgramAssignmentParams{
+			ProgramID: program.ProgramID,
+			ClientID:  request.ClientID,
+			StartDate: pgtype.Date{Time: startDate, Valid: true},
+		})
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(AssignmentResponse{
+			Message: "failed to assign program",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(AssignmentResponse{
+		Message:    "Program assigned",
+		Success:    true,
+		Assignment: &assignment,
+	})
+}
+
+// ProgramProgressHandler compares the client's active program with the
+// entries logged against each prescription. It serves GET /me/program and,
+// through RequireOwnerOrTrainerOf, the trainer's view of a client.
+func (h *TrainerHandler) ProgramProgressHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ProgramProgressResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	assignment, err := h.queries.GetActiveAssignment(r.Context(), userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ProgramProgressResponse{
+			Message: "No active program",
+			Success: false,
+		})
+		return
+	}
+	var days []ProgramDayDetail
+	if err == nil {
+		days, err = h.loadProgramDays(r.Context(), assignment.ProgramID)
+	}
+	var entries []db.ExerciseEntry
+	if err == nil {
+		entries, err = h.queries.ListPrescriptionEntries(r.Context(), db.ListPrescriptionEntriesParams{
+			UserID:    userID,
+			ProgramID: assignment.ProgramID,
+			Since:     pgtype.Timestamp{Time: assignment.StartDate.Time, Valid: true},
+		})
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ProgramProgressResponse{
+			Message: fmt.Sprintf("Failed to fetch program progress: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	actual := make(map[int64][]db.ExerciseEntry)
+	for _, entry := range entries {
+		actual[entry.PrescriptionID.Int64] = append(actual[entry.PrescriptionID.Int64], entry)
+	}
+
+	response := ProgramProgressResponse{
+		Message:      "Program progress retrieved successfully",
+		Success:      true,
+		AssignmentID: assignment.AssignmentID,
+		ProgramID:    assignment.ProgramID,
+		ProgramName:  assignment.Name,
+		StartDate:    assignment.StartDate.Time.Format("2006-01-02"),
+		Days:         days,
+	}
+	today := time.Now().UTC()
+	for d := range days {
+		date := dayDate(assignment.StartDate.Time, days[d].ProgramDay)
+		days[d].Date = date.Format("2006-01-02")
+		due := !date.After(today)
+		for e := range days[d].Exercises {
+			prescription := &days[d].Exercises[e]
+			prescription.Actual = actual[prescription.PrescriptionID]
+			var loggedSets int32
+			for _, entry := range prescription.Actual {
+				loggedSets += entry.Sets
+			}
+			completed := loggedSets >= prescription.TargetSets
+			prescription.Completed = &completed
+			if due {
+				response.Prescribed++
+				if completed {
+					response.Completed++
+				}
+			}
+		}
+	}
+	if response.Prescribed > 0 {
+		response.Adherence = math.Round(float64(response.Completed)/float64(response.Prescribed)*1000) / 10
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,260 @@
+package training
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// editLifts returns the lifts behind an edited entry. Sets are only replaced
+// when the request carries them; otherwise the saved sets stay, and the
+// summary is taken from them too so it describes the same workout as the
+// e1RM. Only entries without sets take their summary from the request.
+func editLifts(request *UpdateTrainingRequest, stored []db.ExerciseSet) []liftSet {
+	if len(request.SetDetails) > 0 {
+		return requestLifts(request.SetDetails)
+	}
+	if len(stored) == 0 {
+		return summaryLifts(request.Weight, request.Sets, request.Reps, request.RPE)
+	}
+	request.Weight, request.Sets, request.Reps, request.RPE = summarizeSets(storedSetRequests(stored))
+	return storedLifts(stored)
+}
+
+func (h *TrainingHandler) UpdateExerciseEntryHandler(w http.ResponseWriter, r *http.Request) {
+	var request UpdateTrainingRequest
+	w.Header().Set("Content-Type", "application/json")
+	entryID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "Invalid entry id",
+			Success: false,
+		})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	if len(request.SetDetails) > 0 {
+		if message := validateSets(request.SetDetails); message != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ExerciseEntryResponse{
+				Message: message,
+				Success: false,
+			})
+			return
+		}
+		request.Weight, request.Sets, request.Reps, request.RPE = summarizeSets(request.SetDetails)
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+	request.ExerciseName = strings.TrimSpace(request.ExerciseName)
+	exercise, inCatalog, err := h.resolveExercise(r.Context(), userID, request.ExerciseID, request.ExerciseName)
+	if errors.Is(err, errExerciseNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "Exercise not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "failed to look up exercise",
+			Success: false,
+		})
+		return
+	}
+	if inCatalog {
+		request.ExerciseName = exercise.Name
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "failed to update exercise entry",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	previous, err := qtx.GetExerciseEntry(r.Context(), db.GetExerciseEntryParams{
+		EntryID: entryID,
+		UserID:  userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "Exercise entry not found",
+			Success: false,
+		})
+		return
+	}
+	var sets []db.ExerciseSet
+	if err == nil && len(request.SetDetails) == 0 {
+		sets, err = qtx.ListEntrySets(r.Context(), entryID)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "failed to update exercise entry",
+			Success: false,
+		})
+		return
+	}
+	lifts := editLifts(&request, sets)
+	if message := validateExercise(request.ExerciseName, request.Weight, request.Sets, request.Reps, request.RPE); message != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: message,
+			Success: false,
+		})
+		return
+	}
+
+	entry, err := qtx.UpdateExerciseEntry(r.Context(), db.UpdateExerciseEntryParams{
+		EntryID:      entryID,
+		UserID:       userID,
+		ExerciseName: request.ExerciseName,
+		Weight:       Float64ToNumeric(request.Weight),
+		Sets:         int32(request.Sets),
+		Reps:         int32(request.Reps),
+		Rpe:          int32(request.RPE),
+		Notes:        StringToText(request.Notes),
+		ExerciseID:   pgtype.Int8{Int64: exercise.ExerciseID, Valid: inCatalog},
+		Estimated1rm: common.OptionalNumeric(bestOneRepMax(lifts).Estimate),
+	})
+	if err == nil && len(request.SetDetails) > 0 {
+		err = qtx.DeleteEntrySets(r.Context(), entryID)
+		if err == nil {
+			sets, err = insertSets(r.Context(), qtx, entryID, request.SetDetails)
+		}
+	}
+	// The edit may have raised or lowered a record, or moved the entry to
+	// another exercise, so rebuild both sides from history
+	if err == nil {
+		err = rebuildRecords(r.Context(), qtx, userID, previous.ExerciseName, previous.ExerciseID)
+	}
+	if err == nil && entry.ExerciseName != previous.ExerciseName {
+		err = rebuildRecords(r.Context(), qtx, userID, entry.ExerciseName, entry.ExerciseID)
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "failed to update exercise entry",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ExerciseEntryResponse{
+		Message: "Exercise entry updated",
+		Success: true,
+		Entry:   &entry,
+		Sets:    sets,
+	})
+}
+
+func (h *TrainingHandler) DeleteExerciseEntryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	entryID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "Invalid entry id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "failed to delete exercise entry",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	entry, err := qtx.GetExerciseEntry(r.Context(), db.GetExerciseEntryParams{
+		EntryID: entryID,
+		UserID:  userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "Exercise entry not found",
+			Success: false,
+		})
+		return
+	}
+	if err == nil {
+		_, err = qtx.DeleteExerciseEntry(r.Context(), db.DeleteExerciseEntryParams{
+			EntryID: entryID,
+			UserID:  userID,
+		})
+	}
+	// Rebuild so the next best entry takes over any record this one held
+	if err == nil {
+		err = rebuildRecords(r.Context(), qtx, userID, entry.ExerciseName, entry.ExerciseID)
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "failed to delete exercise entry",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ExerciseEntryResponse{
+		Message: "Exercise entry deleted",
+		Success: true,
+	})
+}
@@ -0,0 +1,67 @@
+package training
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/common"
+)
+
+func TestEditLifts(t *testing.T) {
+	stored := []db.ExerciseSet{
+		{SetType: SetTypeWarmup, Weight: Float64ToNumeric(60), Reps: 5, Completed: true},
+		{SetType: SetTypeWorking, Weight: Float64ToNumeric(100), Reps: 5, Rpe: common.OptionalNumeric(8), Completed: true},
+		{SetType: SetTypeWorking, Weight: Float64ToNumeric(105), Reps: 3, Rpe: common.OptionalNumeric(9), Completed: true},
+		{SetType: SetTypeWorking, Weight: Float64ToNumeric(120), Reps: 1, Completed: false},
+	}
+
+	tests := []struct {
+		name   string
+		stored []db.ExerciseSet
+		edit   UpdateTrainingRequest
+		want   UpdateTrainingRequest
+		lifts  []liftSet
+	}{
+		{
+			name:   "summary edit on an entry with stored sets",
+			stored: stored,
+			edit:   UpdateTrainingRequest{ExerciseName: "Squat", Weight: 140, Sets: 1, Reps: 1, RPE: 10},
+			want:   UpdateTrainingRequest{ExerciseName: "Squat", Weight: 105, Sets: 2, Reps: 3, RPE: 9},
+			lifts:  []liftSet{{weight: 100, reps: 5, rpe: 8}, {weight: 105, reps: 3, rpe: 9}},
+		},
+		{
+			name:  "summary edit on an entry without sets",
+			edit:  UpdateTrainingRequest{ExerciseName: "Squat", Weight: 140, Sets: 2, Reps: 1, RPE: 9},
+			want:  UpdateTrainingRequest{ExerciseName: "Squat", Weight: 140, Sets: 2, Reps: 1, RPE: 9},
+			lifts: []liftSet{{weight: 140, reps: 1, rpe: 9}, {weight: 140, reps: 1, rpe: 9}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := tt.edit
+			lifts := editLifts(&request, tt.stored)
+			if request.Weight != tt.want.Weight || request.Sets != tt.want.Sets || request.Reps != tt.want.Reps || request.RPE != tt.want.RPE {
+				t.Errorf("editLifts() summary = (%v, %v, %v, %v), want (%v, %v, %v, %v)",
+					request.Weight, request.Sets, request.Reps, request.RPE,
+					tt.want.Weight, tt.want.Sets, tt.want.Reps, tt.want.RPE)
+			}
+			if !slices.Equal(lifts, tt.lifts) {
+				t.Errorf("editLifts() lifts = %v, want %v", lifts, tt.lifts)
+			}
+		})
+	}
+}
+
+func TestEditLiftsUsesRequestSets(t *testing.T) {
+	request := UpdateTrainingRequest{
+		ExerciseName: "Squat",
+		SetDetails:   []SetRequest{{SetType: SetTypeWorking, Weight: 110, Reps: 2, RPE: 9}},
+	}
+	lifts := editLifts(&request, nil)
+	want := []liftSet{{weight: 110, reps: 2, rpe: 9}}
+	if !slices.Equal(lifts, want) {
+		t.Errorf("editLifts() lifts = %v, want %v", lifts, want)
+	}
+}
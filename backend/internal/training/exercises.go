@@ -0,0 +1,218 @@
+package training
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var errExerciseNotFound = errors.New("exercise not found")
+
+// normalizeTags lower-cases, trims and de-duplicates aliases and muscle names
+// so catalog lookups can compare them directly
+func normalizeTags(values []string) []string {
+	tags := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		tag := strings.ToLower(strings.TrimSpace(value))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// resolveExercise looks an entry's exercise up in the catalog. An explicit id
+// must exist (errExerciseNotFound otherwise); a name that matches nothing is
+// not an error, the entry is then stored with its free-text name only.
+func (h *TrainingHandler) resolveExercise(ctx context.Context, userID, exerciseID int64, name string) (db.Exercise, bool, error) {
+	owner := pgtype.Int8{Int64: userID, Valid: true}
+	if exerciseID != 0 {
+		exercise, err := h.queries.GetExercise(ctx, db.GetExerciseParams{
+			ExerciseID: exerciseID,
+			UserID:     owner,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Exercise{}, false, errExerciseNotFound
+		}
+		return exercise, err == nil, err
+	}
+	if name == "" {
+		return db.Exercise{}, false, nil
+	}
+	exercise, err := h.queries.ResolveExercise(ctx, db.ResolveExerciseParams{
+		UserID: owner,
+		Name:   name,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.Exercise{}, false, nil
+	}
+	return exercise, err == nil, err
+}
+
+// ListExercisesHandler returns the built-in catalog plus the user's custom
+// exercises, optionally filtered by ?q= (name or alias) and ?muscle=
+func (h *TrainingHandler) ListExercisesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ListExercisesResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+	query := r.URL.Query()
+
+	exercises, err := h.queries.ListExercises(r.Context(), db.ListExercisesParams{
+		UserID: pgtype.Int8{Int64: userID, Valid: true},
+		Search: strings.TrimSpace(query.Get("q")),
+		Muscle: strings.ToLower(strings.TrimSpace(query.Get("muscle"))),
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ListExercisesResponse{
+			Message: fmt.Sprintf("Failed to fetch exercises: %v", err),
+			Success: false,
+		})
+		return
+	}
+	if exercises == nil {
+		exercises = []db.Exercise{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ListExercisesResponse{
+		Message:   "Exercises retrieved successfully",
+		Success:   true,
+		Exercises: exercises,
+	})
+}
+
+func (h *TrainingHandler) GetExerciseHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	exerciseID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ExerciseResponse{
+			Message: "Invalid exercise id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ExerciseResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	exercise, _, err := h.resolveExercise(r.Context(), userID, exerciseID, "")
+	if errors.Is(err, errExerciseNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ExerciseResponse{
+			Message: "Exercise not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ExerciseResponse{
+			Message: fmt.Sprintf("Failed to fetch exercise: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ExerciseResponse{
+		Message:  "Exercise retrieved successfully",
+		Success:  true,
+		Exercise: &exercise,
+	})
+}
+
+// CreateExerciseHandler adds a custom exercise visible only to its owner
+func (h *TrainingHandler) CreateExerciseHandler(w http.ResponseWriter, r *http.Request) {
+	var request CreateExerciseRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ExerciseResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ExerciseResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ExerciseResponse{
+			Message: "name is required",
+			Success: false,
+		})
+		return
+	}
+
+	exercise, err := h.queries.CreateExercise(r.Context(), db.CreateExerciseParams{
+		UserID:           pgtype.Int8{Int64: userID, Valid: true},
+		Name:             request.Name,
+		Aliases:          normalizeTags(request.Aliases),
+		PrimaryMuscles:   normalizeTags(request.PrimaryMuscles),
+		SecondaryMuscles: normalizeTags(request.SecondaryMuscles),
+		Equipment:        StringToText(strings.ToLower(strings.TrimSpace(request.Equipment))),
+		MovementPattern:  StringToText(strings.ToLower(strings.TrimSpace(request.MovementPattern))),
+	})
+	if common.IsUniqueViolation(err) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ExerciseResponse{
+			Message: "You already have an exercise with that name",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ExerciseResponse{
+			Message: "failed to create exercise",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ExerciseResponse{
+		Message:  "Exercise created",
+		Success:  true,
+		Exercise: &exercise,
+	})
+}
@@ -2,21 +2,28 @@ package training
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
+	"strings"
 
 	"github.com/Bughay/Trainer-GO/db"
 	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type TrainingHandler struct {
+	pool    *pgxpool.Pool
 	queries *db.Queries
 }
 
-func NewTrainingHandler(q *db.Queries) *TrainingHandler {
+func NewTrainingHandler(q *db.Queries, pool *pgxpool.Pool) *TrainingHandler {
 	return &TrainingHandler{
+		pool:    pool,
 		queries: q,
 	}
 }
@@ -44,6 +51,23 @@ func StringToText(value string) pgtype.Text {
 	return pgtype.Text{String: value, Valid: true}
 }
 
+// validateExercise returns a client-facing message when an entry cannot be saved
+func validateExercise(name string, weight float64, sets, reps int, rpe float64) string {
+	if name == "" {
+		return "exercise_name is required"
+	}
+	if weight < 0 {
+		return "weight cannot be negative"
+	}
+	if sets <= 0 || reps <= 0 {
+		return "sets and reps must be positive"
+	}
+	if rpe < 0 || rpe > 10 {
+		return "rpe must be between 0 and 10"
+	}
+	return ""
+}
+
 func (h *TrainingHandler) LogTrainingHandler(w http.ResponseWriter, r *http.Request) {
 	var request LogTrainingRequest
 	w.Header().Set("Content-Type", "application/json")
@@ -57,7 +81,13 @@ func (h *TrainingHandler) LogTrainingHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	userID, ok := r.Context().Value(auth.UserIDKey).(int64)
+	h.logExercise(w, r, request)
+}
+
+// logExercise validates and stores one exercise entry and writes the response.
+// It backs both POST /training/log and POST /training/sessions/{id}/entries.
+func (h *TrainingHandler) logExercise(w http.ResponseWriter, r *http.Request, request LogTrainingRequest) {
+	principal, ok := auth.PrincipalFromContext(r.Context())
 	if !ok {
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(LogTrainingResponse{
@@ -66,18 +96,161 @@ func (h *TrainingHandler) LogTrainingHandler(w http.ResponseWriter, r *http.Requ
 		})
 		return
 	}
+	userID := principal.UserID
+
+	if len(request.SetDetails) > 0 {
+		if message := validateSets(request.SetDetails); message != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(LogTrainingResponse{
+				Message: message,
+				Success: false,
+			})
+			return
+		}
+		request.Weight, request.Sets, request.Reps, request.RPE = summarizeSets(request.SetDetails)
+	}
+
+	request.ExerciseName = strings.TrimSpace(request.ExerciseName)
+	prescribedExercise := false
+	if request.PrescriptionID != 0 {
+		prescription, err := h.queries.GetAssignedPrescription(r.Context(), db.GetAssignedPrescriptionParams{
+			PrescriptionID: request.PrescriptionID,
+			ClientID:       userID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(LogTrainingResponse{
+				Message: "Prescription not found in your active program",
+				Success: false,
+			})
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(LogTrainingResponse{
+				Message: "failed to load prescription",
+				Success: false,
+			})
+			return
+		}
+		if request.ExerciseID == 0 && request.ExerciseName == "" {
+			request.ExerciseID = prescription.ExerciseID.Int64
+			request.ExerciseName = prescription.ExerciseName
+			prescribedExercise = true
+		}
+	}
+
+	exercise, inCatalog, err := h.resolveExercise(r.Context(), userID, request.ExerciseID, request.ExerciseName)
+	if errors.Is(err, errExerciseNotFound) && prescribedExercise {
+		// The trainer prescribed one of their own custom exercises, which
+		// the client can't see; log it under its name instead
+		exercise, inCatalog, err = h.resolveExercise(r.Context(), userID, 0, request.ExerciseName)
+	}
+	if errors.Is(err, errExerciseNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(LogTrainingResponse{
+			Message: "Exercise not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(LogTrainingResponse{
+			Message: "failed to look up exercise",
+			Success: false,
+		})
+		return
+	}
+	if inCatalog {
+		// Store the canonical name so "BP" and "bench" group with "Bench Press"
+		request.ExerciseName = exercise.Name
+	}
+
+	if message := validateExercise(request.ExerciseName, request.Weight, request.Sets, request.Reps, request.RPE); message != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(LogTrainingResponse{
+			Message: message,
+			Success: false,
+		})
+		return
+	}
+
+	if request.SessionID != 0 {
+		session, err := h.queries.GetWorkoutSession(r.Context(), db.GetWorkoutSessionParams{
+			SessionID: request.SessionID,
+			UserID:    userID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(LogTrainingResponse{
+				Message: "Workout session not found",
+				Success: false,
+			})
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(LogTrainingResponse{
+				Message: "failed to load workout session",
+				Success: false,
+			})
+			return
+		}
+		if session.EndedAt.Valid {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(LogTrainingResponse{
+				Message: "Workout session is already finished",
+				Success: false,
+			})
+			return
+		}
+	}
+
+	lifts := summaryLifts(request.Weight, request.Sets, request.Reps, request.RPE)
+	if len(request.SetDetails) > 0 {
+		lifts = requestLifts(request.SetDetails)
+	}
+	oneRepMax := bestOneRepMax(lifts)
 
 	logExerciseParams := db.LogExerciseParams{
-		UserID:       userID,
-		ExerciseName: request.ExerciseName,
-		Weight:       Float64ToNumeric(request.Weight),
-		Sets:         int32(request.Sets),
-		Reps:         int32(request.Reps),
-		Rpe:          int32(request.RPE),
-		Notes:        StringToText(request.Notes),
+		UserID:         userID,
+		ExerciseName:   request.ExerciseName,
+		Weight:         Float64ToNumeric(request.Weight),
+		Sets:           int32(request.Sets),
+		Reps:           int32(request.Reps),
+		Rpe:            int32(request.RPE),
+		Notes:          StringToText(request.Notes),
+		SessionID:      pgtype.Int8{Int64: request.SessionID, Valid: request.SessionID != 0},
+		ExerciseID:     pgtype.Int8{Int64: exercise.ExerciseID, Valid: inCatalog},
+		Estimated1rm:   common.OptionalNumeric(oneRepMax.Estimate),
+		PrescriptionID: pgtype.Int8{Int64: request.PrescriptionID, Valid: request.PrescriptionID != 0},
 	}
 
-	exerciseEntry, err := h.queries.LogExercise(r.Context(), logExerciseParams)
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(LogTrainingResponse{
+			Message: "failed to log exercise",
+			Success: false,
+		})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := h.queries.WithTx(tx)
+
+	exerciseEntry, err := qtx.LogExercise(r.Context(), logExerciseParams)
+	var sets []db.ExerciseSet
+	if err == nil && len(request.SetDetails) > 0 {
+		sets, err = insertSets(r.Context(), qtx, exerciseEntry.EntryID, request.SetDetails)
+	}
+	var records []db.PersonalRecord
+	if err == nil {
+		records, err = updateRecords(r.Context(), qtx, exerciseEntry, lifts)
+	}
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
 
 	if err != nil {
 		fmt.Println("Error logging exercise:", err)
@@ -92,8 +265,14 @@ func (h *TrainingHandler) LogTrainingHandler(w http.ResponseWriter, r *http.Requ
 	fmt.Println("Logged exercise:", exerciseEntry)
 
 	response := LogTrainingResponse{
-		Message: "success",
-		Success: true,
+		Message:    "success",
+		Success:    true,
+		Entry:      &exerciseEntry,
+		Sets:       sets,
+		NewRecords: records,
+	}
+	if oneRepMax.Estimate > 0 {
+		response.OneRepMax = &oneRepMax
 	}
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
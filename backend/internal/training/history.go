@@ -0,0 +1,250 @@
+package training
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// encodeCursor packs the sort key of the last returned entry into an opaque token
+func encodeCursor(entry db.ExerciseEntry) string {
+	raw := fmt.Sprintf("%s|%d", entry.CreatedAt.Time.Format(time.RFC3339Nano), entry.EntryID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	createdAt, entryID, found := strings.Cut(string(raw), "|")
+	if !found {
+		return time.Time{}, 0, fmt.Errorf("malformed cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	id, err := strconv.ParseInt(entryID, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return t, id, nil
+}
+
+// parseDay parses an optional YYYY-MM-DD query value
+func parseDay(value string) (pgtype.Timestamp, error) {
+	if value == "" {
+		return pgtype.Timestamp{}, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return pgtype.Timestamp{}, err
+	}
+	return pgtype.Timestamp{Time: t, Valid: true}, nil
+}
+
+// ListExerciseEntriesHandler returns the newest entries first. Pass the
+// returned next_cursor back as ?cursor= to fetch the following page.
+func (h *TrainingHandler) ListExerciseEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+
+	dateFrom, err := parseDay(query.Get("from"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ListExerciseEntriesResponse{
+			Message: "Invalid 'from' date format. Use YYYY-MM-DD",
+			Success: false,
+		})
+		return
+	}
+	dateTo, err := parseDay(query.Get("to"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ListExerciseEntriesResponse{
+			Message: "Invalid 'to' date format. Use YYYY-MM-DD",
+			Success: false,
+		})
+		return
+	}
+	if dateTo.Valid {
+		// 'to' is inclusive, the query compares against the start of the next day
+		dateTo.Time = dateTo.Time.AddDate(0, 0, 1)
+	}
+	if dateFrom.Valid && dateTo.Valid && !dateTo.Time.After(dateFrom.Time) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ListExerciseEntriesResponse{
+			Message: "'to' date must be after 'from' date",
+			Success: false,
+		})
+		return
+	}
+
+	limit := int64(defaultPageLimit)
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err = strconv.ParseInt(limitStr, 10, 32)
+		if err != nil || limit <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ListExerciseEntriesResponse{
+				Message: "'limit' must be a positive integer",
+				Success: false,
+			})
+			return
+		}
+		limit = min(limit, maxPageLimit)
+	}
+
+	params := db.ListExerciseEntriesParams{
+		DateFrom: dateFrom,
+		DateTo:   dateTo,
+		// Fetch one extra row to know whether another page exists
+		PageLimit: int32(limit + 1),
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		createdAt, entryID, err := decodeCursor(cursor)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ListExerciseEntriesResponse{
+				Message: "Invalid cursor",
+				Success: false,
+			})
+			return
+		}
+		params.CursorCreatedAt = pgtype.Timestamp{Time: createdAt, Valid: true}
+		params.CursorEntryID = entryID
+	}
+
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ListExerciseEntriesResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+	params.UserID = userID
+
+	if exerciseName := strings.TrimSpace(query.Get("exercise")); exerciseName != "" {
+		exercise, inCatalog, err := h.resolveExercise(r.Context(), userID, 0, exerciseName)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ListExerciseEntriesResponse{
+				Message: fmt.Sprintf("Failed to look up exercise: %v", err),
+				Success: false,
+			})
+			return
+		}
+		params.ExerciseName = StringToText(exerciseName)
+		if inCatalog {
+			params.ExerciseName = StringToText(exercise.Name)
+			params.ExerciseID = pgtype.Int8{Int64: exercise.ExerciseID, Valid: true}
+		}
+	}
+
+	entries, err := h.queries.ListExerciseEntries(r.Context(), params)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ListExerciseEntriesResponse{
+			Message: fmt.Sprintf("Failed to fetch exercise entries: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	response := ListExerciseEntriesResponse{
+		Message: "Exercise entries retrieved successfully",
+		Success: true,
+		Entries: entries,
+	}
+	if len(entries) > int(limit) {
+		response.Entries = entries[:limit]
+		response.NextCursor = encodeCursor(entries[limit-1])
+	}
+	if response.Entries == nil {
+		response.Entries = []db.ExerciseEntry{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *TrainingHandler) ExerciseHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	exerciseName := strings.TrimSpace(r.PathValue("name"))
+	if exerciseName == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ExerciseHistoryResponse{
+			Message: "exercise name is required",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ExerciseHistoryResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	// Aliases resolve through the catalog, and entries linked to the exercise
+	// are included even if they were logged under another name
+	exercise, inCatalog, err := h.resolveExercise(r.Context(), userID, 0, exerciseName)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ExerciseHistoryResponse{
+			Message: fmt.Sprintf("Failed to look up exercise: %v", err),
+			Success: false,
+		})
+		return
+	}
+	params := db.ListExerciseHistoryParams{
+		UserID:       userID,
+		ExerciseName: exerciseName,
+	}
+	if inCatalog {
+		exerciseName = exercise.Name
+		params.ExerciseName = exercise.Name
+		params.ExerciseID = pgtype.Int8{Int64: exercise.ExerciseID, Valid: true}
+	}
+
+	entries, err := h.queries.ListExerciseHistory(r.Context(), params)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ExerciseHistoryResponse{
+			Message: fmt.Sprintf("Failed to fetch exercise history: %v", err),
+			Success: false,
+		})
+		return
+	}
+	if entries == nil {
+		entries = []db.ExerciseEntry{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ExerciseHistoryResponse{
+		Message:      "Exercise history retrieved successfully",
+		Success:      true,
+		ExerciseName: exerciseName,
+		Entries:      entries,
+	})
+}
@@ -1,15 +1,170 @@
 package training
 
+import "github.com/Bughay/Trainer-GO/db"
+
 type LogTrainingRequest struct {
+	// ExerciseID picks a catalog exercise directly; otherwise exercise_name
+	// is matched against catalog names and aliases
+	ExerciseID   int64   `json:"exercise_id,omitempty"`
 	ExerciseName string  `json:"exercise_name"`
 	Weight       float64 `json:"weight"`
 	Sets         int     `json:"sets"`
 	Reps         int     `json:"reps"`
 	RPE          float64 `json:"rpe"`
 	Notes        string  `json:"notes"`
+	SessionID    int64   `json:"session_id,omitempty"`
+	// PrescriptionID links the entry to the program exercise it fulfils; the
+	// exercise defaults to the prescribed one when none is given
+	PrescriptionID int64 `json:"prescription_id,omitempty"`
+	// SetDetails, when present, replaces weight/sets/reps/rpe: the entry
+	// stores a summary derived from the individual sets
+	SetDetails []SetRequest `json:"set_details,omitempty"`
+}
+
+type SetRequest struct {
+	SetType   string  `json:"set_type"`
+	Weight    float64 `json:"weight"`
+	Reps      int     `json:"reps"`
+	RPE       float64 `json:"rpe"`
+	Completed *bool   `json:"completed"`
 }
 
 type LogTrainingResponse struct {
-	Message string `json:"message"`
-	Success bool   `json:"success"`
+	Message   string            `json:"message"`
+	Success   bool              `json:"success"`
+	Entry     *db.ExerciseEntry `json:"entry,omitempty"`
+	Sets      []db.ExerciseSet  `json:"sets,omitempty"`
+	OneRepMax *OneRepMax        `json:"one_rep_max,omitempty"`
+	// NewRecords lists the personal records this entry set, if any
+	NewRecords []db.PersonalRecord `json:"new_records,omitempty"`
+}
+
+type ExerciseEntryWithSets struct {
+	db.ExerciseEntry
+	Sets []db.ExerciseSet `json:"sets"`
+}
+
+type ListExerciseEntriesResponse struct {
+	Message    string             `json:"message"`
+	Success    bool               `json:"success"`
+	Entries    []db.ExerciseEntry `json:"entries"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type ExerciseHistoryResponse struct {
+	Message      string             `json:"message"`
+	Success      bool               `json:"success"`
+	ExerciseName string             `json:"exercise_name"`
+	Entries      []db.ExerciseEntry `json:"entries"`
+}
+
+type UpdateTrainingRequest struct {
+	ExerciseID   int64        `json:"exercise_id,omitempty"`
+	ExerciseName string       `json:"exercise_name"`
+	Weight       float64      `json:"weight"`
+	Sets         int          `json:"sets"`
+	Reps         int          `json:"reps"`
+	RPE          float64      `json:"rpe"`
+	Notes        string       `json:"notes"`
+	SetDetails   []SetRequest `json:"set_details,omitempty"`
+}
+
+type ExerciseEntryResponse struct {
+	Message string            `json:"message"`
+	Success bool              `json:"success"`
+	Entry   *db.ExerciseEntry `json:"entry,omitempty"`
+	Sets    []db.ExerciseSet  `json:"sets,omitempty"`
+}
+
+type StartSessionRequest struct {
+	Title      string  `json:"title"`
+	Notes      string  `json:"notes"`
+	Bodyweight float64 `json:"bodyweight"`
+	StartedAt  string  `json:"started_at"`
+}
+
+type FinishSessionRequest struct {
+	Notes      string  `json:"notes"`
+	Bodyweight float64 `json:"bodyweight"`
+	SessionRPE int     `json:"session_rpe"`
+	EndedAt    string  `json:"ended_at"`
+}
+
+type WorkoutSessionResponse struct {
+	Message string                  `json:"message"`
+	Success bool                    `json:"success"`
+	Session *db.WorkoutSession      `json:"session,omitempty"`
+	Entries []ExerciseEntryWithSets `json:"entries,omitempty"`
+}
+
+type ListWorkoutSessionsResponse struct {
+	Message  string              `json:"message"`
+	Success  bool                `json:"success"`
+	Sessions []db.WorkoutSession `json:"sessions"`
+}
+
+type CreateExerciseRequest struct {
+	Name             string   `json:"name"`
+	Aliases          []string `json:"aliases"`
+	PrimaryMuscles   []string `json:"primary_muscles"`
+	SecondaryMuscles []string `json:"secondary_muscles"`
+	Equipment        string   `json:"equipment"`
+	MovementPattern  string   `json:"movement_pattern"`
+}
+
+type ExerciseResponse struct {
+	Message  string       `json:"message"`
+	Success  bool         `json:"success"`
+	Exercise *db.Exercise `json:"exercise,omitempty"`
+}
+
+type ListExercisesResponse struct {
+	Message   string        `json:"message"`
+	Success   bool          `json:"success"`
+	Exercises []db.Exercise `json:"exercises"`
+}
+
+type ListPersonalRecordsResponse struct {
+	Message string              `json:"message"`
+	Success bool                `json:"success"`
+	Records []db.PersonalRecord `json:"records"`
+}
+
+type TrainingStatsBucket struct {
+	Start      string  `json:"start"`
+	Entries    int64   `json:"entries"`
+	Sets       int64   `json:"sets"`
+	Tonnage    float64 `json:"tonnage"`
+	AverageRPE float64 `json:"average_rpe"`
+	// RelativeIntensity is the mean top-set weight as a share of the best
+	// estimated 1RM for that exercise up to the day it was lifted
+	RelativeIntensity float64            `json:"relative_intensity"`
+	MuscleSets        map[string]float64 `json:"muscle_sets"`
+}
+
+type TrainingStatsResponse struct {
+	Message string                `json:"message"`
+	Success bool                  `json:"success"`
+	Bucket  string                `json:"bucket,omitempty"`
+	Buckets []TrainingStatsBucket `json:"buckets"`
+}
+
+type PrescribedExercise struct {
+	db.ProgramExercise
+	// SuggestedWeight is load_percent of the client's estimated 1RM, rounded
+	// to the nearest 2.5 kg, when both are known
+	SuggestedWeight float64 `json:"suggested_weight,omitempty"`
+}
+
+type TodayResponse struct {
+	Message     string               `json:"message"`
+	Success     bool                 `json:"success"`
+	Date        string               `json:"date,omitempty"`
+	ProgramID   int64                `json:"program_id,omitempty"`
+	ProgramName string               `json:"program_name,omitempty"`
+	Week        int                  `json:"week,omitempty"`
+	Day         int                  `json:"day,omitempty"`
+	RestDay     bool                 `json:"rest_day"`
+	DayName     string               `json:"day_name,omitempty"`
+	Exercises   []PrescribedExercise `json:"exercises"`
 }
@@ -0,0 +1,251 @@
+package training
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	RecordOneRM   = "1rm"
+	RecordThreeRM = "3rm"
+	RecordFiveRM  = "5rm"
+	RecordVolume  = "volume"
+	RecordE1RM    = "e1rm"
+)
+
+// liftSet is one set that counts towards records and the estimated 1RM
+type liftSet struct {
+	weight float64
+	reps   int
+	rpe    float64
+}
+
+// summaryLifts expands an entry without set details into identical sets
+func summaryLifts(weight float64, sets, reps int, rpe float64) []liftSet {
+	lifts := make([]liftSet, sets)
+	for i := range lifts {
+		lifts[i] = liftSet{weight: weight, reps: reps, rpe: rpe}
+	}
+	return lifts
+}
+
+// requestLifts keeps the completed, non-warm-up sets of a request
+func requestLifts(sets []SetRequest) []liftSet {
+	lifts := make([]liftSet, 0, len(sets))
+	for _, set := range sets {
+		if set.SetType == SetTypeWarmup || (set.Completed != nil && !*set.Completed) {
+			continue
+		}
+		lifts = append(lifts, liftSet{weight: set.Weight, reps: set.Reps, rpe: set.RPE})
+	}
+	return lifts
+}
+
+// storedLifts keeps the completed, non-warm-up sets already saved for an entry
+func storedLifts(sets []db.ExerciseSet) []liftSet {
+	lifts := make([]liftSet, 0, len(sets))
+	for _, set := range sets {
+		if set.SetType == SetTypeWarmup || !set.Completed {
+			continue
+		}
+		lifts = append(lifts, liftSet{
+			weight: common.NumericToFloat64(set.Weight),
+			reps:   int(set.Reps),
+			rpe:    common.NumericToFloat64(set.Rpe),
+		})
+	}
+	return lifts
+}
+
+// bestOneRepMax returns the highest estimate across the sets
+func bestOneRepMax(lifts []liftSet) OneRepMax {
+	var best OneRepMax
+	for _, lift := range lifts {
+		if estimate := estimateOneRepMax(lift.weight, lift.reps, lift.rpe); estimate.Estimate > best.Estimate {
+			best = estimate
+		}
+	}
+	return best
+}
+
+type recordCandidate struct {
+	recordType string
+	value      float64
+	weight     float64
+	reps       int
+}
+
+// recordCandidates lists the best value an entry reaches for each record
+// type. An nRM is the heaviest set of at least n reps; volume is the entry's
+// total tonnage.
+func recordCandidates(lifts []liftSet) []recordCandidate {
+	repMaxes := []struct {
+		recordType string
+		minReps    int
+	}{
+		{RecordOneRM, 1},
+		{RecordThreeRM, 3},
+		{RecordFiveRM, 5},
+	}
+
+	var candidates []recordCandidate
+	for _, repMax := range repMaxes {
+		var best recordCandidate
+		for _, lift := range lifts {
+			if lift.reps >= repMax.minReps && lift.weight > best.value {
+				best = recordCandidate{recordType: repMax.recordType, value: lift.weight, weight: lift.weight, reps: lift.reps}
+			}
+		}
+		if best.value > 0 {
+			candidates = append(candidates, best)
+		}
+	}
+
+	var volume float64
+	var bestEstimate recordCandidate
+	for _, lift := range lifts {
+		volume += lift.weight * float64(lift.reps)
+		if estimate := estimateOneRepMax(lift.weight, lift.reps, lift.rpe).Estimate; estimate > bestEstimate.value {
+			bestEstimate = recordCandidate{recordType: RecordE1RM, value: estimate, weight: lift.weight, reps: lift.reps}
+		}
+	}
+	if volume > 0 {
+		candidates = append(candidates, recordCandidate{recordType: RecordVolume, value: round2(volume)})
+	}
+	if bestEstimate.value > 0 {
+		candidates = append(candidates, bestEstimate)
+	}
+	return candidates
+}
+
+// updateRecords stores every record the entry beats and returns the new ones
+func updateRecords(ctx context.Context, qtx *db.Queries, entry db.ExerciseEntry, lifts []liftSet) ([]db.PersonalRecord, error) {
+	var records []db.PersonalRecord
+	for _, candidate := range recordCandidates(lifts) {
+		params := db.UpsertPersonalRecordParams{
+			UserID:       entry.UserID,
+			ExerciseName: entry.ExerciseName,
+			RecordType:   candidate.recordType,
+			Value:        Float64ToNumeric(candidate.value),
+			EntryID:      pgtype.Int8{Int64: entry.EntryID, Valid: true},
+			AchievedAt:   entry.CreatedAt,
+		}
+		if candidate.reps > 0 {
+			params.Weight = Float64ToNumeric(candidate.weight)
+			params.Reps = pgtype.Int4{Int32: int32(candidate.reps), Valid: true}
+		}
+		record, err := qtx.UpsertPersonalRecord(ctx, params)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// rebuildRecords recomputes an exercise's records from its whole history,
+// used after an entry behind them is edited or deleted
+func rebuildRecords(ctx context.Context, qtx *db.Queries, userID int64, exerciseName string, exerciseID pgtype.Int8) error {
+	err := qtx.DeleteExercisePersonalRecords(ctx, db.DeleteExercisePersonalRecordsParams{
+		UserID:       userID,
+		ExerciseName: exerciseName,
+	})
+	if err != nil {
+		return err
+	}
+	entries, err := qtx.ListExerciseHistory(ctx, db.ListExerciseHistoryParams{
+		UserID:       userID,
+		ExerciseName: exerciseName,
+		ExerciseID:   exerciseID,
+	})
+	if err != nil {
+		return err
+	}
+	entryIDs := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		entryIDs = append(entryIDs, entry.EntryID)
+	}
+	sets, err := qtx.ListSetsForEntries(ctx, entryIDs)
+	if err != nil {
+		return err
+	}
+
+	// History is oldest first, so ties keep the date the record was first set
+	for _, entry := range attachSets(entries, sets) {
+		lifts := storedLifts(entry.Sets)
+		if len(entry.Sets) == 0 {
+			lifts = summaryLifts(common.NumericToFloat64(entry.Weight), int(entry.ExerciseEntry.Sets), int(entry.Reps), float64(entry.Rpe))
+		}
+		entry.ExerciseEntry.ExerciseName = exerciseName
+		if _, err := updateRecords(ctx, qtx, entry.ExerciseEntry, lifts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListPersonalRecordsHandler returns the user's records, optionally for a
+// single ?exercise= (resolved through the catalog)
+func (h *TrainingHandler) ListPersonalRecordsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ListPersonalRecordsResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	params := db.ListPersonalRecordsParams{UserID: userID}
+	if exerciseName := strings.TrimSpace(r.URL.Query().Get("exercise")); exerciseName != "" {
+		exercise, inCatalog, err := h.resolveExercise(r.Context(), userID, 0, exerciseName)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ListPersonalRecordsResponse{
+				Message: fmt.Sprintf("Failed to look up exercise: %v", err),
+				Success: false,
+			})
+			return
+		}
+		if inCatalog {
+			exerciseName = exercise.Name
+		}
+		params.ExerciseName = StringToText(exerciseName)
+	}
+
+	records, err := h.queries.ListPersonalRecords(r.Context(), params)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ListPersonalRecordsResponse{
+			Message: fmt.Sprintf("Failed to fetch personal records: %v", err),
+			Success: false,
+		})
+		return
+	}
+	if records == nil {
+		records = []db.PersonalRecord{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ListPersonalRecordsResponse{
+		Message: "Personal records retrieved successfully",
+		Success: true,
+		Records: records,
+	})
+}
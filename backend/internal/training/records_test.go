@@ -0,0 +1,49 @@
+package training
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRecordCandidates(t *testing.T) {
+	tests := []struct {
+		name  string
+		lifts []liftSet
+		want  []recordCandidate
+	}{
+		{
+			name: "mixed rep ranges",
+			lifts: []liftSet{
+				{weight: 100, reps: 5, rpe: 8},
+				{weight: 110, reps: 3, rpe: 9},
+				{weight: 120, reps: 1},
+			},
+			want: []recordCandidate{
+				{recordType: RecordOneRM, value: 120, weight: 120, reps: 1},
+				{recordType: RecordThreeRM, value: 110, weight: 110, reps: 3},
+				{recordType: RecordFiveRM, value: 100, weight: 100, reps: 5},
+				{recordType: RecordVolume, value: 950},
+				{recordType: RecordE1RM, value: 123.32, weight: 110, reps: 3},
+			},
+		},
+		{
+			name:  "heavier set with fewer reps than the rep max",
+			lifts: []liftSet{{weight: 140, reps: 2}, {weight: 100, reps: 3}},
+			want: []recordCandidate{
+				{recordType: RecordOneRM, value: 140, weight: 140, reps: 2},
+				{recordType: RecordThreeRM, value: 100, weight: 100, reps: 3},
+				{recordType: RecordVolume, value: 580},
+				{recordType: RecordE1RM, value: 146.67, weight: 140, reps: 2},
+			},
+		},
+		{name: "bodyweight sets", lifts: []liftSet{{weight: 0, reps: 12}}},
+		{name: "no lifts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recordCandidates(tt.lifts); !slices.Equal(got, tt.want) {
+				t.Errorf("recordCandidates() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,372 @@
+package training
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const defaultSessionLimit = 20
+
+// parseTimestamp parses an optional RFC3339 timestamp, defaulting to now
+func parseTimestamp(value string) (pgtype.Timestamp, error) {
+	if value == "" {
+		return pgtype.Timestamp{Time: time.Now().UTC(), Valid: true}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return pgtype.Timestamp{}, err
+	}
+	return pgtype.Timestamp{Time: t.UTC(), Valid: true}, nil
+}
+
+func (h *TrainingHandler) StartSessionHandler(w http.ResponseWriter, r *http.Request) {
+	var request StartSessionRequest
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	startedAt, err := parseTimestamp(request.StartedAt)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "Invalid 'started_at'. Use RFC3339, e.g. 2006-01-02T15:04:05Z",
+			Success: false,
+		})
+		return
+	}
+	if request.Bodyweight < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "bodyweight cannot be negative",
+			Success: false,
+		})
+		return
+	}
+	request.Title = strings.TrimSpace(request.Title)
+	if request.Title == "" {
+		request.Title = "Workout"
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	session, err := h.queries.CreateWorkoutSession(r.Context(), db.CreateWorkoutSessionParams{
+		UserID:     userID,
+		Title:      request.Title,
+		Notes:      StringToText(request.Notes),
+		StartedAt:  startedAt,
+		Bodyweight: common.OptionalNumeric(request.Bodyweight),
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "failed to start workout session",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(WorkoutSessionResponse{
+		Message: "Workout session started",
+		Success: true,
+		Session: &session,
+	})
+}
+
+func (h *TrainingHandler) ListSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	limit := int64(defaultSessionLimit)
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.ParseInt(limitStr, 10, 32)
+		if err != nil || parsed <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ListWorkoutSessionsResponse{
+				Message: "'limit' must be a positive integer",
+				Success: false,
+			})
+			return
+		}
+		limit = min(parsed, maxPageLimit)
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ListWorkoutSessionsResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	sessions, err := h.queries.ListWorkoutSessions(r.Context(), db.ListWorkoutSessionsParams{
+		UserID: userID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ListWorkoutSessionsResponse{
+			Message: fmt.Sprintf("Failed to fetch workout sessions: %v", err),
+			Success: false,
+		})
+		return
+	}
+	if sessions == nil {
+		sessions = []db.WorkoutSession{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ListWorkoutSessionsResponse{
+		Message:  "Workout sessions retrieved successfully",
+		Success:  true,
+		Sessions: sessions,
+	})
+}
+
+// GetSessionHandler returns a session together with its exercises in the order they were logged
+func (h *TrainingHandler) GetSessionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	sessionID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "Invalid session id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	session, err := h.queries.GetWorkoutSession(r.Context(), db.GetWorkoutSessionParams{
+		SessionID: sessionID,
+		UserID:    userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "Workout session not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: fmt.Sprintf("Failed to fetch workout session: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	entries, err := h.queries.ListSessionEntries(r.Context(), pgtype.Int8{Int64: session.SessionID, Valid: true})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: fmt.Sprintf("Failed to fetch session entries: %v", err),
+			Success: false,
+		})
+		return
+	}
+	sets, err := h.queries.ListSessionSets(r.Context(), pgtype.Int8{Int64: session.SessionID, Valid: true})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: fmt.Sprintf("Failed to fetch session sets: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(WorkoutSessionResponse{
+		Message: "Workout session retrieved successfully",
+		Success: true,
+		Session: &session,
+		Entries: attachSets(entries, sets),
+	})
+}
+
+func (h *TrainingHandler) AddSessionEntryHandler(w http.ResponseWriter, r *http.Request) {
+	var request LogTrainingRequest
+	w.Header().Set("Content-Type", "application/json")
+	sessionID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(LogTrainingResponse{
+			Message: "Invalid session id",
+			Success: false,
+		})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(LogTrainingResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	request.SessionID = sessionID
+
+	h.logExercise(w, r, request)
+}
+
+func (h *TrainingHandler) FinishSessionHandler(w http.ResponseWriter, r *http.Request) {
+	var request FinishSessionRequest
+	w.Header().Set("Content-Type", "application/json")
+	sessionID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "Invalid session id",
+			Success: false,
+		})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "failed to decode request",
+			Success: false,
+		})
+		return
+	}
+	endedAt, err := parseTimestamp(request.EndedAt)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "Invalid 'ended_at'. Use RFC3339, e.g. 2006-01-02T15:04:05Z",
+			Success: false,
+		})
+		return
+	}
+	if request.SessionRPE < 0 || request.SessionRPE > 10 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "session_rpe must be between 1 and 10, or 0 if not rated",
+			Success: false,
+		})
+		return
+	}
+	if request.Bodyweight < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "bodyweight cannot be negative",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.UserID
+
+	session, err := h.queries.GetWorkoutSession(r.Context(), db.GetWorkoutSessionParams{
+		SessionID: sessionID,
+		UserID:    userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "Workout session not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "failed to load workout session",
+			Success: false,
+		})
+		return
+	}
+	if endedAt.Time.Before(session.StartedAt.Time) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "'ended_at' must be after the session start",
+			Success: false,
+		})
+		return
+	}
+
+	// Fields left out of the request keep the values recorded at start
+	params := db.FinishWorkoutSessionParams{
+		SessionID:  sessionID,
+		UserID:     userID,
+		EndedAt:    endedAt,
+		Notes:      session.Notes,
+		Bodyweight: session.Bodyweight,
+		SessionRpe: IntToInt4(request.SessionRPE),
+	}
+	if request.Notes != "" {
+		params.Notes = StringToText(request.Notes)
+	}
+	if request.Bodyweight != 0 {
+		params.Bodyweight = Float64ToNumeric(request.Bodyweight)
+	}
+
+	finished, err := h.queries.FinishWorkoutSession(r.Context(), params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "Workout session is already finished",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(WorkoutSessionResponse{
+			Message: "failed to finish workout session",
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(WorkoutSessionResponse{
+		Message: "Workout session finished",
+		Success: true,
+		Session: &finished,
+	})
+}
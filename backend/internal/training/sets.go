@@ -0,0 +1,209 @@
+package training
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	SetTypeWarmup  = "warmup"
+	SetTypeWorking = "working"
+	SetTypeDrop    = "drop"
+	SetTypeAMRAP   = "amrap"
+)
+
+// validateSets normalises set types in place and returns a client-facing
+// message when a set cannot be saved
+func validateSets(sets []SetRequest) string {
+	for i := range sets {
+		set := &sets[i]
+		set.SetType = strings.ToLower(strings.TrimSpace(set.SetType))
+		if set.SetType == "" {
+			set.SetType = SetTypeWorking
+		}
+		switch set.SetType {
+		case SetTypeWarmup, SetTypeWorking, SetTypeDrop, SetTypeAMRAP:
+		default:
+			return fmt.Sprintf("set %d: set_type must be one of warmup, working, drop, amrap", i+1)
+		}
+		if set.Weight < 0 {
+			return fmt.Sprintf("set %d: weight cannot be negative", i+1)
+		}
+		if set.Reps < 0 {
+			return fmt.Sprintf("set %d: reps cannot be negative", i+1)
+		}
+		if set.RPE < 0 || set.RPE > 10 {
+			return fmt.Sprintf("set %d: rpe must be between 0 and 10", i+1)
+		}
+	}
+	// Failed sets are kept, but the entry summary needs at least one that
+	// was actually lifted
+	if !slices.ContainsFunc(sets, liftedSet) {
+		return "at least one set must be completed with reps greater than 0"
+	}
+	return ""
+}
+
+// liftedSet reports whether a set counts towards the entry summary: it was
+// completed and at least one rep was done
+func liftedSet(set SetRequest) bool {
+	return set.Reps > 0 && (set.Completed == nil || *set.Completed)
+}
+
+// summarizeSets derives the exercise_entries summary from individual sets:
+// the heaviest non-warm-up set gives weight and reps, the number of
+// non-warm-up sets gives sets, and rpe is the hardest set's rounded RPE.
+// Failed sets (not completed or no reps) are left out, and an entry made
+// only of warm-ups is summarised from those.
+func summarizeSets(sets []SetRequest) (weight float64, count, reps int, rpe float64) {
+	lifted := make([]SetRequest, 0, len(sets))
+	candidates := make([]SetRequest, 0, len(sets))
+	for _, set := range sets {
+		if !liftedSet(set) {
+			continue
+		}
+		lifted = append(lifted, set)
+		if set.SetType != SetTypeWarmup {
+			candidates = append(candidates, set)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = lifted
+	}
+
+	var top SetRequest
+	for i, set := range candidates {
+		if i == 0 || set.Weight > top.Weight || (set.Weight == top.Weight && set.Reps > top.Reps) {
+			top = set
+		}
+		rpe = math.Max(rpe, set.RPE)
+	}
+	return top.Weight, len(candidates), top.Reps, math.Round(rpe)
+}
+
+// storedSetRequests turns saved sets back into request form so they can be
+// summarised the same way as new ones
+func storedSetRequests(sets []db.ExerciseSet) []SetRequest {
+	requests := make([]SetRequest, 0, len(sets))
+	for _, set := range sets {
+		completed := set.Completed
+		requests = append(requests, SetRequest{
+			SetType:   set.SetType,
+			Weight:    common.NumericToFloat64(set.Weight),
+			Reps:      int(set.Reps),
+			RPE:       common.NumericToFloat64(set.Rpe),
+			Completed: &completed,
+		})
+	}
+	return requests
+}
+
+func insertSets(ctx context.Context, qtx *db.Queries, entryID int64, sets []SetRequest) ([]db.ExerciseSet, error) {
+	stored := make([]db.ExerciseSet, 0, len(sets))
+	for i, set := range sets {
+		completed := set.Completed == nil || *set.Completed
+		row, err := qtx.CreateExerciseSet(ctx, db.CreateExerciseSetParams{
+			EntryID:   entryID,
+			SetOrder:  int32(i + 1),
+			SetType:   set.SetType,
+			Weight:    Float64ToNumeric(set.Weight),
+			Reps:      int32(set.Reps),
+			Rpe:       common.OptionalNumeric(set.RPE),
+			Completed: completed,
+		})
+		if err != nil {
+			return nil, err
+		}
+		stored = append(stored, row)
+	}
+	return stored, nil
+}
+
+// attachSets pairs each entry with its sets, keeping the entry order
+func attachSets(entries []db.ExerciseEntry, sets []db.ExerciseSet) []ExerciseEntryWithSets {
+	byEntry := make(map[int64][]db.ExerciseSet)
+	for _, set := range sets {
+		byEntry[set.EntryID] = append(byEntry[set.EntryID], set)
+	}
+	result := make([]ExerciseEntryWithSets, 0, len(entries))
+	for _, entry := range entries {
+		entrySets := byEntry[entry.EntryID]
+		if entrySets == nil {
+			entrySets = []db.ExerciseSet{}
+		}
+		result = append(result, ExerciseEntryWithSets{ExerciseEntry: entry, Sets: entrySets})
+	}
+	return result
+}
+
+func (h *TrainingHandler) GetExerciseEntryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	entryID, err := common.ParseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "Invalid entry id",
+			Success: false,
+		})
+		return
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	entry, err := h.queries.GetExerciseEntry(r.Context(), db.GetExerciseEntryParams{
+		EntryID: entryID,
+		UserID:  userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: "Exercise entry not found",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: fmt.Sprintf("Failed to fetch exercise entry: %v", err),
+			Success: false,
+		})
+		return
+	}
+	sets, err := h.queries.ListEntrySets(r.Context(), entryID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ExerciseEntryResponse{
+			Message: fmt.Sprintf("Failed to fetch sets: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ExerciseEntryResponse{
+		Message: "Exercise entry retrieved successfully",
+		Success: true,
+		Entry:   &entry,
+		Sets:    sets,
+	})
+}
@@ -0,0 +1,76 @@
+package training
+
+import "testing"
+
+func TestSummarizeSets(t *testing.T) {
+	failed := false
+	tests := []struct {
+		name   string
+		sets   []SetRequest
+		weight float64
+		count  int
+		reps   int
+		rpe    float64
+	}{
+		{
+			name: "heaviest working set",
+			sets: []SetRequest{
+				{SetType: SetTypeWarmup, Weight: 60, Reps: 5},
+				{SetType: SetTypeWorking, Weight: 100, Reps: 5, RPE: 7},
+				{SetType: SetTypeWorking, Weight: 110, Reps: 3, RPE: 8.5},
+			},
+			weight: 110, count: 2, reps: 3, rpe: 9,
+		},
+		{
+			name: "failed top single with zero reps",
+			sets: []SetRequest{
+				{SetType: SetTypeWorking, Weight: 180, Reps: 1, RPE: 9},
+				{SetType: SetTypeWorking, Weight: 200, Reps: 0, RPE: 10},
+			},
+			weight: 180, count: 1, reps: 1, rpe: 9,
+		},
+		{
+			name: "top set marked not completed",
+			sets: []SetRequest{
+				{SetType: SetTypeWorking, Weight: 100, Reps: 5},
+				{SetType: SetTypeWorking, Weight: 120, Reps: 2, Completed: &failed},
+			},
+			weight: 100, count: 1, reps: 5,
+		},
+		{
+			name: "warm-ups only",
+			sets: []SetRequest{
+				{SetType: SetTypeWarmup, Weight: 40, Reps: 10},
+				{SetType: SetTypeWarmup, Weight: 60, Reps: 5},
+			},
+			weight: 60, count: 2, reps: 5,
+		},
+		{
+			name: "nothing lifted",
+			sets: []SetRequest{
+				{SetType: SetTypeWorking, Weight: 200, Reps: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weight, count, reps, rpe := summarizeSets(tt.sets)
+			if weight != tt.weight || count != tt.count || reps != tt.reps || rpe != tt.rpe {
+				t.Errorf("summarizeSets() = (%v, %v, %v, %v), want (%v, %v, %v, %v)",
+					weight, count, reps, rpe, tt.weight, tt.count, tt.reps, tt.rpe)
+			}
+		})
+	}
+}
+
+func TestValidateSetsRequiresLiftedSet(t *testing.T) {
+	sets := []SetRequest{{Weight: 200, Reps: 0}}
+	if msg := validateSets(sets); msg == "" {
+		t.Error("validateSets() accepted an entry with no completed sets")
+	}
+	sets = []SetRequest{{Weight: 180, Reps: 1}, {Weight: 200, Reps: 0}}
+	if msg := validateSets(sets); msg != "" {
+		t.Errorf("validateSets() = %q, want no error", msg)
+	}
+}
@@ -0,0 +1,145 @@
+package training
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+)
+
+// Bucket sizes accepted by ?bucket=, passed straight to date_trunc
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+// TrainingStatsHandler is the training counterpart of ViewFoodTotalHandler:
+// tonnage, sets per muscle group, average RPE and relative intensity between
+// ?from= and ?to= (both inclusive), grouped by ?bucket= (default week).
+func (h *TrainingHandler) TrainingStatsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+
+	if query.Get("from") == "" || query.Get("to") == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(TrainingStatsResponse{
+			Message: "'from' and 'to' date parameters are required. Format: YYYY-MM-DD",
+			Success: false,
+		})
+		return
+	}
+	dateFrom, err := parseDay(query.Get("from"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(TrainingStatsResponse{
+			Message: "Invalid 'from' date format. Use YYYY-MM-DD",
+			Success: false,
+		})
+		return
+	}
+	dateTo, err := parseDay(query.Get("to"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(TrainingStatsResponse{
+			Message: "Invalid 'to' date format. Use YYYY-MM-DD",
+			Success: false,
+		})
+		return
+	}
+	if dateTo.Time.Before(dateFrom.Time) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(TrainingStatsResponse{
+			Message: "'to' date must be after 'from' date",
+			Success: false,
+		})
+		return
+	}
+	// 'to' is inclusive, the queries compare against the start of the next day
+	dateTo.Time = dateTo.Time.AddDate(0, 0, 1)
+
+	bucket := query.Get("bucket")
+	if bucket == "" {
+		bucket = BucketWeek
+	}
+	if bucket != BucketDay && bucket != BucketWeek && bucket != BucketMonth {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(TrainingStatsResponse{
+			Message: "'bucket' must be one of day, week, month",
+			Success: false,
+		})
+		return
+	}
+
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(TrainingStatsResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	volume, err := h.queries.TrainingVolumeStats(r.Context(), db.TrainingVolumeStatsParams{
+		UserID:   userID,
+		DateFrom: dateFrom,
+		DateTo:   dateTo,
+		Bucket:   bucket,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(TrainingStatsResponse{
+			Message: fmt.Sprintf("Failed to fetch training stats: %v", err),
+			Success: false,
+		})
+		return
+	}
+	muscles, err := h.queries.MuscleSetStats(r.Context(), db.MuscleSetStatsParams{
+		UserID:   userID,
+		DateFrom: dateFrom,
+		DateTo:   dateTo,
+		Bucket:   bucket,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(TrainingStatsResponse{
+			Message: fmt.Sprintf("Failed to fetch muscle group stats: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	buckets := make([]TrainingStatsBucket, 0, len(volume))
+	index := make(map[time.Time]int, len(volume))
+	for _, row := range volume {
+		index[row.BucketStart.Time] = len(buckets)
+		buckets = append(buckets, TrainingStatsBucket{
+			Start:             row.BucketStart.Time.Format("2006-01-02"),
+			Entries:           row.Entries,
+			Sets:              row.TotalSets,
+			Tonnage:           round2(row.Tonnage),
+			AverageRPE:        round2(row.AverageRpe),
+			RelativeIntensity: math.Round(row.RelativeIntensity*1000) / 1000,
+			MuscleSets:        map[string]float64{},
+		})
+	}
+	for _, row := range muscles {
+		if i, found := index[row.BucketStart.Time]; found {
+			buckets[i].MuscleSets[row.Muscle] = row.Sets
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(TrainingStatsResponse{
+		Message: "Training stats retrieved successfully",
+		Success: true,
+		Bucket:  bucket,
+		Buckets: buckets,
+	})
+}
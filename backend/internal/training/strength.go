@@ -0,0 +1,83 @@
+package training
+
+import "math"
+
+// rpePercentages is the share of 1RM a lifter can move for a set taken to
+// failure (RPE 10), indexed by reps - 1. A set at a lower RPE is read as a
+// set to failure with the reps left in reserve added, so 5 reps @ RPE 8
+// uses the 7-rep entry.
+var rpePercentages = []float64{
+	1.000, 0.955, 0.922, 0.892, 0.863, 0.837,
+	0.811, 0.786, 0.762, 0.739, 0.707, 0.680,
+}
+
+// minTableRPE is the lowest RPE the table is trusted for; below it lifters
+// rate proximity to failure too loosely to estimate from
+const minTableRPE = 6.5
+
+type OneRepMax struct {
+	Epley   float64 `json:"epley"`
+	Brzycki float64 `json:"brzycki"`
+	// RPE is zero when the set has no RPE or falls outside the table
+	RPE float64 `json:"rpe"`
+	// Estimate is the figure stored on the entry: the RPE-adjusted value when
+	// there is one, otherwise the mean of Epley and Brzycki
+	Estimate float64 `json:"estimate"`
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func epley(weight float64, reps int) float64 {
+	if reps == 1 {
+		return weight
+	}
+	return weight * (1 + float64(reps)/30)
+}
+
+// brzycki is undefined from 37 reps, where it returns zero
+func brzycki(weight float64, reps int) float64 {
+	if reps >= 37 {
+		return 0
+	}
+	return weight * 36 / (37 - float64(reps))
+}
+
+// rpeOneRepMax interpolates between table rows for half-point RPEs
+func rpeOneRepMax(weight float64, reps int, rpe float64) float64 {
+	if rpe < minTableRPE || rpe > 10 {
+		return 0
+	}
+	repsToFailure := float64(reps) + 10 - rpe
+	if repsToFailure > float64(len(rpePercentages)) {
+		return 0
+	}
+	lower := int(math.Floor(repsToFailure))
+	upper := int(math.Ceil(repsToFailure))
+	fraction := repsToFailure - float64(lower)
+	percentage := rpePercentages[lower-1] + (rpePercentages[upper-1]-rpePercentages[lower-1])*fraction
+	return weight / percentage
+}
+
+// estimateOneRepMax returns the zero value for sets that cannot be estimated
+// from, such as bodyweight sets logged at 0 kg
+func estimateOneRepMax(weight float64, reps int, rpe float64) OneRepMax {
+	if weight <= 0 || reps <= 0 {
+		return OneRepMax{}
+	}
+	estimate := OneRepMax{
+		Epley:   round2(epley(weight, reps)),
+		Brzycki: round2(brzycki(weight, reps)),
+		RPE:     round2(rpeOneRepMax(weight, reps, rpe)),
+	}
+	switch {
+	case estimate.RPE > 0:
+		estimate.Estimate = estimate.RPE
+	case estimate.Brzycki > 0:
+		estimate.Estimate = round2((estimate.Epley + estimate.Brzycki) / 2)
+	default:
+		estimate.Estimate = estimate.Epley
+	}
+	return estimate
+}
@@ -0,0 +1,74 @@
+package training
+
+import "testing"
+
+func TestRPEOneRepMax(t *testing.T) {
+	tests := []struct {
+		name   string
+		weight float64
+		reps   int
+		rpe    float64
+		want   float64
+	}{
+		{name: "single to failure", weight: 100, reps: 1, rpe: 10, want: 100},
+		{name: "whole RPE", weight: 100, reps: 5, rpe: 8, want: 123.3},
+		{name: "half-point RPE interpolates", weight: 100, reps: 5, rpe: 8.5, want: 121.36},
+		{name: "last table row", weight: 100, reps: 8, rpe: 7, want: 141.44},
+		{name: "half-point between the last rows", weight: 100, reps: 9, rpe: 7.5, want: 144.2},
+		{name: "past the table", weight: 100, reps: 10, rpe: 7, want: 0},
+		{name: "below the trusted RPE", weight: 100, reps: 5, rpe: 6, want: 0},
+		{name: "above RPE 10", weight: 100, reps: 5, rpe: 11, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := round2(rpeOneRepMax(tt.weight, tt.reps, tt.rpe)); got != tt.want {
+				t.Errorf("rpeOneRepMax() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateOneRepMax(t *testing.T) {
+	tests := []struct {
+		name   string
+		weight float64
+		reps   int
+		rpe    float64
+		want   OneRepMax
+	}{
+		{
+			name:   "RPE estimate preferred",
+			weight: 100, reps: 5, rpe: 8,
+			want: OneRepMax{Epley: 116.67, Brzycki: 112.5, RPE: 123.3, Estimate: 123.3},
+		},
+		{
+			name:   "mean of formulas without RPE",
+			weight: 100, reps: 5,
+			want: OneRepMax{Epley: 116.67, Brzycki: 112.5, Estimate: 114.59},
+		},
+		{
+			name:   "mean of formulas outside the RPE table",
+			weight: 100, reps: 10, rpe: 7,
+			want: OneRepMax{Epley: 133.33, Brzycki: 133.33, Estimate: 133.33},
+		},
+		{
+			name:   "last rep count Brzycki covers",
+			weight: 60, reps: 36,
+			want: OneRepMax{Epley: 132, Brzycki: 2160, Estimate: 1146},
+		},
+		{
+			name:   "Epley alone from 37 reps",
+			weight: 50, reps: 37,
+			want: OneRepMax{Epley: 111.67, Estimate: 111.67},
+		},
+		{name: "bodyweight set", weight: 0, reps: 10, want: OneRepMax{}},
+		{name: "no reps", weight: 100, reps: 0, want: OneRepMax{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateOneRepMax(tt.weight, tt.reps, tt.rpe); got != tt.want {
+				t.Errorf("estimateOneRepMax() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
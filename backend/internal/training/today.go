@@ -0,0 +1,142 @@
+package training
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/Bughay/Trainer-GO/db"
+	"github.com/Bughay/Trainer-GO/internal/auth"
+	"github.com/Bughay/Trainer-GO/internal/common"
+	"github.com/jackc/pgx/v5"
+)
+
+// loadIncrement is the smallest jump in suggested weights, in kg
+const loadIncrement = 2.5
+
+// TodayHandler returns the workout prescribed for today, or ?date=, by the
+// user's active program. Weeks and days count from the assignment's start
+// date; a day with nothing prescribed is a rest day.
+func (h *TrainingHandler) TodayHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := r.URL.Query().Get("date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TodayResponse{
+				Message: "Invalid 'date' format. Use YYYY-MM-DD",
+				Success: false,
+			})
+			return
+		}
+		date = parsed
+	}
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(TodayResponse{
+			Message: "Authentication required",
+			Success: false,
+		})
+		return
+	}
+	userID := principal.SubjectID()
+
+	assignment, err := h.queries.GetActiveAssignment(r.Context(), userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(TodayResponse{
+			Message: "No active program",
+			Success: false,
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(TodayResponse{
+			Message: fmt.Sprintf("Failed to fetch program: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	elapsed := int(date.Sub(assignment.StartDate.Time).Hours() / 24)
+	week, day := elapsed/7+1, elapsed%7+1
+	if elapsed < 0 || week > int(assignment.Weeks) {
+		message := "Program has finished"
+		if elapsed < 0 {
+			message = fmt.Sprintf("Program starts on %s", assignment.StartDate.Time.Format("2006-01-02"))
+		}
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(TodayResponse{
+			Message: message,
+			Success: false,
+		})
+		return
+	}
+
+	response := TodayResponse{
+		Message:     "Today's workout retrieved successfully",
+		Success:     true,
+		Date:        date.Format("2006-01-02"),
+		ProgramID:   assignment.ProgramID,
+		ProgramName: assignment.Name,
+		Week:        week,
+		Day:         day,
+		Exercises:   []PrescribedExercise{},
+	}
+
+	programDay, err := h.queries.GetProgramDay(r.Context(), db.GetProgramDayParams{
+		ProgramID:  assignment.ProgramID,
+		WeekNumber: int32(week),
+		DayNumber:  int32(day),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Message = "Rest day"
+		response.RestDay = true
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	var prescriptions []db.ProgramExercise
+	if err == nil {
+		prescriptions, err = h.queries.ListDayExercises(r.Context(), programDay.DayID)
+	}
+	var records []db.PersonalRecord
+	if err == nil {
+		records, err = h.queries.ListPersonalRecords(r.Context(), db.ListPersonalRecordsParams{UserID: userID})
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(TodayResponse{
+			Message: fmt.Sprintf("Failed to fetch today's workout: %v", err),
+			Success: false,
+		})
+		return
+	}
+
+	estimates := make(map[string]float64)
+	for _, record := range records {
+		if record.RecordType == RecordE1RM {
+			estimates[record.ExerciseName] = common.NumericToFloat64(record.Value)
+		}
+	}
+
+	response.DayName = programDay.Name.String
+	for _, prescription := range prescriptions {
+		exercise := PrescribedExercise{ProgramExercise: prescription}
+		percent := common.NumericToFloat64(prescription.LoadPercent)
+		if estimate := estimates[prescription.ExerciseName]; percent > 0 && estimate > 0 {
+			exercise.SuggestedWeight = math.Round(estimate*percent/100/loadIncrement) * loadIncrement
+		}
+		response.Exercises = append(response.Exercises, exercise)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -6,7 +6,7 @@ RETURNING user_id, username;
 -- name: GetUserByUsername :one
 SELECT user_id,username, hashed_password
 FROM users
-WHERE username = $1;
+WHERE lower(username) = lower($1);
 
 -- name: GetUserByID :one
 SELECT user_id,username,hashed_password
@@ -16,56 +16,932 @@ WHERE user_id = $1;
 -- name: CreateFoodItem :one
 INSERT INTO food(user_id,food_name,calories_100,protein_100,carbs_100,fats_100)
 VALUES($1,$2,$3,$4,$5,$6)
-RETURNING user_id,food_name,calories_100,protein_100,carbs_100,fats_100;
+RETURNING *;
 
--- name: CreateFoodCacheItem :one
-INSERT INTO food_Cache(user_id,food_name,calories_100,protein_100,carbs_100,fats_100)
-VALUES($1,$2,$3,$4,$5,$6)
+-- name: UpsertFoodCacheItem :one
+INSERT INTO food_Cache(user_id,food_name,normalized_name,calories_100,protein_100,carbs_100,fats_100)
+VALUES($1,$2,$3,$4,$5,$6,$7)
+ON CONFLICT (user_id, normalized_name, calories_100, protein_100, carbs_100, fats_100)
+DO UPDATE SET food_name = EXCLUDED.food_name,
+              use_count = food_Cache.use_count + 1,
+              last_used = CURRENT_TIMESTAMP,
+              last_updated = CURRENT_TIMESTAMP
 RETURNING *;
 
+-- name: GetFoodCacheItem :one
+SELECT *
+FROM food_Cache
+WHERE food_id = $1 AND user_id = $2;
+
+-- name: TouchFoodCacheItem :exec
+UPDATE food_Cache
+SET use_count = use_count + 1,
+    last_used = CURRENT_TIMESTAMP
+WHERE food_id = $1;
+
+-- name: ListRecentFoods :many
+SELECT *
+FROM food_Cache
+WHERE user_id = $1
+ORDER BY use_count / (1 + EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - last_used)) / 604800.0) DESC,
+         last_used DESC
+LIMIT $2;
+
 -- name: LogFoodItem :one
 INSERT INTO food_entries (
     user_id,
     food_id,
     recipe_id,
+    cache_id,
     calories,
     total_grams,
     protein,
     carbs,
-    fats
+    fats,
+    plan_item_id
 ) VALUES (
     $1,  -- user_id (BIGINT, NOT NULL)
     $2,  -- food_id (BIGINT, can be NULL)
     $3,  -- recipe_id (BIGINT, can be NULL) 
-    $4,  -- calories (DOUBLE PRECISION, NOT NULL)
-    $5,  -- total_grams (DOUBLE PRECISION, NOT NULL)
-    $6,  -- protein (DOUBLE PRECISION, NOT NULL)
-    $7,  -- carbs (DOUBLE PRECISION, NOT NULL)
-    $8   -- fats (DOUBLE PRECISION, NOT NULL)
+    $4,  -- cache_id (BIGINT, can be NULL)
+    $5,  -- calories (DOUBLE PRECISION, NOT NULL)
+    $6,  -- total_grams (DOUBLE PRECISION, NOT NULL)
+    $7,  -- protein (DOUBLE PRECISION, NOT NULL)
+    $8,  -- carbs (DOUBLE PRECISION, NOT NULL)
+    $9,  -- fats (DOUBLE PRECISION, NOT NULL)
+    $10  -- plan_item_id (BIGINT, can be NULL)
 )
 RETURNING *;
 
 -- name: ViewFood :many
-SELECT calories, protein, carbs, fats
-FROM food_entries
-WHERE user_id = $1 
-  AND created_at BETWEEN $2 AND $3
-ORDER BY created_at;
-;
+SELECT fe.nutrition_id, fe.food_id, fe.recipe_id, fe.cache_id,
+       COALESCE(f.food_name, r.recipe_name, fc.food_name)::text AS food_name,
+       fe.total_grams, fe.calories, fe.protein, fe.carbs, fe.fats,
+       fe.created_at, fe.last_updated
+FROM food_entries fe
+LEFT JOIN food f ON f.food_id = fe.food_id
+LEFT JOIN recipes r ON r.recipe_id = fe.recipe_id
+LEFT JOIN food_Cache fc ON fc.food_id = fe.cache_id
+WHERE fe.user_id = $1 
+  AND fe.created_at BETWEEN $2 AND $3
+ORDER BY fe.created_at;
 
 -- name: ViewFoodTotal :one
 SELECT 
-  SUM(calories)::float as total_calories,
-  SUM(protein)::float as total_protein,
-  SUM(carbs)::float as total_carbs,
-  SUM(fats)::float as total_fats
+  COALESCE(SUM(calories), 0)::float as total_calories,
+  COALESCE(SUM(protein), 0)::float as total_protein,
+  COALESCE(SUM(carbs), 0)::float as total_carbs,
+  COALESCE(SUM(fats), 0)::float as total_fats
 FROM food_entries
 WHERE user_id = $1 
   AND created_at BETWEEN $2 AND $3;
 
 
 -- name: LogExercise :one
-INSERT INTO exercise_entries(user_id,exercise_name,weight,sets,reps,rpe,notes)
+INSERT INTO exercise_entries(user_id,exercise_name,weight,sets,reps,rpe,notes,session_id,exercise_id,estimated_1rm,prescription_id)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+RETURNING *;
+
+-- name: CreateRecipe :one
+INSERT INTO recipes(user_id,recipe_name,instructions,servings)
+VALUES($1,$2,$3,$4)
+RETURNING *;
+
+-- name: GetRecipe :one
+SELECT *
+FROM recipes
+WHERE recipe_id = $1 AND user_id = $2;
+
+-- name: ListRecipes :many
+SELECT *
+FROM recipes
+WHERE user_id = $1
+ORDER BY recipe_name;
+
+-- name: UpdateRecipe :one
+UPDATE recipes
+SET recipe_name = $3,
+    instructions = $4,
+    servings = $5,
+    last_updated = CURRENT_TIMESTAMP
+WHERE recipe_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteRecipe :execrows
+DELETE FROM recipes
+WHERE recipe_id = $1 AND user_id = $2;
+
+-- name: AddRecipeIngredient :one
+INSERT INTO recipe_ingredients(recipe_id,food_id,total_grams)
+VALUES($1,$2,$3)
+RETURNING *;
+
+-- name: DeleteRecipeIngredients :exec
+DELETE FROM recipe_ingredients
+WHERE recipe_id = $1;
+
+-- name: ListRecipeIngredients :many
+SELECT ri.ingredient_id, ri.recipe_id, ri.food_id, f.food_name, ri.total_grams,
+       f.calories_100, f.protein_100, f.carbs_100, f.fats_100
+FROM recipe_ingredients ri
+JOIN food f ON f.food_id = ri.food_id
+WHERE ri.recipe_id = $1
+ORDER BY ri.ingredient_id;
+
+-- name: ListUserRecipeIngredients :many
+SELECT ri.ingredient_id, ri.recipe_id, ri.food_id, f.food_name, ri.total_grams,
+       f.calories_100, f.protein_100, f.carbs_100, f.fats_100
+FROM recipe_ingredients ri
+JOIN recipes r ON r.recipe_id = ri.recipe_id
+JOIN food f ON f.food_id = ri.food_id
+WHERE r.user_id = $1
+ORDER BY ri.recipe_id, ri.ingredient_id;
+
+-- name: CountUserFoods :one
+SELECT COUNT(DISTINCT food_id)
+FROM food
+WHERE user_id = @user_id AND food_id = ANY(@food_ids::bigint[]);
+
+-- name: GetFoodItem :one
+SELECT *
+FROM food
+WHERE food_id = $1 AND user_id = $2;
+
+-- name: ListFoodItems :many
+-- The search term is matched literally, with \, % and _ escaped
+SELECT *
+FROM food
+WHERE user_id = @user_id
+  AND food_name ILIKE '%' || replace(replace(replace(@search::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+ORDER BY food_name, food_id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountFoodItems :one
+SELECT COUNT(*)
+FROM food
+WHERE user_id = @user_id
+  AND food_name ILIKE '%' || replace(replace(replace(@search::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\';
+
+-- name: UpdateFoodItem :one
+UPDATE food
+SET food_name = $3,
+    calories_100 = $4,
+    protein_100 = $5,
+    carbs_100 = $6,
+    fats_100 = $7,
+    last_updated = CURRENT_TIMESTAMP
+WHERE food_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteFoodItem :execrows
+DELETE FROM food
+WHERE food_id = $1 AND user_id = $2;
+
+-- name: GetFoodEntry :one
+SELECT *
+FROM food_entries
+WHERE nutrition_id = $1 AND user_id = $2;
+
+-- name: UpdateFoodEntry :one
+UPDATE food_entries
+SET total_grams = $3,
+    calories = $4,
+    protein = $5,
+    carbs = $6,
+    fats = $7,
+    last_updated = CURRENT_TIMESTAMP
+WHERE nutrition_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteFoodEntry :execrows
+DELETE FROM food_entries
+WHERE nutrition_id = $1 AND user_id = $2;
+
+-- name: ListExerciseEntries :many
+SELECT *
+FROM exercise_entries
+WHERE user_id = @user_id
+  AND (sqlc.narg('date_from')::timestamp IS NULL OR created_at >= sqlc.narg('date_from'))
+  AND (sqlc.narg('date_to')::timestamp IS NULL OR created_at < sqlc.narg('date_to'))
+  AND (sqlc.narg('exercise_name')::text IS NULL
+       OR exercise_name = sqlc.narg('exercise_name')
+       OR exercise_id = sqlc.narg('exercise_id')::bigint)
+  AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+       OR (created_at, entry_id) < (sqlc.narg('cursor_created_at'), sqlc.arg('cursor_entry_id')::bigint))
+ORDER BY created_at DESC, entry_id DESC
+LIMIT @page_limit;
+
+-- name: ListExerciseHistory :many
+SELECT *
+FROM exercise_entries
+WHERE user_id = @user_id
+  AND (exercise_name = @exercise_name OR exercise_id = sqlc.narg('exercise_id')::bigint)
+ORDER BY created_at, entry_id;
+
+-- name: UpdateExerciseEntry :one
+UPDATE exercise_entries
+SET exercise_name = $3,
+    weight = $4,
+    sets = $5,
+    reps = $6,
+    rpe = $7,
+    notes = $8,
+    exercise_id = $9,
+    estimated_1rm = $10,
+    last_updated = CURRENT_TIMESTAMP
+WHERE entry_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteExerciseEntry :execrows
+DELETE FROM exercise_entries
+WHERE entry_id = $1 AND user_id = $2;
+
+-- name: CreateWorkoutSession :one
+INSERT INTO workout_sessions(user_id,title,notes,started_at,bodyweight)
+VALUES($1,$2,$3,$4,$5)
+RETURNING *;
+
+-- name: GetWorkoutSession :one
+SELECT *
+FROM workout_sessions
+WHERE session_id = $1 AND user_id = $2;
+
+-- name: ListWorkoutSessions :many
+SELECT *
+FROM workout_sessions
+WHERE user_id = $1
+ORDER BY started_at DESC
+LIMIT $2;
+
+-- name: FinishWorkoutSession :one
+UPDATE workout_sessions
+SET ended_at = $3,
+    notes = $4,
+    bodyweight = $5,
+    session_rpe = $6,
+    last_updated = CURRENT_TIMESTAMP
+WHERE session_id = $1 AND user_id = $2 AND ended_at IS NULL
+RETURNING *;
+
+-- name: ListSessionEntries :many
+SELECT *
+FROM exercise_entries
+WHERE session_id = $1
+ORDER BY created_at, entry_id;
+
+-- name: GetExerciseEntry :one
+SELECT *
+FROM exercise_entries
+WHERE entry_id = $1 AND user_id = $2;
+
+-- name: CreateExerciseSet :one
+INSERT INTO exercise_sets(entry_id,set_order,set_type,weight,reps,rpe,completed)
+VALUES($1,$2,$3,$4,$5,$6,$7)
+RETURNING *;
+
+-- name: ListEntrySets :many
+SELECT *
+FROM exercise_sets
+WHERE entry_id = $1
+ORDER BY set_order;
+
+-- name: ListSessionSets :many
+SELECT s.*
+FROM exercise_sets s
+JOIN exercise_entries e ON e.entry_id = s.entry_id
+WHERE e.session_id = $1
+ORDER BY s.entry_id, s.set_order;
+
+-- name: DeleteEntrySets :exec
+DELETE FROM exercise_sets
+WHERE entry_id = $1;
+
+-- name: ListExercises :many
+-- The search term is matched literally, with \, % and _ escaped
+SELECT *
+FROM exercises
+WHERE (user_id IS NULL OR user_id = @user_id)
+  AND (@search::text = ''
+       OR name ILIKE '%' || replace(replace(replace(@search::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+       OR EXISTS (SELECT 1 FROM unnest(aliases) AS alias WHERE alias ILIKE '%' || replace(replace(replace(@search::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'))
+  AND (@muscle::text = ''
+       OR @muscle::text = ANY(primary_muscles)
+       OR @muscle::text = ANY(secondary_muscles))
+ORDER BY name, exercise_id;
+
+-- name: GetExercise :one
+SELECT *
+FROM exercises
+WHERE exercise_id = $1 AND (user_id IS NULL OR user_id = $2);
+
+-- name: ResolveExercise :one
+-- Matches a canonical name or alias case-insensitively, preferring the
+-- user's own custom exercise over the built-in one
+SELECT *
+FROM exercises
+WHERE (user_id IS NULL OR user_id = @user_id)
+  AND (LOWER(name) = LOWER(@name::text) OR LOWER(@name::text) = ANY(aliases))
+ORDER BY user_id IS NULL, exercise_id
+LIMIT 1;
+
+-- name: CreateExercise :one
+INSERT INTO exercises(user_id,name,aliases,primary_muscles,secondary_muscles,equipment,movement_pattern)
+VALUES($1,$2,$3,$4,$5,$6,$7)
+RETURNING *;
+
+-- name: ListSetsForEntries :many
+SELECT *
+FROM exercise_sets
+WHERE entry_id = ANY(@entry_ids::bigint[])
+ORDER BY entry_id, set_order;
+
+-- name: UpsertPersonalRecord :one
+-- Returns no row when the existing record is at least as good
+INSERT INTO personal_records(user_id,exercise_name,record_type,value,weight,reps,entry_id,achieved_at)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8)
+ON CONFLICT (user_id, exercise_name, record_type) DO UPDATE
+SET value = EXCLUDED.value,
+    weight = EXCLUDED.weight,
+    reps = EXCLUDED.reps,
+    entry_id = EXCLUDED.entry_id,
+    achieved_at = EXCLUDED.achieved_at,
+    last_updated = CURRENT_TIMESTAMP
+WHERE personal_records.value < EXCLUDED.value
+RETURNING *;
+
+-- name: ListPersonalRecords :many
+SELECT *
+FROM personal_records
+WHERE user_id = @user_id
+  AND (sqlc.narg('exercise_name')::text IS NULL OR exercise_name = sqlc.narg('exercise_name'))
+ORDER BY exercise_name, record_type;
+
+-- name: DeleteExercisePersonalRecords :exec
+DELETE FROM personal_records
+WHERE user_id = $1 AND exercise_name = $2;
+
+-- name: TrainingVolumeStats :many
+WITH entry_work AS (
+    SELECT e.created_at,
+           e.rpe,
+           e.weight,
+           COALESCE(s.set_count, e.sets) AS set_count,
+           COALESCE(s.tonnage, e.weight * e.sets * e.reps) AS tonnage,
+           best.e1rm AS best_e1rm
+    FROM exercise_entries e
+    LEFT JOIN LATERAL (
+        -- Entries with set details count their completed working sets,
+        -- older entries fall back to the sets x reps summary
+        SELECT COUNT(*) AS set_count, SUM(es.weight * es.reps) AS tonnage
+        FROM exercise_sets es
+        WHERE es.entry_id = e.entry_id AND es.completed AND es.set_type <> 'warmup'
+        HAVING COUNT(*) > 0
+    ) s ON TRUE
+    LEFT JOIN LATERAL (
+        SELECT MAX(prior.estimated_1rm) AS e1rm
+        FROM exercise_entries prior
+        WHERE prior.user_id = e.user_id
+          AND prior.exercise_name = e.exercise_name
+          AND prior.created_at <= e.created_at
+    ) best ON TRUE
+    WHERE e.user_id = @user_id
+      AND e.created_at >= @date_from
+      AND e.created_at < @date_to
+)
+SELECT date_trunc(@bucket::text, created_at)::timestamp AS bucket_start,
+       COUNT(*) AS entries,
+       COALESCE(SUM(set_count), 0)::bigint AS total_sets,
+       COALESCE(SUM(tonnage), 0)::float AS tonnage,
+       COALESCE(AVG(NULLIF(rpe, 0)), 0)::float AS average_rpe,
+       COALESCE(AVG(weight / NULLIF(best_e1rm, 0)), 0)::float AS relative_intensity
+FROM entry_work
+GROUP BY bucket_start
+ORDER BY bucket_start;
+
+-- name: MuscleSetStats :many
+-- Secondary muscles count as half a set. Entries that are not linked to the
+-- exercise catalog have no muscle groups and are left out.
+WITH entry_sets AS (
+    SELECT e.created_at,
+           e.exercise_id,
+           COALESCE(s.set_count, e.sets) AS set_count
+    FROM exercise_entries e
+    LEFT JOIN LATERAL (
+        SELECT COUNT(*) AS set_count
+        FROM exercise_sets es
+        WHERE es.entry_id = e.entry_id AND es.completed AND es.set_type <> 'warmup'
+        HAVING COUNT(*) > 0
+    ) s ON TRUE
+    WHERE e.user_id = @user_id
+      AND e.created_at >= @date_from
+      AND e.created_at < @date_to
+)
+SELECT date_trunc(@bucket::text, es.created_at)::timestamp AS bucket_start,
+       m.muscle::text AS muscle,
+       SUM(es.set_count * m.share)::float AS sets
+FROM entry_sets es
+JOIN exercises x ON x.exercise_id = es.exercise_id
+CROSS JOIN LATERAL (
+    SELECT unnest(x.primary_muscles) AS muscle, 1.0 AS share
+    UNION ALL
+    SELECT unnest(x.secondary_muscles), 0.5
+) m
+GROUP BY bucket_start, m.muscle
+ORDER BY bucket_start, sets DESC, muscle;
+
+-- name: UpsertNutritionTarget :one
+INSERT INTO nutrition_targets(user_id,day_type,effective_from,calories,protein,carbs,fats)
 VALUES($1,$2,$3,$4,$5,$6,$7)
+ON CONFLICT (user_id, day_type, effective_from) DO UPDATE
+SET calories = EXCLUDED.calories,
+    protein = EXCLUDED.protein,
+    carbs = EXCLUDED.carbs,
+    fats = EXCLUDED.fats,
+    last_updated = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: ListNutritionTargets :many
+SELECT *
+FROM nutrition_targets
+WHERE user_id = $1
+ORDER BY effective_from DESC, day_type;
+
+-- name: DailyFoodTotals :many
+SELECT created_at::date AS day,
+       SUM(calories)::float AS calories,
+       SUM(protein)::float AS protein,
+       SUM(carbs)::float AS carbs,
+       SUM(fats)::float AS fats
+FROM food_entries
+WHERE user_id = @user_id
+  AND created_at >= @date_from
+  AND created_at < @date_to
+GROUP BY day
+ORDER BY day;
+
+-- name: ListTrainingDays :many
+SELECT DISTINCT created_at::date AS day
+FROM exercise_entries
+WHERE user_id = @user_id
+  AND created_at >= @date_from
+  AND created_at < @date_to
+ORDER BY day;
+
+-- name: GetUserProfile :one
+SELECT *
+FROM users_profile
+WHERE user_id = $1;
+
+-- name: ListBodyweightReadings :many
+-- Bodyweight from the measurement log plus any weigh-ins recorded on
+-- workout sessions
+SELECT measured_at, bodyweight::float AS weight
+FROM body_measurements
+WHERE user_id = @user_id
+  AND bodyweight IS NOT NULL
+  AND measured_at >= @date_from
+UNION ALL
+SELECT started_at, bodyweight::float
+FROM workout_sessions
+WHERE user_id = @user_id
+  AND bodyweight IS NOT NULL
+  AND started_at >= @date_from
+ORDER BY measured_at;
+
+-- name: CreateUserProfile :exec
+INSERT INTO users_profile(user_id)
+VALUES($1);
+
+-- name: UpsertUserProfile :one
+INSERT INTO users_profile(user_id,date_of_birth,email,height,weight,sex,body_fat,preferred_units)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8)
+ON CONFLICT (user_id) DO UPDATE
+SET date_of_birth = EXCLUDED.date_of_birth,
+    email = EXCLUDED.email,
+    height = EXCLUDED.height,
+    weight = EXCLUDED.weight,
+    sex = EXCLUDED.sex,
+    body_fat = EXCLUDED.body_fat,
+    preferred_units = EXCLUDED.preferred_units,
+    last_updated = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: CreateBodyMeasurement :one
+INSERT INTO body_measurements(user_id,measured_at,bodyweight,body_fat,waist,chest,arms,thighs,notes)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9)
+RETURNING *;
+
+-- name: ListBodyMeasurements :many
+SELECT *
+FROM body_measurements
+WHERE user_id = @user_id
+  AND (sqlc.narg('date_from')::timestamp IS NULL OR measured_at >= sqlc.narg('date_from'))
+  AND (sqlc.narg('date_to')::timestamp IS NULL OR measured_at < sqlc.narg('date_to'))
+ORDER BY measured_at, measurement_id;
+
+-- name: UpdateBodyMeasurement :one
+UPDATE body_measurements
+SET measured_at = $3,
+    bodyweight = $4,
+    body_fat = $5,
+    waist = $6,
+    chest = $7,
+    arms = $8,
+    thighs = $9,
+    notes = $10,
+    last_updated = CURRENT_TIMESTAMP
+WHERE measurement_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteBodyMeasurement :execrows
+DELETE FROM body_measurements
+WHERE measurement_id = $1 AND user_id = $2;
+
+
+-- name: InviteClient :one
+-- Re-inviting is allowed once an earlier invite was declined or the
+-- relationship ended; a pending or active link returns no rows
+INSERT INTO trainer_clients(trainer_id,client_id)
+VALUES($1,$2)
+ON CONFLICT (trainer_id, client_id) DO UPDATE
+SET status = 'pending',
+    invited_at = CURRENT_TIMESTAMP,
+    responded_at = NULL,
+    last_updated = CURRENT_TIMESTAMP
+WHERE trainer_clients.status IN ('declined', 'ended')
 RETURNING *;
 
+-- name: RespondToInvite :one
+UPDATE trainer_clients
+SET status = @status,
+    responded_at = CURRENT_TIMESTAMP,
+    last_updated = CURRENT_TIMESTAMP
+WHERE relationship_id = @relationship_id
+  AND client_id = @client_id
+  AND status = 'pending'
+RETURNING *;
+
+-- name: EndTrainerRelationship :execrows
+UPDATE trainer_clients
+SET status = 'ended',
+    last_updated = CURRENT_TIMESTAMP
+WHERE relationship_id = @relationship_id
+  AND (trainer_id = @user_id OR client_id = @user_id)
+  AND status IN ('pending', 'active');
+
+-- name: ListTrainerClients :many
+SELECT tc.relationship_id, tc.client_id AS user_id, u.username, tc.status, tc.invited_at, tc.responded_at
+FROM trainer_clients tc
+JOIN users u ON u.user_id = tc.client_id
+WHERE tc.trainer_id = $1
+  AND tc.status IN ('pending', 'active')
+ORDER BY u.username;
+
+-- name: ListClientTrainers :many
+SELECT tc.relationship_id, tc.trainer_id AS user_id, u.username, tc.status, tc.invited_at, tc.responded_at
+FROM trainer_clients tc
+JOIN users u ON u.user_id = tc.trainer_id
+WHERE tc.client_id = $1
+  AND tc.status IN ('pending', 'active')
+ORDER BY tc.invited_at DESC;
+
+-- name: IsTrainerOfClient :one
+-- The trainer must still be flagged as one for the link to count
+SELECT EXISTS (
+    SELECT 1
+    FROM trainer_clients tc
+    JOIN users_profile p ON p.user_id = tc.trainer_id
+    WHERE tc.trainer_id = $1
+      AND tc.client_id = $2
+      AND tc.status = 'active'
+      AND p.is_trainer
+);
+
+-- name: CreateProgram :one
+INSERT INTO programs(trainer_id,name,description,weeks)
+VALUES($1,$2,$3,$4)
+RETURNING *;
+
+-- name: CreateProgramDay :one
+INSERT INTO program_days(program_id,week_number,day_number,name)
+VALUES($1,$2,$3,$4)
+RETURNING *;
+
+-- name: CreateProgramExercise :one
+INSERT INTO program_exercises(day_id,exercise_order,exercise_name,exercise_id,target_sets,target_reps,load_percent,target_rpe,rest_seconds,notes)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+RETURNING *;
+
+-- name: ListPrograms :many
+SELECT *
+FROM programs
+WHERE trainer_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetProgram :one
+SELECT *
+FROM programs
+WHERE program_id = $1 AND trainer_id = $2;
+
+-- name: ListProgramDays :many
+SELECT *
+FROM program_days
+WHERE program_id = $1
+ORDER BY week_number, day_number;
+
+-- name: GetProgramDay :one
+SELECT *
+FROM program_days
+WHERE program_id = $1 AND week_number = $2 AND day_number = $3;
+
+-- name: ListProgramExercises :many
+SELECT pe.*
+FROM program_exercises pe
+JOIN program_days d ON d.day_id = pe.day_id
+WHERE d.program_id = $1
+ORDER BY d.week_number, d.day_number, pe.exercise_order;
+
+-- name: ListDayExercises :many
+SELECT *
+FROM program_exercises
+WHERE day_id = $1
+ORDER BY exercise_order;
+
+-- name: EndActiveAssignments :exec
+UPDATE program_assignments
+SET status = 'ended',
+    last_updated = CURRENT_TIMESTAMP
+WHERE client_id = $1 AND status = 'active';
+
+-- name: CreateProgramAssignment :one
+INSERT INTO program_assignments(program_id,client_id,start_date)
+VALUES($1,$2,$3)
+RETURNING *;
+
+-- name: GetActiveAssignment :one
+SELECT a.assignment_id, a.program_id, a.start_date, p.trainer_id, p.name, p.weeks
+FROM program_assignments a
+JOIN programs p ON p.program_id = a.program_id
+WHERE a.client_id = $1 AND a.status = 'active';
+
+-- name: GetAssignedPrescription :one
+-- Only prescriptions from the client's active program can be logged against
+SELECT pe.*
+FROM program_exercises pe
+JOIN program_days d ON d.day_id = pe.day_id
+JOIN program_assignments a ON a.program_id = d.program_id
+WHERE pe.prescription_id = @prescription_id
+  AND a.client_id = @client_id
+  AND a.status = 'active';
+
+-- name: ListPrescriptionEntries :many
+SELECT e.*
+FROM exercise_entries e
+JOIN program_exercises pe ON pe.prescription_id = e.prescription_id
+JOIN program_days d ON d.day_id = pe.day_id
+WHERE e.user_id = @user_id
+  AND d.program_id = @program_id
+  AND e.created_at >= @since
+ORDER BY e.created_at;
+
+-- name: CreateMealPlan :one
+INSERT INTO meal_plans(trainer_id,name,description,cycle_days)
+VALUES($1,$2,$3,$4)
+RETURNING *;
+
+-- name: CreateMealPlanDay :one
+INSERT INTO meal_plan_days(plan_id,day_number,name)
+VALUES($1,$2,$3)
+RETURNING *;
+
+-- name: CreateMealPlanMeal :one
+INSERT INTO meal_plan_meals(day_id,meal_order,name)
+VALUES($1,$2,$3)
+RETURNING *;
+
+-- name: CreateMealPlanItem :one
+INSERT INTO meal_plan_items(meal_id,item_order,food_id,recipe_id,item_name,total_grams,calories,protein,carbs,fats)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+RETURNING *;
+
+-- name: ListMealPlans :many
+SELECT *
+FROM meal_plans
+WHERE trainer_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetMealPlan :one
+SELECT *
+FROM meal_plans
+WHERE plan_id = $1 AND trainer_id = $2;
+
+-- name: ListMealPlanItems :many
+-- Flattened plan contents, optionally for a single day
+SELECT d.day_id, d.day_number, d.name AS day_name,
+       m.meal_id, m.meal_order, m.name AS meal_name,
+       i.item_id, i.item_order, i.food_id, i.recipe_id, i.item_name,
+       i.total_grams, i.calories, i.protein, i.carbs, i.fats
+FROM meal_plan_days d
+JOIN meal_plan_meals m ON m.day_id = d.day_id
+JOIN meal_plan_items i ON i.meal_id = m.meal_id
+WHERE d.plan_id = @plan_id
+  AND (sqlc.narg('day_number')::int IS NULL OR d.day_number = sqlc.narg('day_number'))
+ORDER BY d.day_number, m.meal_order, i.item_order;
+
+-- name: EndActiveMealPlanAssignments :exec
+UPDATE meal_plan_assignments
+SET status = 'ended',
+    last_updated = CURRENT_TIMESTAMP
+WHERE client_id = $1 AND status = 'active';
+
+-- name: CreateMealPlanAssignment :one
+INSERT INTO meal_plan_assignments(plan_id,client_id,start_date)
+VALUES($1,$2,$3)
+RETURNING *;
+
+-- name: GetActiveMealPlanAssignment :one
+SELECT a.assignment_id, a.plan_id, a.start_date, p.trainer_id, p.name, p.cycle_days
+FROM meal_plan_assignments a
+JOIN meal_plans p ON p.plan_id = a.plan_id
+WHERE a.client_id = $1 AND a.status = 'active';
+
+-- name: GetAssignedPlanItem :one
+-- Only items from the client's active meal plan can be eaten
+SELECT i.*
+FROM meal_plan_items i
+JOIN meal_plan_meals m ON m.meal_id = i.meal_id
+JOIN meal_plan_days d ON d.day_id = m.day_id
+JOIN meal_plan_assignments a ON a.plan_id = d.plan_id
+WHERE i.item_id = @item_id
+  AND a.client_id = @client_id
+  AND a.status = 'active';
+
+-- name: ListPlanItemEntries :many
+SELECT *
+FROM food_entries
+WHERE user_id = @user_id
+  AND plan_item_id IS NOT NULL
+  AND created_at >= @date_from
+  AND created_at < @date_to
+ORDER BY created_at;
+
+-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens(user_id,family_id,token_hash,expires_at)
+VALUES($1,$2,$3,$4)
+RETURNING *;
+
+-- name: GetRefreshTokenByHash :one
+SELECT *
+FROM refresh_tokens
+WHERE token_hash = $1;
+
+-- name: MarkRefreshTokenUsed :execrows
+-- Affects no rows when the token was already used or revoked, which is how
+-- a concurrent replay is detected
+UPDATE refresh_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE token_id = $1 AND used_at IS NULL AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeUserRefreshTokens :exec
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeAccessToken :exec
+INSERT INTO revoked_tokens(jti,user_id,expires_at)
+VALUES($1,$2,$3)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: IsAccessTokenRevoked :one
+SELECT EXISTS (
+    SELECT 1
+    FROM revoked_tokens
+    WHERE jti = $1
+);
+
+-- name: GetUserByEmail :one
+SELECT u.user_id, u.username
+FROM users u
+JOIN users_profile p ON p.user_id = u.user_id
+WHERE lower(p.email) = lower($1);
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET hashed_password = $2
+WHERE user_id = $1;
+
+-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens(user_id,token_hash,expires_at)
+VALUES($1,$2,$3);
+
+-- name: InvalidatePasswordResetTokens :exec
+-- Spends any outstanding tokens so only the most recent email works
+UPDATE password_reset_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: ConsumePasswordResetToken :one
+-- Marks the token used and returns its owner, or no rows when the token is
+-- unknown, spent or expired
+UPDATE password_reset_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+RETURNING user_id;
+
+-- name: DeleteUserPersonalRecords :exec
+DELETE FROM personal_records
+WHERE user_id = $1;
+
+-- name: DeleteUserExerciseEntries :exec
+DELETE FROM exercise_entries
+WHERE user_id = $1;
+
+-- name: DeleteUserWorkoutSessions :exec
+DELETE FROM workout_sessions
+WHERE user_id = $1;
+
+-- name: DeleteUserExercises :exec
+DELETE FROM exercises
+WHERE user_id = $1;
+
+-- name: DeleteUserFoodEntries :exec
+DELETE FROM food_entries
+WHERE user_id = $1;
+
+-- name: DeleteUserRecipeIngredients :exec
+DELETE FROM recipe_ingredients
+WHERE recipe_id IN (SELECT recipe_id FROM recipes WHERE user_id = $1);
+
+-- name: DeleteUserRecipes :exec
+DELETE FROM recipes
+WHERE user_id = $1;
+
+-- name: DeleteUserFoodCache :exec
+DELETE FROM food_Cache
+WHERE user_id = $1;
+
+-- name: DeleteUserFoods :exec
+DELETE FROM food
+WHERE user_id = $1;
+
+-- name: DeleteUserNutritionTargets :exec
+DELETE FROM nutrition_targets
+WHERE user_id = $1;
+
+-- name: DeleteUserMeasurements :exec
+DELETE FROM body_measurements
+WHERE user_id = $1;
+
+-- name: DeleteUser :exec
+-- Tables without ON DELETE CASCADE to users must be cleared first with the
+-- DeleteUser* queries
+DELETE FROM users
+WHERE user_id = $1;
+
+-- name: GetLoginThrottle :one
+SELECT *
+FROM login_throttle
+WHERE throttle_key = $1;
+
+-- name: RecordLoginFailure :one
+-- Counts a failure, starting over when the previous one is older than
+-- window_start
+INSERT INTO login_throttle(throttle_key,failures,last_failure)
+VALUES(@throttle_key,1,@failed_at)
+ON CONFLICT (throttle_key) DO UPDATE
+SET failures = CASE WHEN login_throttle.last_failure < @window_start THEN 1
+                    ELSE login_throttle.failures + 1 END,
+    last_failure = EXCLUDED.last_failure
+RETURNING *;
+
+-- name: ReleaseLoginFailure :exec
+-- Takes back a failure reserved for a login that succeeded or never ran
+UPDATE login_throttle
+SET failures = failures - 1
+WHERE throttle_key = $1 AND failures > 0;
+
+-- name: DeleteStaleLoginThrottles :execrows
+DELETE FROM login_throttle
+WHERE last_failure < $1;
+
+-- name: ResetLoginThrottle :exec
+DELETE FROM login_throttle
+WHERE throttle_key = $1;
+
+-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempts(username,user_id,ip_address,outcome)
+VALUES($1,$2,$3,$4);
+
+-- name: SetUserRoles :one
+UPDATE users_profile
+SET is_trainer = $2,
+    is_vip = $3,
+    is_admin = $4,
+    last_updated = CURRENT_TIMESTAMP
+WHERE user_id = $1
+RETURNING *;
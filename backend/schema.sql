@@ -7,6 +7,71 @@ CREATE TABLE users (
 );
 
 CREATE INDEX idx_users_username ON users(username);
+-- Usernames are unique regardless of case, and login matches them the same way
+CREATE UNIQUE INDEX idx_users_username_lower ON users(lower(username));
+
+-- Refresh tokens are stored as SHA-256 hashes. Each login starts a family;
+-- refreshing marks the presented token used and issues the next one in the
+-- same family, so presenting a used token again revokes the whole family.
+CREATE TABLE refresh_tokens (
+    token_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    family_id VARCHAR(64) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_family ON refresh_tokens(family_id);
+CREATE INDEX idx_refresh_tokens_user ON refresh_tokens(user_id);
+
+-- Access tokens revoked before they expire, by jti. Rows can be deleted once
+-- expires_at has passed.
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    -- Kept after the user is deleted so their last token stays revoked
+    user_id BIGINT REFERENCES users(user_id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Single-use password reset tokens, stored as SHA-256 hashes like refresh
+-- tokens. A token is spent by setting used_at.
+CREATE TABLE password_reset_tokens (
+    token_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_password_reset_tokens_user ON password_reset_tokens(user_id);
+
+-- Failed login counters for the Postgres attempt store, keyed by
+-- "user:<username>" or "ip:<address>". The count restarts once a key has
+-- been quiet for the throttle window.
+CREATE TABLE login_throttle (
+    throttle_key VARCHAR(255) PRIMARY KEY,
+    failures INTEGER NOT NULL,
+    last_failure TIMESTAMP NOT NULL
+);
+
+-- Audit trail of failed and throttled logins
+CREATE TABLE login_attempts (
+    attempt_id BIGSERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    user_id BIGINT REFERENCES users(user_id) ON DELETE SET NULL,  -- NULL for unknown usernames
+    ip_address VARCHAR(64) NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    attempted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_login_attempt_outcome CHECK (outcome IN ('unknown_user', 'bad_password', 'throttled'))
+);
+
+CREATE INDEX idx_login_attempts_username ON login_attempts(lower(username), attempted_at);
+CREATE INDEX idx_login_attempts_ip ON login_attempts(ip_address, attempted_at);
 
 CREATE TABLE users_profile(
     user_id BIGINT PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE NOT NULL,  -- ✅ NOT NULL
@@ -14,11 +79,55 @@ CREATE TABLE users_profile(
     email VARCHAR(255) UNIQUE,
     height DECIMAL,
     weight DECIMAL,
+    sex VARCHAR(6),  -- 'male' or 'female', used by the BMR equations
+    body_fat DECIMAL(4, 1),  -- percent
+    preferred_units VARCHAR(10) NOT NULL DEFAULT 'metric',  -- display only, values are stored in kg and cm
     is_trainer   BOOLEAN NOT NULL DEFAULT FALSE,
     is_vip       BOOLEAN NOT NULL DEFAULT FALSE,
-    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    is_admin     BOOLEAN NOT NULL DEFAULT FALSE,  -- the first admin has to be granted in SQL
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_profile_sex CHECK (sex IN ('male', 'female')),
+    CONSTRAINT chk_profile_units CHECK (preferred_units IN ('metric', 'imperial'))
+);
+
+-- Time series of bodyweight, body fat and circumferences (cm). Any subset
+-- can be recorded on a given day.
+CREATE TABLE body_measurements (
+    measurement_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(user_id),
+    measured_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    bodyweight DECIMAL(10, 2),
+    body_fat DECIMAL(4, 1),
+    waist DECIMAL(10, 1),
+    chest DECIMAL(10, 1),
+    arms DECIMAL(10, 1),
+    thighs DECIMAL(10, 1),
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_measurement_values CHECK (num_nonnulls(bodyweight, body_fat, waist, chest, arms, thighs) > 0)
 );
 
+CREATE INDEX idx_body_measurements_user_measured ON body_measurements(user_id, measured_at);
+
+-- A trainer invites a client, who accepts or declines. Only 'active' links
+-- give the trainer read access to the client's logs.
+CREATE TABLE trainer_clients (
+    relationship_id BIGSERIAL PRIMARY KEY,
+    trainer_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    client_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    invited_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_trainer_client_status CHECK (status IN ('pending', 'active', 'declined', 'ended')),
+    CONSTRAINT chk_trainer_not_client CHECK (trainer_id <> client_id),
+    CONSTRAINT uq_trainer_client UNIQUE (trainer_id, client_id)
+);
+
+CREATE INDEX idx_trainer_clients_client_id ON trainer_clients(client_id);
+
 CREATE TABLE food (
     food_id BIGSERIAL PRIMARY KEY,
     user_id BIGINT REFERENCES users(user_id) NOT NULL,  -- ✅ NOT NULL
@@ -35,12 +144,17 @@ CREATE TABLE food_Cache (
     food_id BIGSERIAL PRIMARY KEY,
     user_id BIGINT REFERENCES users(user_id) NOT NULL,  -- ✅ NOT NULL
     food_name VARCHAR(255) NOT NULL,
+    normalized_name VARCHAR(255) NOT NULL,  -- lower-cased, whitespace-collapsed food_name
     calories_100 DOUBLE PRECISION NOT NULL,
     protein_100 DOUBLE PRECISION NOT NULL,
     carbs_100 DOUBLE PRECISION NOT NULL,
     fats_100 DOUBLE PRECISION NOT NULL,
+    use_count INTEGER NOT NULL DEFAULT 1,
+    last_used TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- One row per user and food: repeated logs bump use_count instead of inserting
+    CONSTRAINT uq_food_cache_entry UNIQUE (user_id, normalized_name, calories_100, protein_100, carbs_100, fats_100)
 );
 
 CREATE TABLE recipes (
@@ -48,6 +162,7 @@ CREATE TABLE recipes (
     user_id BIGINT REFERENCES users(user_id) NOT NULL,  -- ✅ NOT NULL
     recipe_name VARCHAR(255) NOT NULL,
     instructions TEXT,
+    servings INTEGER NOT NULL DEFAULT 1,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
@@ -61,11 +176,74 @@ CREATE TABLE recipe_ingredients (
     last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Trainer-authored meal plans: a cycle of days, each with meals made of
+-- catalog foods or recipes. Items keep a copy of their name and macros so a
+-- client can log them without access to the trainer's catalog.
+CREATE TABLE meal_plans (
+    plan_id BIGSERIAL PRIMARY KEY,
+    trainer_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    cycle_days INTEGER NOT NULL,  -- the plan repeats after this many days
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_meal_plan_cycle CHECK (cycle_days BETWEEN 1 AND 28)
+);
+
+CREATE INDEX idx_meal_plans_trainer_id ON meal_plans(trainer_id);
+
+CREATE TABLE meal_plan_days (
+    day_id BIGSERIAL PRIMARY KEY,
+    plan_id BIGINT NOT NULL REFERENCES meal_plans(plan_id) ON DELETE CASCADE,
+    day_number INTEGER NOT NULL,  -- 1 is the assignment's start date
+    name VARCHAR(255),
+    CONSTRAINT uq_meal_plan_day UNIQUE (plan_id, day_number)
+);
+
+CREATE TABLE meal_plan_meals (
+    meal_id BIGSERIAL PRIMARY KEY,
+    day_id BIGINT NOT NULL REFERENCES meal_plan_days(day_id) ON DELETE CASCADE,
+    meal_order INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    CONSTRAINT uq_meal_plan_meal_order UNIQUE (day_id, meal_order)
+);
+
+CREATE TABLE meal_plan_items (
+    item_id BIGSERIAL PRIMARY KEY,
+    meal_id BIGINT NOT NULL REFERENCES meal_plan_meals(meal_id) ON DELETE CASCADE,
+    item_order INTEGER NOT NULL,
+    food_id BIGINT REFERENCES food(food_id) ON DELETE SET NULL,
+    recipe_id BIGINT REFERENCES recipes(recipe_id) ON DELETE SET NULL,
+    item_name VARCHAR(255) NOT NULL,
+    total_grams DOUBLE PRECISION NOT NULL,
+    calories DOUBLE PRECISION NOT NULL,  -- for total_grams, not per 100g
+    protein DOUBLE PRECISION NOT NULL,
+    carbs DOUBLE PRECISION NOT NULL,
+    fats DOUBLE PRECISION NOT NULL,
+    CONSTRAINT chk_meal_plan_item_grams CHECK (total_grams > 0),
+    CONSTRAINT uq_meal_plan_item_order UNIQUE (meal_id, item_order)
+);
+
+-- A client follows at most one active meal plan at a time
+CREATE TABLE meal_plan_assignments (
+    assignment_id BIGSERIAL PRIMARY KEY,
+    plan_id BIGINT NOT NULL REFERENCES meal_plans(plan_id) ON DELETE CASCADE,
+    client_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_meal_plan_assignment_status CHECK (status IN ('active', 'ended'))
+);
+
+CREATE UNIQUE INDEX idx_meal_plan_assignments_active_client ON meal_plan_assignments(client_id) WHERE status = 'active';
+
 CREATE TABLE food_entries (
     nutrition_id BIGSERIAL PRIMARY KEY,
     user_id BIGINT NOT NULL REFERENCES users(user_id) NOT NULL,  -- ✅ NOT NULL
     food_id BIGINT REFERENCES food(food_id),  -- ❓ Can be NULL if using recipe_id
     recipe_id BIGINT REFERENCES recipes(recipe_id),  -- ❓ Can be NULL if using food_id
+    cache_id BIGINT REFERENCES food_Cache(food_id),  -- ❓ Set for quick-logged foods outside the catalog
     calories DOUBLE PRECISION NOT NULL,
     total_grams DOUBLE PRECISION NOT NULL,
     protein DOUBLE PRECISION NOT NULL,
@@ -73,13 +251,121 @@ CREATE TABLE food_entries (
     fats DOUBLE PRECISION NOT NULL,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    -- Add constraint: either food_id OR recipe_id must be set
+    plan_item_id BIGINT REFERENCES meal_plan_items(item_id) ON DELETE SET NULL,  -- set when eaten from a meal plan
+    -- Add constraint: exactly one of food_id, recipe_id or cache_id must be set
     CONSTRAINT chk_food_or_recipe CHECK (
-        (food_id IS NOT NULL AND recipe_id IS NULL) OR 
-        (food_id IS NULL AND recipe_id IS NOT NULL) 
+        num_nonnulls(food_id, recipe_id, cache_id) = 1
     )
 );
 
+-- Daily calorie and macro goals. A new row takes over from its effective_from
+-- date; 'training' and 'rest' rows override 'any' on those days.
+CREATE TABLE nutrition_targets (
+    target_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(user_id),
+    day_type VARCHAR(10) NOT NULL DEFAULT 'any',
+    effective_from DATE NOT NULL,
+    calories DOUBLE PRECISION NOT NULL,
+    protein DOUBLE PRECISION NOT NULL,
+    carbs DOUBLE PRECISION NOT NULL,
+    fats DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_target_day_type CHECK (day_type IN ('any', 'training', 'rest')),
+    CONSTRAINT uq_nutrition_target UNIQUE (user_id, day_type, effective_from)
+);
+
+
+-- Exercise catalog. Rows with a NULL user_id are the built-in lifts shared by
+-- everyone (see seed.sql); users add their own custom exercises alongside them
+CREATE TABLE exercises (
+    exercise_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT REFERENCES users(user_id),
+    name VARCHAR(255) NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',  -- lower-case alternative names, e.g. 'bp', 'bench'
+    primary_muscles TEXT[] NOT NULL DEFAULT '{}',
+    secondary_muscles TEXT[] NOT NULL DEFAULT '{}',
+    equipment VARCHAR(50),
+    movement_pattern VARCHAR(50),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_exercises_owner_name ON exercises(COALESCE(user_id, 0), LOWER(name));
+CREATE INDEX idx_exercises_aliases ON exercises USING GIN (aliases);
+
+-- Trainer-authored programs. A program is a set of days, each placed at a
+-- week and a day within that week counted from the assignment's start date;
+-- days without a row are rest days.
+CREATE TABLE programs (
+    program_id BIGSERIAL PRIMARY KEY,
+    trainer_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    weeks INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_program_weeks CHECK (weeks BETWEEN 1 AND 52)
+);
+
+CREATE INDEX idx_programs_trainer_id ON programs(trainer_id);
+
+CREATE TABLE program_days (
+    day_id BIGSERIAL PRIMARY KEY,
+    program_id BIGINT NOT NULL REFERENCES programs(program_id) ON DELETE CASCADE,
+    week_number INTEGER NOT NULL,
+    day_number INTEGER NOT NULL,  -- 1 is the weekday the assignment starts on
+    name VARCHAR(255),
+    CONSTRAINT chk_program_day_number CHECK (day_number BETWEEN 1 AND 7),
+    CONSTRAINT uq_program_day UNIQUE (program_id, week_number, day_number)
+);
+
+-- One prescribed exercise on a program day. Load is given as a percentage of
+-- the client's estimated 1RM, a target RPE, or both.
+CREATE TABLE program_exercises (
+    prescription_id BIGSERIAL PRIMARY KEY,
+    day_id BIGINT NOT NULL REFERENCES program_days(day_id) ON DELETE CASCADE,
+    exercise_order INTEGER NOT NULL,
+    exercise_name VARCHAR(255) NOT NULL,
+    exercise_id BIGINT REFERENCES exercises(exercise_id) ON DELETE SET NULL,
+    target_sets INTEGER NOT NULL,
+    target_reps INTEGER NOT NULL,
+    load_percent DECIMAL(5, 2),  -- percent of 1RM
+    target_rpe DECIMAL(3, 1),
+    rest_seconds INTEGER,
+    notes TEXT,
+    CONSTRAINT chk_prescription_targets CHECK (target_sets > 0 AND target_reps > 0),
+    CONSTRAINT uq_prescription_order UNIQUE (day_id, exercise_order)
+);
+
+-- A client follows at most one active program at a time
+CREATE TABLE program_assignments (
+    assignment_id BIGSERIAL PRIMARY KEY,
+    program_id BIGINT NOT NULL REFERENCES programs(program_id) ON DELETE CASCADE,
+    client_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_assignment_status CHECK (status IN ('active', 'ended'))
+);
+
+CREATE UNIQUE INDEX idx_program_assignments_active_client ON program_assignments(client_id) WHERE status = 'active';
+
+CREATE TABLE workout_sessions (
+    session_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(user_id),
+    title VARCHAR(255) NOT NULL,
+    notes TEXT,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ended_at TIMESTAMP,  -- NULL while the session is in progress
+    bodyweight DECIMAL(10, 2),
+    session_rpe INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_workout_sessions_user_started ON workout_sessions(user_id, started_at);
 
 CREATE TABLE exercise_entries (
     entry_id BIGSERIAL PRIMARY KEY,
@@ -91,8 +377,50 @@ CREATE TABLE exercise_entries (
     sets INTEGER NOT NULL,
     reps INTEGER NOT NULL,
     rpe INTEGER NOT NULL,
-    notes TEXT
+    notes TEXT,
+    session_id BIGINT REFERENCES workout_sessions(session_id) ON DELETE SET NULL,
+    exercise_id BIGINT REFERENCES exercises(exercise_id) ON DELETE SET NULL,
+    estimated_1rm DECIMAL(10, 2),
+    prescription_id BIGINT REFERENCES program_exercises(prescription_id) ON DELETE SET NULL  -- the program exercise this entry fulfils
 );
 
 CREATE INDEX idx_exercise_entries_user_created ON exercise_entries(user_id, created_at);
 CREATE INDEX idx_exercise_entries_exercise ON exercise_entries(user_id, exercise_name);
+CREATE INDEX idx_exercise_entries_session ON exercise_entries(session_id);
+CREATE INDEX idx_exercise_entries_exercise_id ON exercise_entries(user_id, exercise_id);
+CREATE INDEX idx_exercise_entries_prescription ON exercise_entries(prescription_id);
+
+-- Individual sets behind an exercise_entries row; the entry keeps a summary
+-- (top set weight/reps, working set count) so older readers still work
+CREATE TABLE exercise_sets (
+    set_id BIGSERIAL PRIMARY KEY,
+    entry_id BIGINT NOT NULL REFERENCES exercise_entries(entry_id) ON DELETE CASCADE,
+    set_order INTEGER NOT NULL,
+    set_type VARCHAR(10) NOT NULL DEFAULT 'working',
+    weight DECIMAL(10, 2) NOT NULL,
+    reps INTEGER NOT NULL,
+    rpe DECIMAL(3, 1),
+    completed BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_set_type CHECK (set_type IN ('warmup', 'working', 'drop', 'amrap')),
+    CONSTRAINT uq_exercise_set_order UNIQUE (entry_id, set_order)
+);
+
+-- Best lift per user, exercise and record type. Rows are replaced whenever a
+-- logged entry beats them and rebuilt when the entry behind one changes.
+CREATE TABLE personal_records (
+    record_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(user_id),
+    exercise_name VARCHAR(255) NOT NULL,
+    record_type VARCHAR(10) NOT NULL,
+    value DECIMAL(10, 2) NOT NULL,
+    weight DECIMAL(10, 2),
+    reps INTEGER,
+    entry_id BIGINT REFERENCES exercise_entries(entry_id) ON DELETE CASCADE,
+    achieved_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_record_type CHECK (record_type IN ('1rm', '3rm', '5rm', 'volume', 'e1rm')),
+    CONSTRAINT uq_personal_record UNIQUE (user_id, exercise_name, record_type)
+);
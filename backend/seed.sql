@@ -0,0 +1,37 @@
+-- Built-in exercise catalog. Run after schema.sql; safe to re-run.
+INSERT INTO exercises(name, aliases, primary_muscles, secondary_muscles, equipment, movement_pattern)
+VALUES
+    ('Back Squat', '{squat,squats,back squats,high bar squat,low bar squat}', '{quads,glutes}', '{hamstrings,lower back,core}', 'barbell', 'squat'),
+    ('Front Squat', '{front squats}', '{quads}', '{glutes,upper back,core}', 'barbell', 'squat'),
+    ('Goblet Squat', '{goblet squats}', '{quads,glutes}', '{core}', 'dumbbell', 'squat'),
+    ('Leg Press', '{leg presses}', '{quads,glutes}', '{hamstrings}', 'machine', 'squat'),
+    ('Bulgarian Split Squat', '{split squat,bss,rear foot elevated split squat}', '{quads,glutes}', '{hamstrings}', 'dumbbell', 'lunge'),
+    ('Walking Lunge', '{lunge,lunges,walking lunges}', '{quads,glutes}', '{hamstrings}', 'dumbbell', 'lunge'),
+    ('Deadlift', '{dl,deadlifts,conventional deadlift}', '{hamstrings,glutes,lower back}', '{quads,upper back,forearms}', 'barbell', 'hinge'),
+    ('Sumo Deadlift', '{sumo,sumo dl}', '{glutes,quads,hamstrings}', '{lower back,upper back}', 'barbell', 'hinge'),
+    ('Romanian Deadlift', '{rdl,rdls,romanian deadlifts}', '{hamstrings,glutes}', '{lower back}', 'barbell', 'hinge'),
+    ('Hip Thrust', '{hip thrusts,barbell hip thrust}', '{glutes}', '{hamstrings}', 'barbell', 'hinge'),
+    ('Leg Curl', '{hamstring curl,lying leg curl,seated leg curl}', '{hamstrings}', '{}', 'machine', 'isolation'),
+    ('Leg Extension', '{leg extensions,quad extension}', '{quads}', '{}', 'machine', 'isolation'),
+    ('Standing Calf Raise', '{calf raise,calf raises}', '{calves}', '{}', 'machine', 'isolation'),
+    ('Bench Press', '{bench,bp,flat bench,barbell bench press}', '{chest}', '{triceps,front delts}', 'barbell', 'horizontal push'),
+    ('Incline Bench Press', '{incline bench,incline bp}', '{chest,front delts}', '{triceps}', 'barbell', 'horizontal push'),
+    ('Dumbbell Bench Press', '{db bench,dumbbell bench,db bench press}', '{chest}', '{triceps,front delts}', 'dumbbell', 'horizontal push'),
+    ('Dip', '{dips,parallel bar dip}', '{chest,triceps}', '{front delts}', 'bodyweight', 'vertical push'),
+    ('Push-Up', '{pushup,pushups,push up,push ups}', '{chest}', '{triceps,front delts,core}', 'bodyweight', 'horizontal push'),
+    ('Overhead Press', '{ohp,press,military press,strict press,shoulder press}', '{front delts}', '{triceps,side delts,upper back}', 'barbell', 'vertical push'),
+    ('Dumbbell Shoulder Press', '{db shoulder press,seated dumbbell press}', '{front delts}', '{triceps,side delts}', 'dumbbell', 'vertical push'),
+    ('Lateral Raise', '{lateral raises,side raise,side raises}', '{side delts}', '{}', 'dumbbell', 'isolation'),
+    ('Barbell Row', '{row,rows,bent over row,bb row,pendlay row}', '{upper back,lats}', '{biceps,rear delts,lower back}', 'barbell', 'horizontal pull'),
+    ('Dumbbell Row', '{db row,one arm row,single arm row}', '{lats,upper back}', '{biceps,rear delts}', 'dumbbell', 'horizontal pull'),
+    ('Seated Cable Row', '{cable row,seated row}', '{upper back,lats}', '{biceps,rear delts}', 'cable', 'horizontal pull'),
+    ('Pull-Up', '{pullup,pullups,pull up,pull ups,chin-up,chinup,chin up}', '{lats}', '{biceps,upper back}', 'bodyweight', 'vertical pull'),
+    ('Lat Pulldown', '{pulldown,pulldowns,lat pulldowns}', '{lats}', '{biceps,upper back}', 'cable', 'vertical pull'),
+    ('Face Pull', '{face pulls}', '{rear delts}', '{upper back}', 'cable', 'horizontal pull'),
+    ('Barbell Curl', '{curl,curls,bicep curl,biceps curl}', '{biceps}', '{forearms}', 'barbell', 'isolation'),
+    ('Dumbbell Curl', '{db curl,dumbbell curls,hammer curl}', '{biceps}', '{forearms}', 'dumbbell', 'isolation'),
+    ('Triceps Pushdown', '{pushdown,pushdowns,tricep pushdown,rope pushdown}', '{triceps}', '{}', 'cable', 'isolation'),
+    ('Skull Crusher', '{skull crushers,lying triceps extension}', '{triceps}', '{}', 'barbell', 'isolation'),
+    ('Plank', '{planks}', '{core}', '{}', 'bodyweight', 'carry'),
+    ('Farmer''s Carry', '{farmers carry,farmer carry,farmers walk}', '{forearms,upper back}', '{core}', 'dumbbell', 'carry')
+ON CONFLICT DO NOTHING;